@@ -0,0 +1,27 @@
+package apiclient
+
+import (
+	"net/http"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// AppDoer hands requests straight to a Fiber app through app.Test, so the client can exercise
+// the app in-process without a listener:
+//
+//	c := apiclient.New("http://dytest", apiclient.WithHTTPClient(apiclient.AppDoer{App: app}))
+//
+// Responses are buffered, so it does not suit MovieEvents.
+type AppDoer struct {
+	App *fiber.App
+	// Timeout in milliseconds; zero or less waits as long as the handler takes.
+	Timeout int
+}
+
+func (d AppDoer) Do(req *http.Request) (*http.Response, error) {
+	timeout := d.Timeout
+	if timeout <= 0 {
+		timeout = -1
+	}
+	return d.App.Test(req, timeout)
+}
@@ -0,0 +1,171 @@
+package apiclient
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"dytest/problem"
+)
+
+// Doer sends HTTP requests. *http.Client implements it, and so does AppDoer for in-process calls.
+type Doer interface {
+	Do(req *http.Request) (*http.Response, error)
+}
+
+// Client calls the movie API. Errors from the API come back as *problem.Problem.
+type Client struct {
+	baseURL    string
+	httpClient Doer
+	header     http.Header
+	maxRetries int
+	backoff    time.Duration
+	maxBackoff time.Duration
+}
+
+type Option func(*Client)
+
+func WithHTTPClient(doer Doer) Option {
+	return func(c *Client) {
+		c.httpClient = doer
+	}
+}
+
+// WithRetries retries requests answered with 429 or 503 up to maxRetries times, waiting for
+// Retry-After when the server sends it and doubling backoff otherwise.
+func WithRetries(maxRetries int, backoff time.Duration) Option {
+	return func(c *Client) {
+		c.maxRetries = maxRetries
+		c.backoff = backoff
+	}
+}
+
+// WithTenant sends the tenant ID in the X-Tenant-ID header.
+func WithTenant(tenantID string) Option {
+	return WithHeader("X-Tenant-ID", tenantID)
+}
+
+func WithHeader(key, value string) Option {
+	return func(c *Client) {
+		c.header.Set(key, value)
+	}
+}
+
+func New(baseURL string, opts ...Option) *Client {
+	c := &Client{
+		baseURL:    strings.TrimRight(baseURL, "/"),
+		httpClient: &http.Client{Timeout: 30 * time.Second},
+		header:     http.Header{},
+		maxRetries: 3,
+		backoff:    200 * time.Millisecond,
+		maxBackoff: 10 * time.Second,
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+// do sends body as JSON, or as is when it is a []byte, with contentType when set, and decodes a
+// 2xx response into result. It returns the response status.
+func (c *Client) do(ctx context.Context, method, path string, query url.Values, contentType string, body, result any) (int, error) {
+	var payload []byte
+	if raw, ok := body.([]byte); ok {
+		payload = raw
+	} else if body != nil {
+		var err error
+		if payload, err = json.Marshal(body); err != nil {
+			return 0, fmt.Errorf("failed to encode request: %v", err)
+		}
+		if contentType == "" {
+			contentType = "application/json"
+		}
+	}
+
+	target := c.baseURL + path
+	if len(query) > 0 {
+		target += "?" + query.Encode()
+	}
+
+	for attempt := 0; ; attempt++ {
+		req, err := http.NewRequestWithContext(ctx, method, target, bytes.NewReader(payload))
+		if err != nil {
+			return 0, err
+		}
+		for key, values := range c.header {
+			req.Header[key] = values
+		}
+		req.Header.Set("Accept", "application/json")
+		if contentType != "" {
+			req.Header.Set("Content-Type", contentType)
+		}
+
+		resp, err := c.httpClient.Do(req)
+		if err != nil {
+			return 0, err
+		}
+		if (resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode == http.StatusServiceUnavailable) && attempt < c.maxRetries {
+			resp.Body.Close()
+			if err := c.wait(ctx, attempt, resp.Header.Get("Retry-After")); err != nil {
+				return 0, err
+			}
+			continue
+		}
+		return resp.StatusCode, decode(resp, result)
+	}
+}
+
+func (c *Client) wait(ctx context.Context, attempt int, retryAfter string) error {
+	delay := min(c.backoff<<attempt, c.maxBackoff)
+	if seconds, err := strconv.Atoi(retryAfter); err == nil {
+		delay = time.Duration(seconds) * time.Second
+	}
+
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+func decode(resp *http.Response, result any) error {
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+
+	if resp.StatusCode >= http.StatusBadRequest {
+		p := &problem.Problem{}
+		if json.Unmarshal(body, p) != nil || p.Status == 0 {
+			p = problem.Status(resp.StatusCode, strings.TrimSpace(string(body)))
+		}
+		return p
+	}
+
+	if result == nil || len(body) == 0 {
+		return nil
+	}
+	if text, ok := result.(*string); ok {
+		*text = string(body)
+		return nil
+	}
+	if err := json.Unmarshal(body, result); err != nil {
+		return fmt.Errorf("failed to decode response: %v", err)
+	}
+	return nil
+}
+
+func moviePath(year int, title string) string {
+	return fmt.Sprintf("/movies/%d/%s", year, url.PathEscape(title))
+}
@@ -0,0 +1,361 @@
+package apiclient_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"dytest/apiclient"
+	"dytest/config"
+	"dytest/dynamodb/memory"
+	"dytest/events"
+	"dytest/fixtures"
+	"dytest/importer"
+	"dytest/jobs"
+	"dytest/model"
+	"dytest/openapi"
+	"dytest/patch"
+	"dytest/problem"
+	"dytest/server"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+// newClient returns a client calling a server backed by the in-memory client, with an empty
+// Movies table.
+func newClient(t *testing.T, opts ...apiclient.Option) (*apiclient.Client, *server.Server) {
+	t.Helper()
+	cfg := config.Default()
+	cfg.Jobs.DataDir = t.TempDir()
+
+	db := memory.New()
+	schema := &model.TableSchema{
+		TableName:    "Movies",
+		PartitionKey: model.KeyAttribute{Name: "year", Type: "N"},
+		SortKey:      &model.KeyAttribute{Name: "title", Type: "S"},
+	}
+	if err := fixtures.CreateTable(context.Background(), db, schema); err != nil {
+		t.Fatalf("failed to create Movies: %v", err)
+	}
+
+	srv, err := server.New(cfg, db, events.NewBroker(10))
+	if err != nil {
+		t.Fatalf("failed to build server: %v", err)
+	}
+	opts = append([]apiclient.Option{apiclient.WithHTTPClient(apiclient.AppDoer{App: srv.App})}, opts...)
+	return apiclient.New("http://dytest", opts...), srv
+}
+
+func rating(v float64) map[string]any {
+	return map[string]any{"rating": v}
+}
+
+func TestRoutesCoverOperations(t *testing.T) {
+	if err := openapi.CheckCoverage(openapi.Operations, apiclient.Routes, "movies", "tables", "imports", "jobs"); err != nil {
+		t.Fatalf("the client is out of date: %v", err)
+	}
+}
+
+func TestMovieLifecycle(t *testing.T) {
+	ctx := context.Background()
+	c, _ := newClient(t)
+
+	if _, err := c.CreateMovie(ctx, model.MovieItem{Year: 2013, Title: "Rush", Info: rating(8.1)}); err != nil {
+		t.Fatalf("CreateMovie: %v", err)
+	}
+
+	movie, err := c.GetMovie(ctx, 2013, "Rush")
+	if err != nil {
+		t.Fatalf("GetMovie: %v", err)
+	}
+	if movie.Title != "Rush" || movie.Info["rating"] != 8.1 {
+		t.Errorf("GetMovie = %+v", movie)
+	}
+
+	if _, created, err := c.PutMovie(ctx, model.MovieItem{Year: 2013, Title: "Rush", Info: rating(8.2)}); err != nil || created {
+		t.Errorf("PutMovie over an existing movie = created %v, %v", created, err)
+	}
+	if _, created, err := c.PutMovie(ctx, model.MovieItem{Year: 2013, Title: "Her", Info: rating(8)}); err != nil || !created {
+		t.Errorf("PutMovie of a new movie = created %v, %v", created, err)
+	}
+
+	movie, err = c.MergePatchMovie(ctx, 2013, "Rush", map[string]any{"info": map[string]any{"plot": "Hunt and Lauda."}})
+	if err != nil {
+		t.Fatalf("MergePatchMovie: %v", err)
+	}
+	if movie.Info["plot"] != "Hunt and Lauda." || movie.Info["rating"] != 8.2 {
+		t.Errorf("MergePatchMovie = %+v", movie)
+	}
+
+	movie, err = c.JSONPatchMovie(ctx, 2013, "Rush", []patch.Operation{{Op: "replace", Path: "/info/rating", Value: 8.3}})
+	if err != nil {
+		t.Fatalf("JSONPatchMovie: %v", err)
+	}
+	if movie.Info["rating"] != 8.3 {
+		t.Errorf("JSONPatchMovie = %+v", movie)
+	}
+
+	if err := c.DeleteMovie(ctx, 2013, "Rush"); err != nil {
+		t.Fatalf("DeleteMovie: %v", err)
+	}
+	_, err = c.GetMovie(ctx, 2013, "Rush")
+	var p *problem.Problem
+	if !errors.As(err, &p) || p.Status != http.StatusNotFound {
+		t.Errorf("GetMovie after DeleteMovie = %v, want a 404 problem", err)
+	}
+}
+
+func TestProblems(t *testing.T) {
+	ctx := context.Background()
+	c, _ := newClient(t)
+
+	movie := model.MovieItem{Year: 2013, Title: "Rush"}
+	if _, err := c.CreateMovie(ctx, movie); err != nil {
+		t.Fatalf("CreateMovie: %v", err)
+	}
+	_, err := c.CreateMovie(ctx, movie)
+	var p *problem.Problem
+	if !errors.As(err, &p) || p.Status != http.StatusConflict {
+		t.Errorf("CreateMovie of an existing movie = %v, want a 409 problem", err)
+	}
+
+	_, err = c.CreateMovie(ctx, model.MovieItem{Year: 1200, Title: "Rush", Info: rating(11)})
+	if !errors.As(err, &p) || p.Status != http.StatusUnprocessableEntity {
+		t.Fatalf("CreateMovie of an invalid movie = %v, want a 422 problem", err)
+	}
+	fields := map[string]bool{}
+	for _, fieldErr := range p.Errors {
+		fields[fieldErr.Field] = true
+	}
+	if !fields["year"] || !fields["info.rating"] {
+		t.Errorf("problem errors = %+v, want year and info.rating", p.Errors)
+	}
+}
+
+func TestPatchMissingParents(t *testing.T) {
+	ctx := context.Background()
+	c, _ := newClient(t)
+
+	if _, err := c.CreateMovie(ctx, model.MovieItem{Year: 2013, Title: "Rush"}); err != nil {
+		t.Fatalf("CreateMovie: %v", err)
+	}
+
+	var p *problem.Problem
+	_, err := c.JSONPatchMovie(ctx, 2013, "Rush", []patch.Operation{{Op: "add", Path: "/info/plot", Value: "Hunt and Lauda."}})
+	if !errors.As(err, &p) || p.Status != http.StatusConflict {
+		t.Errorf("add under a missing member = %v, want a 409 problem", err)
+	}
+	_, err = c.JSONPatchMovie(ctx, 2013, "Rush", []patch.Operation{{Op: "remove", Path: "/info/plot"}})
+	if !errors.As(err, &p) || p.Status != http.StatusConflict {
+		t.Errorf("remove of a missing member = %v, want a 409 problem", err)
+	}
+
+	movie, err := c.MergePatchMovie(ctx, 2013, "Rush", map[string]any{"info": map[string]any{"plot": "Hunt and Lauda.", "rating": 8.1}})
+	if err != nil {
+		t.Fatalf("MergePatchMovie: %v", err)
+	}
+	if movie.Info["plot"] != "Hunt and Lauda." || movie.Info["rating"] != 8.1 {
+		t.Errorf("MergePatchMovie = %+v, want info created", movie)
+	}
+
+	movie, err = c.JSONPatchMovie(ctx, 2013, "Rush", []patch.Operation{{Op: "add", Path: "/info/rank", Value: 3}})
+	if err != nil {
+		t.Fatalf("JSONPatchMovie: %v", err)
+	}
+	if movie.Info["rank"] != float64(3) || movie.Info["plot"] != "Hunt and Lauda." {
+		t.Errorf("JSONPatchMovie = %+v", movie)
+	}
+}
+
+func TestMovieIterator(t *testing.T) {
+	ctx := context.Background()
+	c, _ := newClient(t)
+
+	titles := []string{"Gravity", "Her", "Prisoners", "Rush", "The Wolf of Wall Street"}
+	for _, title := range titles {
+		if _, err := c.CreateMovie(ctx, model.MovieItem{Year: 2013, Title: title}); err != nil {
+			t.Fatalf("CreateMovie: %v", err)
+		}
+	}
+	if _, err := c.CreateMovie(ctx, model.MovieItem{Year: 2014, Title: "Interstellar"}); err != nil {
+		t.Fatalf("CreateMovie: %v", err)
+	}
+
+	var got []string
+	it := c.Movies(apiclient.ListMoviesParams{Year: 2013, Limit: 2})
+	for it.Next(ctx) {
+		got = append(got, it.Movie().Title)
+	}
+	if err := it.Err(); err != nil {
+		t.Fatalf("Movies: %v", err)
+	}
+	if len(got) != len(titles) {
+		t.Fatalf("Movies = %v, want %v", got, titles)
+	}
+	for i := range titles {
+		if got[i] != titles[i] {
+			t.Errorf("Movies = %v, want %v", got, titles)
+			break
+		}
+	}
+
+	count := 0
+	for it := c.Movies(apiclient.ListMoviesParams{Limit: 4}); it.Next(ctx); {
+		count++
+	}
+	if count != len(titles)+1 {
+		t.Errorf("Movies without a year returned %d movies, want %d", count, len(titles)+1)
+	}
+}
+
+// flaky answers the first failures requests itself with status, then passes requests on.
+type flaky struct {
+	next     apiclient.Doer
+	status   int
+	failures int
+	calls    int
+}
+
+func (f *flaky) Do(req *http.Request) (*http.Response, error) {
+	f.calls++
+	if f.calls <= f.failures {
+		return &http.Response{StatusCode: f.status, Header: http.Header{}, Body: http.NoBody, Request: req}, nil
+	}
+	return f.next.Do(req)
+}
+
+func TestRetries(t *testing.T) {
+	ctx := context.Background()
+	for _, status := range []int{http.StatusTooManyRequests, http.StatusServiceUnavailable} {
+		_, srv := newClient(t)
+		doer := &flaky{next: apiclient.AppDoer{App: srv.App}, status: status, failures: 2}
+		c := apiclient.New("http://dytest", apiclient.WithHTTPClient(doer), apiclient.WithRetries(2, time.Millisecond))
+
+		if _, err := c.ListTables(ctx); err != nil {
+			t.Errorf("ListTables after two %d responses: %v", status, err)
+		}
+		if doer.calls != 3 {
+			t.Errorf("%d: %d calls, want 3", status, doer.calls)
+		}
+
+		doer.calls, doer.failures = 0, 3
+		_, err := c.ListTables(ctx)
+		var p *problem.Problem
+		if !errors.As(err, &p) || p.Status != status {
+			t.Errorf("ListTables after running out of retries = %v, want a %d problem", err, status)
+		}
+	}
+}
+
+func TestTables(t *testing.T) {
+	ctx := context.Background()
+	c, srv := newClient(t)
+
+	tables, err := c.ListTables(ctx)
+	if err != nil {
+		t.Fatalf("ListTables: %v", err)
+	}
+	if len(tables) != 1 || tables[0] != "Movies" {
+		t.Errorf("ListTables = %v, want [Movies]", tables)
+	}
+
+	if err := c.UpdateTimeToLive(ctx, model.TimeToLiveRequest{TableName: "Movies", AttributeName: "expiresAt", Enabled: true}); err != nil {
+		t.Fatalf("UpdateTimeToLive: %v", err)
+	}
+	ttl, err := c.DescribeTimeToLive(ctx, "Movies")
+	if err != nil {
+		t.Fatalf("DescribeTimeToLive: %v", err)
+	}
+	if ttl.AttributeName == nil || *ttl.AttributeName != "expiresAt" {
+		t.Errorf("DescribeTimeToLive = %+v", ttl)
+	}
+
+	if err := srv.Jobs.Store.EnsureTable(ctx); err != nil {
+		t.Fatalf("failed to create Jobs: %v", err)
+	}
+	job, err := c.CreateTable(ctx, model.CreateTableRequest{
+		TableName:            "Movies2",
+		AttributeDefinitions: []types.AttributeDefinition{{AttributeName: aws.String("title"), AttributeType: types.ScalarAttributeTypeS}},
+		KeySchema:            []types.KeySchemaElement{{AttributeName: aws.String("title"), KeyType: types.KeyTypeHash}},
+	})
+	if err != nil {
+		t.Fatalf("CreateTable: %v", err)
+	}
+	if job.ID == "" || job.Kind != "create-table" {
+		t.Errorf("CreateTable = %+v, want a create-table job", job)
+	}
+
+	runCtx, stop := context.WithCancel(ctx)
+	defer stop()
+	srv.Jobs.Start(runCtx)
+	waitCtx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+	if job, err = c.WaitJob(waitCtx, job.ID, 10*time.Millisecond); err != nil {
+		t.Fatalf("WaitJob: %v", err)
+	}
+	if job.Status != jobs.StatusSucceeded {
+		t.Errorf("WaitJob = %+v, want a succeeded job", job)
+	}
+	var p *problem.Problem
+	if _, err := c.CancelJob(ctx, job.ID); !errors.As(err, &p) || p.Status != http.StatusConflict {
+		t.Errorf("CancelJob of a finished job = %v, want a 409 problem", err)
+	}
+	if _, err := c.GetJobResult(ctx, job.ID, io.Discard); !errors.As(err, &p) || p.Status != http.StatusNotFound {
+		t.Errorf("GetJobResult of a create-table job = %v, want a 404 problem", err)
+	}
+	if _, err := c.GetJob(ctx, "missing"); !errors.As(err, &p) || p.Status != http.StatusNotFound {
+		t.Errorf("GetJob of a missing job = %v, want a 404 problem", err)
+	}
+}
+
+func TestImport(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	c, srv := newClient(t)
+	if err := srv.Start(ctx); err != nil {
+		t.Fatalf("Start: %v", err)
+	}
+
+	rows := `{"year": 2013, "title": "Rush", "info": {"rating": 8.1}}
+{"year": 1200, "title": "Too early"}
+`
+	var job *jobs.Job
+	var err error
+	// Start creates the Jobs and Imports tables in the background.
+	for job == nil {
+		job, err = c.Import(ctx, apiclient.ImportParams{Format: "ndjson"}, strings.NewReader(rows))
+		if err != nil && ctx.Err() != nil {
+			t.Fatalf("Import: %v", err)
+		}
+		if err != nil {
+			time.Sleep(10 * time.Millisecond)
+		}
+	}
+	if job, err = c.WaitJob(ctx, job.ID, 10*time.Millisecond); err != nil {
+		t.Fatalf("WaitJob: %v", err)
+	}
+	if job.Status != jobs.StatusSucceeded {
+		t.Fatalf("WaitJob = %+v, want a succeeded job", job)
+	}
+
+	var report importer.Import
+	if err := json.Unmarshal(job.Result, &report); err != nil {
+		t.Fatalf("invalid job result %s: %v", job.Result, err)
+	}
+	imp, err := c.GetImport(ctx, report.ID)
+	if err != nil {
+		t.Fatalf("GetImport: %v", err)
+	}
+	if !imp.Done || imp.Written != 1 || imp.Rejected != 1 {
+		t.Errorf("GetImport = %+v, want one row written and one rejected", imp)
+	}
+	if _, err := c.GetMovie(ctx, 2013, "Rush"); err != nil {
+		t.Errorf("GetMovie of an imported movie: %v", err)
+	}
+}
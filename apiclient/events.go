@@ -0,0 +1,71 @@
+package apiclient
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"dytest/events"
+)
+
+// MovieEvents follows GET /movies/events and calls handle for each change until ctx is done,
+// the stream ends or handle returns an error. lastEventID resumes after an event already seen;
+// after a server restart, every event the server still has is sent again.
+// The stream is long-lived, so use an HTTP client without a timeout.
+func (c *Client) MovieEvents(ctx context.Context, lastEventID string, handle func(events.Event) error) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.baseURL+"/movies/events", nil)
+	if err != nil {
+		return err
+	}
+	for key, values := range c.header {
+		req.Header[key] = values
+	}
+	req.Header.Set("Accept", "text/event-stream")
+	if lastEventID != "" {
+		req.Header.Set("Last-Event-ID", lastEventID)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return decode(resp, nil)
+	}
+	defer resp.Body.Close()
+
+	var event events.Event
+	var data strings.Builder
+	scanner := bufio.NewScanner(resp.Body)
+	for scanner.Scan() {
+		field, value, _ := strings.Cut(scanner.Text(), ":")
+		value = strings.TrimPrefix(value, " ")
+		switch field {
+		case "id":
+			event.ID = value
+		case "data":
+			data.WriteString(value)
+		case "":
+			// A blank line ends the event; a comment line (": ping") has an empty field too.
+			if scanner.Text() != "" || data.Len() == 0 {
+				continue
+			}
+			id := event.ID
+			if err := json.Unmarshal([]byte(data.String()), &event); err != nil {
+				return fmt.Errorf("failed to decode event %s: %v", id, err)
+			}
+			event.ID = id
+			if err := handle(event); err != nil {
+				return err
+			}
+			event, data = events.Event{}, strings.Builder{}
+		}
+	}
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+	return scanner.Err()
+}
@@ -0,0 +1,72 @@
+package apiclient
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+type ExportMoviesParams struct {
+	// Format is "ndjson", "csv" or "json"; the server defaults to ndjson.
+	Format string
+	Year   int
+	// Filter is comma-separated conditions such as "year>=2000,info.rating>7".
+	Filter  string
+	Fields  []string
+	Columns []string
+}
+
+func (p ExportMoviesParams) query() url.Values {
+	query := url.Values{}
+	if p.Format != "" {
+		query.Set("format", p.Format)
+	}
+	if p.Year != 0 {
+		query.Set("year", strconv.Itoa(p.Year))
+	}
+	if p.Filter != "" {
+		query.Set("filter", p.Filter)
+	}
+	if len(p.Fields) > 0 {
+		query.Set("fields", strings.Join(p.Fields, ","))
+	}
+	if len(p.Columns) > 0 {
+		query.Set("columns", strings.Join(p.Columns, ","))
+	}
+	return query
+}
+
+// ExportMovies copies GET /movies/export to w as it arrives and returns the bytes written.
+// Large exports outlast the default timeout, so use an HTTP client without one.
+func (c *Client) ExportMovies(ctx context.Context, params ExportMoviesParams, w io.Writer) (int64, error) {
+	// Without a wait preference the server runs the export as a job.
+	return c.download(ctx, "/movies/export?"+params.query().Encode(), http.Header{"Prefer": {"wait=3600"}}, w)
+}
+
+// download copies the body of a GET request to w, which is how files are fetched instead of
+// decoded.
+func (c *Client) download(ctx context.Context, path string, header http.Header, w io.Writer) (int64, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.baseURL+path, nil)
+	if err != nil {
+		return 0, err
+	}
+	for key, values := range c.header {
+		req.Header[key] = values
+	}
+	for key, values := range header {
+		req.Header[key] = values
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return 0, decode(resp, nil)
+	}
+	defer resp.Body.Close()
+	return io.Copy(w, resp.Body)
+}
@@ -0,0 +1,64 @@
+package apiclient
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+
+	"dytest/importer"
+	"dytest/jobs"
+)
+
+type ImportParams struct {
+	// Format is "ndjson", "csv" or "json".
+	Format string
+	// Table defaults to Movies on the server.
+	Table string
+	// ID resumes the failed import with this id; send the same file again.
+	ID string
+}
+
+func (p ImportParams) query() url.Values {
+	query := url.Values{"format": {p.Format}}
+	if p.Table != "" {
+		query.Set("table", p.Table)
+	}
+	if p.ID != "" {
+		query.Set("id", p.ID)
+	}
+	return query
+}
+
+var importContentTypes = map[string]string{
+	"ndjson": "application/x-ndjson",
+	"csv":    "text/csv",
+	"json":   "application/json",
+}
+
+// Import uploads rows and returns the job importing them. The job's result is the import
+// report, with the id to resume or to pass to GetImport.
+func (c *Client) Import(ctx context.Context, params ImportParams, rows io.Reader) (*jobs.Job, error) {
+	contentType, ok := importContentTypes[params.Format]
+	if !ok {
+		return nil, fmt.Errorf("unknown import format %q", params.Format)
+	}
+	body, err := io.ReadAll(rows)
+	if err != nil {
+		return nil, err
+	}
+	job := &jobs.Job{}
+	if _, err := c.do(ctx, http.MethodPost, "/imports", params.query(), contentType, body, job); err != nil {
+		return nil, err
+	}
+	return job, nil
+}
+
+func (c *Client) GetImport(ctx context.Context, id string) (*importer.Import, error) {
+	imp := &importer.Import{}
+	if _, err := c.do(ctx, http.MethodGet, "/imports/"+url.PathEscape(id), nil, "", nil, imp); err != nil {
+		return nil, err
+	}
+	return imp, nil
+}
@@ -0,0 +1,56 @@
+package apiclient
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/url"
+	"time"
+
+	"dytest/jobs"
+)
+
+func (c *Client) GetJob(ctx context.Context, id string) (*jobs.Job, error) {
+	job := &jobs.Job{}
+	if _, err := c.do(ctx, http.MethodGet, jobPath(id), nil, "", nil, job); err != nil {
+		return nil, err
+	}
+	return job, nil
+}
+
+// CancelJob asks the server to stop the job; a running job stops shortly after, so use WaitJob
+// to see it canceled.
+func (c *Client) CancelJob(ctx context.Context, id string) (*jobs.Job, error) {
+	job := &jobs.Job{}
+	if _, err := c.do(ctx, http.MethodPost, jobPath(id)+"/cancel", nil, "", nil, job); err != nil {
+		return nil, err
+	}
+	return job, nil
+}
+
+// GetJobResult copies the file a succeeded export job wrote to w and returns the bytes written.
+func (c *Client) GetJobResult(ctx context.Context, id string, w io.Writer) (int64, error) {
+	return c.download(ctx, jobPath(id)+"/result", nil, w)
+}
+
+// WaitJob polls the job every interval until it has finished and returns it. A failed or
+// canceled job is returned without an error; check its Status.
+func (c *Client) WaitJob(ctx context.Context, id string, interval time.Duration) (*jobs.Job, error) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		job, err := c.GetJob(ctx, id)
+		if err != nil || job.Finished() {
+			return job, err
+		}
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+func jobPath(id string) string {
+	return "/jobs/" + url.PathEscape(id)
+}
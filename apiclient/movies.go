@@ -0,0 +1,142 @@
+package apiclient
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strconv"
+
+	"dytest/model"
+	"dytest/patch"
+)
+
+type ListMoviesParams struct {
+	// Limit is the page size; the server defaults to 20.
+	Limit       int
+	Cursor      string
+	Year        int
+	TitlePrefix string
+}
+
+func (p ListMoviesParams) query() url.Values {
+	query := url.Values{}
+	if p.Limit > 0 {
+		query.Set("limit", strconv.Itoa(p.Limit))
+	}
+	if p.Cursor != "" {
+		query.Set("cursor", p.Cursor)
+	}
+	if p.Year != 0 {
+		query.Set("year", strconv.Itoa(p.Year))
+	}
+	if p.TitlePrefix != "" {
+		query.Set("titlePrefix", p.TitlePrefix)
+	}
+	return query
+}
+
+// ListMovies returns one page. Pass NextCursor back as Cursor for the next one, or use Movies.
+func (c *Client) ListMovies(ctx context.Context, params ListMoviesParams) (*model.MovieList, error) {
+	list := &model.MovieList{}
+	if _, err := c.do(ctx, http.MethodGet, "/movies", params.query(), "", nil, list); err != nil {
+		return nil, err
+	}
+	return list, nil
+}
+
+// Movies iterates over every movie matching params, fetching pages as needed.
+func (c *Client) Movies(params ListMoviesParams) *MovieIterator {
+	return &MovieIterator{client: c, params: params}
+}
+
+// MovieIterator works like bufio.Scanner:
+//
+//	it := c.Movies(apiclient.ListMoviesParams{Year: 2013})
+//	for it.Next(ctx) {
+//		movie := it.Movie()
+//	}
+//	if err := it.Err(); err != nil { ... }
+type MovieIterator struct {
+	client *Client
+	params ListMoviesParams
+	page   []model.MovieItem
+	index  int
+	done   bool
+	err    error
+}
+
+func (it *MovieIterator) Next(ctx context.Context) bool {
+	it.index++
+	for it.index >= len(it.page) {
+		if it.done || it.err != nil {
+			return false
+		}
+		list, err := it.client.ListMovies(ctx, it.params)
+		if err != nil {
+			it.err = err
+			return false
+		}
+		it.page, it.index = list.Items, 0
+		it.params.Cursor = list.NextCursor
+		it.done = list.NextCursor == ""
+	}
+	return true
+}
+
+func (it *MovieIterator) Movie() model.MovieItem {
+	return it.page[it.index]
+}
+
+func (it *MovieIterator) Err() error {
+	return it.err
+}
+
+func (c *Client) GetMovie(ctx context.Context, year int, title string) (*model.MovieItem, error) {
+	movie := &model.MovieItem{}
+	if _, err := c.do(ctx, http.MethodGet, moviePath(year, title), nil, "", nil, movie); err != nil {
+		return nil, err
+	}
+	return movie, nil
+}
+
+// CreateMovie fails with a 409 problem when the movie exists.
+func (c *Client) CreateMovie(ctx context.Context, movie model.MovieItem) (*model.MovieItem, error) {
+	created := &model.MovieItem{}
+	if _, err := c.do(ctx, http.MethodPost, "/movies", nil, "", movie, created); err != nil {
+		return nil, err
+	}
+	return created, nil
+}
+
+// PutMovie creates or replaces a movie and reports whether it was created.
+func (c *Client) PutMovie(ctx context.Context, movie model.MovieItem) (*model.MovieItem, bool, error) {
+	saved := &model.MovieItem{}
+	status, err := c.do(ctx, http.MethodPut, moviePath(movie.Year, movie.Title), nil, "", movie, saved)
+	if err != nil {
+		return nil, false, err
+	}
+	return saved, status == http.StatusCreated, nil
+}
+
+// MergePatchMovie applies an RFC 7396 merge patch: null removes a member, objects are merged.
+func (c *Client) MergePatchMovie(ctx context.Context, year int, title string, doc map[string]any) (*model.MovieItem, error) {
+	movie := &model.MovieItem{}
+	if _, err := c.do(ctx, http.MethodPatch, moviePath(year, title), nil, patch.MergePatchContentType, doc, movie); err != nil {
+		return nil, err
+	}
+	return movie, nil
+}
+
+// JSONPatchMovie applies an RFC 6902 JSON Patch.
+func (c *Client) JSONPatchMovie(ctx context.Context, year int, title string, operations []patch.Operation) (*model.MovieItem, error) {
+	movie := &model.MovieItem{}
+	if _, err := c.do(ctx, http.MethodPatch, moviePath(year, title), nil, patch.JSONPatchContentType, operations, movie); err != nil {
+		return nil, err
+	}
+	return movie, nil
+}
+
+func (c *Client) DeleteMovie(ctx context.Context, year int, title string) error {
+	_, err := c.do(ctx, http.MethodDelete, moviePath(year, title), nil, "", nil, nil)
+	return err
+}
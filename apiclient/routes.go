@@ -0,0 +1,25 @@
+package apiclient
+
+// Routes are the API operations this package covers, keyed like openapi.Operations.
+// TestRoutesCoverOperations checks them against the OpenAPI operations, so a new movie, table,
+// import or job route cannot be added without a client method.
+var Routes = []string{
+	"GET /movies",
+	"POST /movies",
+	"GET /movies/{year}/{title}",
+	"PUT /movies/{year}/{title}",
+	"PATCH /movies/{year}/{title}",
+	"DELETE /movies/{year}/{title}",
+	"GET /movies/events",
+	"GET /movies/export",
+	"GET /get-table",
+	"POST /create-table",
+	"POST /delete-table",
+	"POST /update-table-ttl",
+	"POST /describe-table-ttl",
+	"POST /imports",
+	"GET /imports/{id}",
+	"GET /jobs/{id}",
+	"POST /jobs/{id}/cancel",
+	"GET /jobs/{id}/result",
+}
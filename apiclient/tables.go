@@ -0,0 +1,46 @@
+package apiclient
+
+import (
+	"context"
+	"net/http"
+
+	"dytest/jobs"
+	"dytest/model"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+func (c *Client) ListTables(ctx context.Context) ([]string, error) {
+	var tables []string
+	if _, err := c.do(ctx, http.MethodGet, "/get-table", nil, "", nil, &tables); err != nil {
+		return nil, err
+	}
+	return tables, nil
+}
+
+// CreateTable starts creating a table and returns the job doing it; WaitJob waits for it to finish.
+func (c *Client) CreateTable(ctx context.Context, request model.CreateTableRequest) (*jobs.Job, error) {
+	job := &jobs.Job{}
+	if _, err := c.do(ctx, http.MethodPost, "/create-table", nil, "", request, job); err != nil {
+		return nil, err
+	}
+	return job, nil
+}
+
+func (c *Client) DeleteTable(ctx context.Context, tableName string) error {
+	_, err := c.do(ctx, http.MethodPost, "/delete-table", nil, "", model.DeleteTableRequest{TableName: tableName}, nil)
+	return err
+}
+
+func (c *Client) UpdateTimeToLive(ctx context.Context, request model.TimeToLiveRequest) error {
+	_, err := c.do(ctx, http.MethodPost, "/update-table-ttl", nil, "", request, nil)
+	return err
+}
+
+func (c *Client) DescribeTimeToLive(ctx context.Context, tableName string) (*types.TimeToLiveDescription, error) {
+	description := &types.TimeToLiveDescription{}
+	if _, err := c.do(ctx, http.MethodPost, "/describe-table-ttl", nil, "", model.DescribeTimeToLiveRequest{TableName: tableName}, description); err != nil {
+		return nil, err
+	}
+	return description, nil
+}
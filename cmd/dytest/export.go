@@ -0,0 +1,112 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	dynamodbClient "dytest/dynamodb"
+	"dytest/export"
+)
+
+// runExport writes a table, or the items matching a key condition, as NDJSON, CSV or a JSON array.
+func runExport(ctx context.Context, env *env, args []string) error {
+	fs := flag.NewFlagSet("export", flag.ContinueOnError)
+	var (
+		tableName = fs.String("table", "Movies", "table to export")
+		file      = fs.String("file", "-", "file to write, - for stdout")
+		format    = fs.String("format", "", "ndjson, csv or json; taken from the file extension when empty")
+		key       = fs.String("key", "", "query this key condition instead of scanning, e.g. year=2013")
+		filter    = fs.String("filter", "", "conditions such as info.rating>=7,title^=The")
+		fields    = fs.String("fields", "", "comma-separated attribute paths to export")
+		columns   = fs.String("columns", "", "CSV columns, each path or header=path; the config's for Movies by default")
+	)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *format == "" {
+		*format = string(export.FormatNDJSON)
+		if *file != "-" {
+			*format = formatOf(*file)
+		}
+	}
+
+	var projected []string
+	if *fields != "" {
+		projected = strings.Split(*fields, ",")
+	}
+	expressions, err := export.Parse(*filter, projected)
+	if err != nil {
+		return err
+	}
+
+	columnSpecs := projected
+	switch {
+	case *columns != "":
+		columnSpecs = strings.Split(*columns, ",")
+	case len(projected) == 0 && *tableName == "Movies":
+		columnSpecs = env.cfg.Export.CSVColumns
+	}
+	csvColumns, err := export.ParseColumns(columnSpecs)
+	if err != nil {
+		return err
+	}
+	if export.Format(*format) == export.FormatCSV && len(csvColumns) == 0 {
+		return errors.New("-columns or -fields are required for csv")
+	}
+
+	var pages export.Pages
+	if *key != "" {
+		condition, err := export.ParseKeyCondition(*key)
+		if err != nil {
+			return err
+		}
+		for placeholder, name := range expressions.Names {
+			condition.Names[placeholder] = name
+		}
+		for placeholder, value := range expressions.Values {
+			condition.Values[placeholder] = value
+		}
+		pages = export.QueryPages(env.client, *tableName, dynamodbClient.QueryRequest{
+			KeyCondition: condition.Filter,
+			Filter:       expressions.Filter,
+			Projection:   expressions.Projection,
+			Names:        condition.Names,
+			Values:       condition.Values,
+			Limit:        int32(env.cfg.Export.PageSize),
+		})
+	} else {
+		pages = export.ScanPages(env.client, *tableName, dynamodbClient.ScanRequest{
+			Filter:     expressions.Filter,
+			Projection: expressions.Projection,
+			Names:      expressions.Names,
+			Values:     expressions.Values,
+			Limit:      int32(env.cfg.Export.PageSize),
+		})
+	}
+
+	var w io.Writer = os.Stdout
+	if *file != "-" {
+		f, err := os.Create(*file)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		w = f
+	}
+	enc, err := export.NewEncoder(export.Format(*format), bufio.NewWriter(w), csvColumns)
+	if err != nil {
+		return err
+	}
+	written, err := export.Copy(ctx, pages, enc)
+	if err != nil {
+		return fmt.Errorf("stopped after %d items: %w", written, err)
+	}
+	fmt.Fprintf(os.Stderr, "exported %d items from %s\n", written, *tableName)
+	return nil
+}
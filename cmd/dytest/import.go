@@ -0,0 +1,121 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	dynamodbClient "dytest/dynamodb"
+	"dytest/export"
+	"dytest/importer"
+)
+
+// runImport loads a file, resuming from its checkpoint file when one is left from an earlier run.
+func runImport(ctx context.Context, env *env, args []string) error {
+	fs := flag.NewFlagSet("import", flag.ContinueOnError)
+	var (
+		tableName  = fs.String("table", "Movies", "table to import into")
+		file       = fs.String("file", "", "file to import, - for stdin")
+		format     = fs.String("format", "", "ndjson, csv or json; taken from the file extension when empty")
+		checkpoint = fs.String("checkpoint", "", "checkpoint file, <file>.checkpoint by default")
+		restart    = fs.Bool("restart", false, "ignore an existing checkpoint")
+		rate       = fs.Int("rate", env.cfg.Import.Rate, "items written per second, 0 for no limit")
+		rejects    = fs.String("rejects", "", "write rejected rows as NDJSON to this file instead of stderr")
+		columns    = fs.String("columns", strings.Join(env.cfg.Export.CSVColumns, ","), "CSV columns, each path or header=path")
+		tenant     = fs.String("tenant", "", "tenant to import for")
+	)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *file == "" {
+		return errors.New("-file is required")
+	}
+	if *checkpoint == "" {
+		if *file == "-" {
+			return errors.New("-checkpoint is required when reading stdin")
+		}
+		*checkpoint = *file + ".checkpoint"
+	}
+	if *format == "" {
+		*format = formatOf(*file)
+	}
+	if *tenant != "" {
+		ctx = dynamodbClient.WithTenant(ctx, *tenant)
+	}
+
+	csvColumns, err := export.ParseColumns(strings.Split(*columns, ","))
+	if err != nil {
+		return err
+	}
+
+	in := os.Stdin
+	if *file != "-" {
+		if in, err = os.Open(*file); err != nil {
+			return err
+		}
+		defer in.Close()
+	}
+	rows, err := importer.NewReader(export.Format(*format), in, csvColumns, importer.TextFields(*tableName))
+	if err != nil {
+		return err
+	}
+
+	store := &importer.FileStore{Path: *checkpoint}
+	imp, err := store.Load(ctx, "")
+	switch {
+	case errors.Is(err, importer.ErrImportNotFound) || (err == nil && *restart):
+		imp = &importer.Import{ID: filepath.Base(*file), TableName: *tableName, Format: *format}
+	case err != nil:
+		return err
+	case imp.TableName != *tableName || imp.Format != *format:
+		return fmt.Errorf("%s is a checkpoint of a %s import into %s; use -restart to start over", *checkpoint, imp.Format, imp.TableName)
+	default:
+		fmt.Fprintf(os.Stderr, "resuming after row %d\n", imp.Row)
+	}
+
+	var rejectOut io.Writer = os.Stderr
+	if *rejects != "" {
+		f, err := os.OpenFile(*rejects, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		rejectOut = f
+	}
+	rejectEnc := json.NewEncoder(rejectOut)
+
+	im := &importer.Importer{
+		Client:    env.client,
+		TableName: *tableName,
+		Store:     store,
+		Rate:      *rate,
+		Reject:    func(rejection importer.Rejection) { rejectEnc.Encode(rejection) },
+		Progress: func(imp *importer.Import) {
+			fmt.Fprintf(os.Stderr, "\rrow %d: %d written, %d rejected", imp.Row, imp.Written, imp.Rejected)
+		},
+	}
+	err = im.Run(ctx, imp, rows)
+	fmt.Fprintln(os.Stderr)
+	if err != nil {
+		return fmt.Errorf("%v; run again to resume after row %d", err, imp.Row)
+	}
+
+	fmt.Printf("imported %d rows into %s, rejected %d\n", imp.Written, *tableName, imp.Rejected)
+	return nil
+}
+
+func formatOf(file string) string {
+	switch strings.ToLower(filepath.Ext(file)) {
+	case ".ndjson", ".jsonl":
+		return string(export.FormatNDJSON)
+	case ".csv":
+		return string(export.FormatCSV)
+	}
+	return string(export.FormatJSON)
+}
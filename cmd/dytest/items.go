@@ -0,0 +1,278 @@
+package main
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	dynamodbClient "dytest/dynamodb"
+	"dytest/export"
+	"dytest/fixtures"
+	"dytest/importer"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+// runGet prints one item: dytest get [-table t] name=value...
+func runGet(ctx context.Context, env *env, args []string) error {
+	fs := flag.NewFlagSet("get", flag.ContinueOnError)
+	tableName := fs.String("table", "Movies", "table to read")
+	out := outputFlag(fs)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if err := out.check(); err != nil {
+		return err
+	}
+
+	keyNames, key, err := parseKey(ctx, env.client, *tableName, fs.Args())
+	if err != nil {
+		return err
+	}
+	var item map[string]any
+	if err := env.client.GetItem(ctx, *tableName, key, &item); err != nil {
+		return err
+	}
+	return out.print(item, keyNames...)
+}
+
+// runPut writes one item given as JSON, or read from a JSON or YAML file. Items of tables with a
+// model are validated first, as the server does.
+func runPut(ctx context.Context, env *env, args []string) error {
+	fs := flag.NewFlagSet("put", flag.ContinueOnError)
+	var (
+		tableName   = fs.String("table", "Movies", "table to write")
+		file        = fs.String("file", "", "JSON or YAML file holding the item, - for stdin; or pass the item as JSON")
+		ifNotExists = fs.Bool("if-not-exists", false, "fail when an item with the same key exists")
+	)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	var data map[string]any
+	switch {
+	case *file != "":
+		if err := fixtures.Decode(*file, &data); err != nil {
+			return err
+		}
+	case fs.NArg() == 1:
+		if err := json.Unmarshal([]byte(fs.Arg(0)), &data); err != nil {
+			return fmt.Errorf("invalid item: %v", err)
+		}
+	default:
+		return errors.New("pass the item as JSON or with -file")
+	}
+
+	item, rejection := importer.Decode(*tableName, importer.Row{Number: 1, Data: data})
+	if rejection != nil {
+		return fmt.Errorf("invalid item: %s", strings.TrimPrefix(rejection.Error(), "row 1: "))
+	}
+	var opts []dynamodbClient.WriteOption
+	if *ifNotExists {
+		keyNames, _, err := tableKeys(ctx, env.client, *tableName)
+		if err != nil {
+			return err
+		}
+		opts = append(opts, dynamodbClient.IfNotExists(keyNames[0]))
+	}
+	return env.client.TransactWriteItems(ctx, *tableName, item, opts...)
+}
+
+// runDelete deletes one item: dytest delete [-table t] name=value...
+func runDelete(ctx context.Context, env *env, args []string) error {
+	fs := flag.NewFlagSet("delete", flag.ContinueOnError)
+	tableName := fs.String("table", "Movies", "table to delete from")
+	ifExists := fs.Bool("if-exists", false, "fail when the item does not exist")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	keyNames, key, err := parseKey(ctx, env.client, *tableName, fs.Args())
+	if err != nil {
+		return err
+	}
+	var opts []dynamodbClient.WriteOption
+	if *ifExists {
+		opts = append(opts, dynamodbClient.IfExists(keyNames[0]))
+	}
+	return env.client.DeleteItem(ctx, *tableName, key, opts...)
+}
+
+// runQuery reads the items matching a key condition, e.g. dytest query -key year=2013,title^=R.
+func runQuery(ctx context.Context, env *env, args []string) error {
+	return runRead(ctx, env, "query", args)
+}
+
+func runScan(ctx context.Context, env *env, args []string) error {
+	return runRead(ctx, env, "scan", args)
+}
+
+func runRead(ctx context.Context, env *env, name string, args []string) error {
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	var (
+		tableName  = fs.String("table", "Movies", "table to read")
+		indexName  = fs.String("index", "", "index to read instead of the table")
+		filter     = fs.String("filter", "", "conditions such as info.rating>=7,title^=The")
+		fields     = fs.String("fields", "", "comma-separated attribute paths to print")
+		limit      = fs.Int("limit", 100, "items per page")
+		cursor     = fs.String("cursor", "", "cursor printed after the previous page")
+		all        = fs.Bool("all", false, "read every page")
+		keyFlag    *string
+		descending *bool
+	)
+	if name == "query" {
+		keyFlag = fs.String("key", "", "key condition such as year=2013 or year=2013,title^=R")
+		descending = fs.Bool("desc", false, "return items in descending sort key order")
+	}
+	out := outputFlag(fs)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if err := out.check(); err != nil {
+		return err
+	}
+
+	var projected []string
+	if *fields != "" {
+		projected = strings.Split(*fields, ",")
+	}
+	expressions, err := export.Parse(*filter, projected)
+	if err != nil {
+		return err
+	}
+
+	var pages export.Pages
+	if name == "query" {
+		key, err := export.ParseKeyCondition(*keyFlag)
+		if err != nil {
+			return err
+		}
+		if key.Filter == "" {
+			return errors.New("-key is required")
+		}
+		for placeholder, name := range expressions.Names {
+			key.Names[placeholder] = name
+		}
+		for placeholder, value := range expressions.Values {
+			key.Values[placeholder] = value
+		}
+		pages = export.QueryPages(env.client, *tableName, dynamodbClient.QueryRequest{
+			IndexName:    *indexName,
+			KeyCondition: key.Filter,
+			Filter:       expressions.Filter,
+			Projection:   expressions.Projection,
+			Names:        key.Names,
+			Values:       key.Values,
+			Limit:        int32(*limit),
+			Descending:   *descending,
+		})
+	} else {
+		pages = export.ScanPages(env.client, *tableName, dynamodbClient.ScanRequest{
+			IndexName:  *indexName,
+			Filter:     expressions.Filter,
+			Projection: expressions.Projection,
+			Names:      expressions.Names,
+			Values:     expressions.Values,
+			Limit:      int32(*limit),
+		})
+	}
+
+	items := []map[string]any{}
+	next := *cursor
+	for {
+		var page []map[string]any
+		if next, err = pages(ctx, next, &page); err != nil {
+			return err
+		}
+		items = append(items, page...)
+		if next == "" || !*all {
+			break
+		}
+	}
+
+	keyNames, _, err := tableKeys(ctx, env.client, *tableName)
+	if err != nil {
+		return err
+	}
+	if err := out.print(items, keyNames...); err != nil {
+		return err
+	}
+	if next != "" {
+		fmt.Fprintf(os.Stderr, "more items: -cursor %s\n", next)
+	}
+	return nil
+}
+
+// tableKeys returns the names of the table's key attributes, partition key first, and the types of its attributes.
+func tableKeys(ctx context.Context, client dynamodbClient.DynamodbClient, tableName string) ([]string, map[string]types.ScalarAttributeType, error) {
+	description, err := client.DescribeTable(ctx, tableName)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to describe %s: %w", tableName, err)
+	}
+	var names []string
+	for _, element := range description.KeySchema {
+		if element.KeyType == types.KeyTypeHash {
+			names = append([]string{aws.ToString(element.AttributeName)}, names...)
+		} else {
+			names = append(names, aws.ToString(element.AttributeName))
+		}
+	}
+	attributeTypes := map[string]types.ScalarAttributeType{}
+	for _, definition := range description.AttributeDefinitions {
+		attributeTypes[aws.ToString(definition.AttributeName)] = definition.AttributeType
+	}
+	return names, attributeTypes, nil
+}
+
+// parseKey reads name=value arguments into the table's key, typed as the table defines the attributes.
+func parseKey(ctx context.Context, client dynamodbClient.DynamodbClient, tableName string, args []string) ([]string, map[string]types.AttributeValue, error) {
+	keyNames, attributeTypes, err := tableKeys(ctx, client, tableName)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	values := map[string]string{}
+	for _, arg := range args {
+		name, value, ok := strings.Cut(arg, "=")
+		if !ok {
+			return nil, nil, fmt.Errorf("key attributes are name=value, not %q", arg)
+		}
+		values[name] = value
+	}
+
+	key := map[string]types.AttributeValue{}
+	for _, name := range keyNames {
+		value, ok := values[name]
+		if !ok {
+			return nil, nil, fmt.Errorf("missing key attribute %s", name)
+		}
+		delete(values, name)
+
+		switch attributeTypes[name] {
+		case types.ScalarAttributeTypeN:
+			if _, err := strconv.ParseFloat(value, 64); err != nil {
+				return nil, nil, fmt.Errorf("%s must be a number", name)
+			}
+			key[name] = &types.AttributeValueMemberN{Value: value}
+		case types.ScalarAttributeTypeB:
+			data, err := base64.StdEncoding.DecodeString(value)
+			if err != nil {
+				return nil, nil, fmt.Errorf("%s must be base64", name)
+			}
+			key[name] = &types.AttributeValueMemberB{Value: data}
+		default:
+			key[name] = &types.AttributeValueMemberS{Value: value}
+		}
+	}
+	for name := range values {
+		return nil, nil, fmt.Errorf("%s is not a key attribute of %s", name, tableName)
+	}
+	return keyNames, key, nil
+}
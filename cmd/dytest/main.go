@@ -0,0 +1,107 @@
+// Command dytest works with the server's tables from the command line. It reads the same
+// config file, environment and flags as the server:
+//
+//	dytest [-config file] [-endpoint url] <command> [flags]
+//
+// For example:
+//
+//	dytest tables create -file movies.schema.yaml
+//	dytest put '{"year": 2013, "title": "Rush"}'
+//	dytest get year=2013 title=Rush
+//	dytest query -key year=2013 -filter 'info.rating>=7' -fields title,info.rating -o yaml
+//	dytest seed seed/
+//	dytest -seed seed/ shell -memory
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"sort"
+
+	"dytest/config"
+	dynamodbClient "dytest/dynamodb"
+	"dytest/problem"
+)
+
+type command struct {
+	summary string
+	run     func(ctx context.Context, env *env, args []string) error
+}
+
+// env is what every command gets: the loaded config and a client for it.
+type env struct {
+	cfg    *config.Config
+	client dynamodbClient.DynamodbClient
+}
+
+var commands = map[string]command{
+	"tables": {summary: "list, describe, create or delete tables", run: runTables},
+	"get":    {summary: "print an item by its key", run: runGet},
+	"put":    {summary: "write an item", run: runPut},
+	"delete": {summary: "delete an item by its key", run: runDelete},
+	"query":  {summary: "print the items matching a key condition and filter", run: runQuery},
+	"scan":   {summary: "print the items of a table or index matching a filter", run: runScan},
+	"export": {summary: "write a table as NDJSON, CSV or a JSON array", run: runExport},
+	"import": {summary: "load an NDJSON, CSV or JSON array file into a table", run: runImport},
+	"seed":   {summary: "load seed files, creating the tables they describe", run: runSeed},
+	"shell":  {summary: "work with tables interactively", run: runShell},
+}
+
+func main() {
+	cfg, args, err := config.LoadArgs(os.Args[1:])
+	if errors.Is(err, flag.ErrHelp) {
+		usage()
+		os.Exit(2)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "dytest: %v\n", err)
+		os.Exit(2)
+	}
+	if len(args) == 0 {
+		usage()
+		os.Exit(2)
+	}
+	cmd, ok := commands[args[0]]
+	if !ok {
+		fmt.Fprintf(os.Stderr, "dytest: unknown command %q\n", args[0])
+		usage()
+		os.Exit(2)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	client, err := dynamodbClient.NewRegistry(ctx, cfg)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "dytest: failed to create DynamoDB client: %v\n", err)
+		os.Exit(1)
+	}
+
+	if err := cmd.run(ctx, &env{cfg: cfg, client: client}, args[1:]); err != nil {
+		fmt.Fprintf(os.Stderr, "dytest %s: %v\n", args[0], err)
+		var p *problem.Problem
+		if errors.As(err, &p) {
+			for _, fieldErr := range p.Errors {
+				fmt.Fprintf(os.Stderr, "  %s %s\n", fieldErr.Field, fieldErr.Message)
+			}
+		}
+		os.Exit(1)
+	}
+}
+
+func usage() {
+	fmt.Fprintln(os.Stderr, "usage: dytest [-config file] [-endpoint url] <command> [flags]")
+	fmt.Fprintln(os.Stderr, "\ncommands:")
+	names := make([]string, 0, len(commands))
+	for name := range commands {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		fmt.Fprintf(os.Stderr, "  %-10s %s\n", name, commands[name].summary)
+	}
+}
@@ -0,0 +1,143 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	"gopkg.in/yaml.v3"
+)
+
+// printer writes command results as a table, JSON or YAML.
+type printer struct {
+	format string
+	w      io.Writer
+}
+
+func outputFlag(fs *flag.FlagSet) *printer {
+	p := &printer{w: os.Stdout}
+	fs.StringVar(&p.format, "o", "table", "output format: table, json or yaml")
+	return p
+}
+
+func (p *printer) check() error {
+	switch p.format {
+	case "table", "json", "yaml":
+		return nil
+	}
+	return fmt.Errorf("unknown output format %q", p.format)
+}
+
+// print writes v. A table has a row per item and a column per attribute, starting with columns;
+// a single object becomes a row per attribute.
+func (p *printer) print(v any, columns ...string) error {
+	switch p.format {
+	case "json":
+		enc := json.NewEncoder(p.w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(v)
+	case "yaml":
+		generic, err := toGeneric(v)
+		if err != nil {
+			return err
+		}
+		enc := yaml.NewEncoder(p.w)
+		enc.SetIndent(2)
+		defer enc.Close()
+		return enc.Encode(generic)
+	}
+
+	generic, err := toGeneric(v)
+	if err != nil {
+		return err
+	}
+	tw := tabwriter.NewWriter(p.w, 0, 0, 2, ' ', 0)
+	switch value := generic.(type) {
+	case []any:
+		rows := make([]map[string]any, 0, len(value))
+		for _, row := range value {
+			if m, ok := row.(map[string]any); ok {
+				rows = append(rows, m)
+			} else {
+				rows = append(rows, map[string]any{"value": row})
+			}
+		}
+		columns = tableColumns(rows, columns)
+		fmt.Fprintln(tw, strings.Join(columns, "\t"))
+		for _, row := range rows {
+			cells := make([]string, len(columns))
+			for i, column := range columns {
+				cells[i] = cellText(row[column])
+			}
+			fmt.Fprintln(tw, strings.Join(cells, "\t"))
+		}
+	case map[string]any:
+		for _, key := range tableColumns([]map[string]any{value}, columns) {
+			fmt.Fprintf(tw, "%s\t%s\n", key, cellText(value[key]))
+		}
+	default:
+		fmt.Fprintln(tw, cellText(value))
+	}
+	return tw.Flush()
+}
+
+// tableColumns lists every attribute of rows, first the given ones that occur, then the rest sorted.
+func tableColumns(rows []map[string]any, first []string) []string {
+	seen := map[string]bool{}
+	for _, row := range rows {
+		for key := range row {
+			seen[key] = true
+		}
+	}
+	var columns []string
+	for _, column := range first {
+		if seen[column] {
+			columns = append(columns, column)
+			delete(seen, column)
+		}
+	}
+	rest := make([]string, 0, len(seen))
+	for key := range seen {
+		rest = append(rest, key)
+	}
+	sort.Strings(rest)
+	return append(columns, rest...)
+}
+
+// cellText shows strings and numbers as they are and nested values as compact JSON.
+func cellText(v any) string {
+	switch value := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return value
+	case float64:
+		return strconv.FormatFloat(value, 'f', -1, 64)
+	case bool:
+		return strconv.FormatBool(value)
+	}
+	data, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprint(v)
+	}
+	return string(data)
+}
+
+// toGeneric turns v into maps, slices and scalars by way of JSON, so structs print with their JSON names.
+func toGeneric(v any) (any, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	var generic any
+	if err := json.Unmarshal(data, &generic); err != nil {
+		return nil, err
+	}
+	return generic, nil
+}
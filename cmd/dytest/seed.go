@@ -0,0 +1,35 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+
+	"dytest/fixtures"
+)
+
+// runSeed loads seed files or directories of them: dytest seed [path...]. Without paths it loads
+// the configured ones. Missing tables with a schema are created first.
+func runSeed(ctx context.Context, env *env, args []string) error {
+	fs := flag.NewFlagSet("seed", flag.ContinueOnError)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	paths := fs.Args()
+	if len(paths) == 0 {
+		paths = env.cfg.Seed.Paths
+	}
+	if len(paths) == 0 {
+		return errors.New("usage: dytest seed <file or directory>...")
+	}
+
+	results, err := fixtures.Load(ctx, env.client, paths...)
+	for _, result := range results {
+		if result.Created {
+			fmt.Printf("%s: created %s\n", result.Path, result.Table)
+		}
+		fmt.Printf("%s: put %d items into %s\n", result.Path, result.Items, result.Table)
+	}
+	return err
+}
@@ -0,0 +1,653 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	dynamodbClient "dytest/dynamodb"
+	"dytest/dynamodb/memory"
+	"dytest/export"
+	"dytest/fixtures"
+	"dytest/importer"
+	"dytest/model"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/peterh/liner"
+)
+
+const shellHelp = `Data:
+  get <name=value>...                      print an item by its key
+  put <json>                               write an item
+  delete <name=value>...                   delete an item by its key
+  query <key condition> [where <filter>] [fields <a,b>] [index <name>] [desc]
+  scan [where <filter>] [fields <a,b>] [index <name>]
+  next, or an empty line                   print the next page of the last query or scan
+
+  Conditions are comma-separated, e.g. year=2013,title^=R or info.rating>=7.
+
+Tables:
+  \dt                  list tables
+  \d[escribe] [table]  describe a table, by default the current one
+  \use <table>         make a table current
+  \create <file>       create a table from a JSON or YAML schema file
+  \seed <path>...      load seed files or directories of them
+
+Display:
+  \x [on|off|auto]     expanded display: a block per item with nested maps indented
+  \page <n>            items per page
+  \?                   this help
+  \q                   quit
+`
+
+// shellWidth is how wide a table may get before \x auto switches to expanded display.
+const shellWidth = 120
+
+var shellKeywords = []string{"get", "put", "delete", "query", "scan", "next", "where", "fields", "index", "desc",
+	`\dt`, `\d`, `\describe`, `\use`, `\create`, `\seed`, `\x`, `\page`, `\?`, `\q`}
+
+// shell is an interactive session over a DynamodbClient, in the manner of psql.
+type shell struct {
+	ctx      context.Context
+	env      *env
+	out      io.Writer
+	table    string
+	expanded string
+	pageSize int
+
+	// more prints the next page of the last query or scan; nil when there is none.
+	more func() error
+	// tables caches what completion needs to know about each table.
+	tables map[string]*tableInfo
+}
+
+type tableInfo struct {
+	indexes    []string
+	attributes map[string]bool
+}
+
+// runShell starts an interactive session: dytest shell [-memory] [-table t].
+func runShell(ctx context.Context, env *env, args []string) error {
+	fs := flag.NewFlagSet("shell", flag.ContinueOnError)
+	var (
+		tableName = fs.String("table", "Movies", "table to start with")
+		inMemory  = fs.Bool("memory", false, "work on an in-memory database loaded from the -seed paths instead of DynamoDB")
+		pageSize  = fs.Int("page", 20, "items per page")
+	)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *inMemory {
+		env.client = memory.New()
+		if len(env.cfg.Seed.Paths) > 0 {
+			if err := runSeed(ctx, env, nil); err != nil {
+				return err
+			}
+		}
+	}
+
+	s := &shell{ctx: ctx, env: env, out: os.Stdout, table: *tableName, expanded: "auto", pageSize: *pageSize, tables: map[string]*tableInfo{}}
+
+	line := liner.NewLiner()
+	defer line.Close()
+	line.SetCtrlCAborts(true)
+	line.SetTabCompletionStyle(liner.TabPrints)
+	line.SetWordCompleter(s.complete)
+
+	history := ""
+	if home, err := os.UserHomeDir(); err == nil {
+		history = filepath.Join(home, ".dytest_history")
+		if f, err := os.Open(history); err == nil {
+			line.ReadHistory(f)
+			f.Close()
+		}
+	}
+
+	fmt.Fprintln(s.out, `dytest shell. Type \? for help, \q to quit.`)
+	for {
+		input, err := line.Prompt(s.table + "> ")
+		if errors.Is(err, liner.ErrPromptAborted) {
+			continue
+		}
+		if err != nil {
+			break
+		}
+		input = strings.TrimSpace(input)
+		if input != "" {
+			line.AppendHistory(input)
+		}
+		if input == `\q` || input == "quit" || input == "exit" {
+			break
+		}
+		if err := s.run(input); err != nil {
+			fmt.Fprintf(os.Stderr, "error: %v\n", err)
+		}
+	}
+
+	if history != "" {
+		if f, err := os.Create(history); err == nil {
+			line.WriteHistory(f)
+			f.Close()
+		}
+	}
+	return nil
+}
+
+func (s *shell) run(input string) error {
+	if input == "" || input == "next" {
+		if s.more == nil {
+			return nil
+		}
+		return s.more()
+	}
+
+	command, rest, _ := strings.Cut(input, " ")
+	rest = strings.TrimSpace(rest)
+	args := shellFields(rest)
+	switch command {
+	case `\?`, "help":
+		fmt.Fprint(s.out, shellHelp)
+		return nil
+	case `\dt`:
+		names, err := s.env.client.ListTables(s.ctx)
+		if err != nil {
+			return err
+		}
+		if len(names) == 0 {
+			fmt.Fprintln(s.out, "(no tables)")
+			return nil
+		}
+		return (&printer{format: "table", w: s.out}).print(toItems("table", names))
+	case `\d`, `\describe`:
+		tableName := s.table
+		if len(args) > 0 {
+			tableName = args[0]
+		}
+		return s.describe(tableName)
+	case `\use`, `\c`:
+		if len(args) != 1 {
+			return errors.New(`usage: \use <table>`)
+		}
+		if _, err := s.info(args[0]); err != nil {
+			return err
+		}
+		s.table = args[0]
+		s.more = nil
+		return nil
+	case `\create`:
+		if len(args) != 1 {
+			return errors.New(`usage: \create <schema file>`)
+		}
+		var schema model.TableSchema
+		if err := fixtures.Decode(args[0], &schema); err != nil {
+			return err
+		}
+		if err := fixtures.CreateTable(s.ctx, s.env.client, &schema); err != nil {
+			return err
+		}
+		fmt.Fprintf(s.out, "created %s\n", schema.TableName)
+		return nil
+	case `\seed`:
+		if len(args) == 0 {
+			return errors.New(`usage: \seed <path>...`)
+		}
+		return runSeed(s.ctx, s.env, args)
+	case `\x`:
+		switch {
+		case len(args) == 0 && s.expanded == "on":
+			s.expanded = "off"
+		case len(args) == 0:
+			s.expanded = "on"
+		case args[0] == "on" || args[0] == "off" || args[0] == "auto":
+			s.expanded = args[0]
+		default:
+			return errors.New(`usage: \x [on|off|auto]`)
+		}
+		fmt.Fprintf(s.out, "expanded display is %s\n", s.expanded)
+		return nil
+	case `\page`:
+		n, err := strconv.Atoi(rest)
+		if err != nil || n < 1 {
+			return errors.New(`usage: \page <items per page>`)
+		}
+		s.pageSize = n
+		return nil
+	case "get":
+		keyNames, key, err := parseKey(s.ctx, s.env.client, s.table, unquoteValues(args))
+		if err != nil {
+			return err
+		}
+		var item map[string]any
+		if err := s.env.client.GetItem(s.ctx, s.table, key, &item); err != nil {
+			return err
+		}
+		return s.print([]map[string]any{item}, keyNames)
+	case "put":
+		var data map[string]any
+		if err := json.Unmarshal([]byte(rest), &data); err != nil {
+			return fmt.Errorf("invalid item: %v", err)
+		}
+		item, rejection := importer.Decode(s.table, importer.Row{Number: 1, Data: data})
+		if rejection != nil {
+			return fmt.Errorf("invalid item: %s", strings.TrimPrefix(rejection.Error(), "row 1: "))
+		}
+		return s.env.client.TransactWriteItems(s.ctx, s.table, item)
+	case "delete":
+		_, key, err := parseKey(s.ctx, s.env.client, s.table, unquoteValues(args))
+		if err != nil {
+			return err
+		}
+		return s.env.client.DeleteItem(s.ctx, s.table, key)
+	case "query", "scan":
+		return s.read(command, args)
+	}
+	return fmt.Errorf(`unknown command %q, type \? for help`, command)
+}
+
+// read starts a query or scan and prints its first page.
+func (s *shell) read(command string, args []string) error {
+	var keyCondition, filter, fields, indexName string
+	descending := false
+	if command == "query" {
+		if len(args) == 0 {
+			return errors.New("usage: query <key condition> [where <filter>] [fields <a,b>] [index <name>] [desc]")
+		}
+		keyCondition, args = args[0], args[1:]
+	}
+	for len(args) > 0 {
+		clause := args[0]
+		if clause == "desc" && command == "query" {
+			descending = true
+			args = args[1:]
+			continue
+		}
+		if len(args) < 2 {
+			return fmt.Errorf("%s needs a value", clause)
+		}
+		switch clause {
+		case "where":
+			filter = args[1]
+		case "fields":
+			fields = args[1]
+		case "index":
+			indexName = args[1]
+		default:
+			return fmt.Errorf("unknown clause %q", clause)
+		}
+		args = args[2:]
+	}
+
+	var projected []string
+	if fields != "" {
+		projected = strings.Split(fields, ",")
+	}
+	expressions, err := export.Parse(filter, projected)
+	if err != nil {
+		return err
+	}
+	keyNames, _, err := tableKeys(s.ctx, s.env.client, s.table)
+	if err != nil {
+		return err
+	}
+
+	var fetch func(cursor string, limit int, items *[]map[string]any) (string, error)
+	if command == "query" {
+		key, err := export.ParseKeyCondition(keyCondition)
+		if err != nil {
+			return err
+		}
+		for placeholder, name := range expressions.Names {
+			key.Names[placeholder] = name
+		}
+		for placeholder, value := range expressions.Values {
+			key.Values[placeholder] = value
+		}
+		query := dynamodbClient.QueryRequest{
+			IndexName:    indexName,
+			KeyCondition: key.Filter,
+			Filter:       expressions.Filter,
+			Projection:   expressions.Projection,
+			Names:        key.Names,
+			Values:       key.Values,
+			Descending:   descending,
+		}
+		fetch = func(cursor string, limit int, items *[]map[string]any) (string, error) {
+			query.Cursor, query.Limit = cursor, int32(limit)
+			return s.env.client.Query(s.ctx, s.table, query, items)
+		}
+	} else {
+		scan := dynamodbClient.ScanRequest{
+			IndexName:  indexName,
+			Filter:     expressions.Filter,
+			Projection: expressions.Projection,
+			Names:      expressions.Names,
+			Values:     expressions.Values,
+		}
+		fetch = func(cursor string, limit int, items *[]map[string]any) (string, error) {
+			scan.Cursor, scan.Limit = cursor, int32(limit)
+			return s.env.client.ScanPage(s.ctx, s.table, scan, items)
+		}
+	}
+
+	cursor := ""
+	s.more = func() error {
+		// A filter can leave pages short, so read on until a page is full or the items run out.
+		var items []map[string]any
+		for {
+			var page []map[string]any
+			next, err := fetch(cursor, s.pageSize-len(items), &page)
+			if err != nil {
+				return err
+			}
+			items = append(items, page...)
+			cursor = next
+			if cursor == "" || len(items) >= s.pageSize {
+				break
+			}
+		}
+		if err := s.print(items, keyNames); err != nil {
+			return err
+		}
+		count := fmt.Sprintf("%d items", len(items))
+		if len(items) == 1 {
+			count = "1 item"
+		}
+		if cursor == "" {
+			s.more = nil
+			fmt.Fprintf(s.out, "(%s)\n", count)
+		} else {
+			fmt.Fprintf(s.out, "(%s, more: press Enter or type next)\n", count)
+		}
+		return nil
+	}
+	return s.more()
+}
+
+// describe prints a table's keys, indexes and time to live.
+func (s *shell) describe(tableName string) error {
+	description, err := s.env.client.DescribeTable(s.ctx, tableName)
+	if err != nil {
+		return err
+	}
+	ttl, err := s.env.client.DescribeTimeToLive(s.ctx, tableName)
+	if err != nil {
+		return err
+	}
+	attributeTypes := map[string]types.ScalarAttributeType{}
+	for _, definition := range description.AttributeDefinitions {
+		attributeTypes[aws.ToString(definition.AttributeName)] = definition.AttributeType
+	}
+	keys := func(schema []types.KeySchemaElement) string {
+		parts := make([]string, 0, len(schema))
+		for _, element := range schema {
+			name := aws.ToString(element.AttributeName)
+			parts = append(parts, fmt.Sprintf("%s %s (%s)", name, attributeTypes[name], element.KeyType))
+		}
+		return strings.Join(parts, ", ")
+	}
+	projection := func(p *types.Projection) string {
+		if p == nil {
+			return ""
+		}
+		if len(p.NonKeyAttributes) > 0 {
+			return fmt.Sprintf("%s %s", p.ProjectionType, strings.Join(p.NonKeyAttributes, ","))
+		}
+		return string(p.ProjectionType)
+	}
+
+	tw := tabwriter.NewWriter(s.out, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(tw, "Table %s\n", tableName)
+	fmt.Fprintf(tw, "  status\t%s\n", description.TableStatus)
+	fmt.Fprintf(tw, "  items\t%d\n", aws.ToInt64(description.ItemCount))
+	fmt.Fprintf(tw, "  key\t%s\n", keys(description.KeySchema))
+	if description.BillingModeSummary != nil && description.BillingModeSummary.BillingMode != "" {
+		fmt.Fprintf(tw, "  billing\t%s\n", description.BillingModeSummary.BillingMode)
+	} else if throughput := description.ProvisionedThroughput; throughput != nil {
+		fmt.Fprintf(tw, "  billing\tPROVISIONED %d read, %d write\n", aws.ToInt64(throughput.ReadCapacityUnits), aws.ToInt64(throughput.WriteCapacityUnits))
+	}
+	if ttl.TimeToLiveStatus == types.TimeToLiveStatusEnabled {
+		fmt.Fprintf(tw, "  time to live\t%s\n", aws.ToString(ttl.AttributeName))
+	}
+	if len(description.GlobalSecondaryIndexes)+len(description.LocalSecondaryIndexes) > 0 {
+		fmt.Fprintln(tw, "Indexes")
+	}
+	for _, index := range description.GlobalSecondaryIndexes {
+		fmt.Fprintf(tw, "  %s\tglobal\t%s\t%s\n", aws.ToString(index.IndexName), keys(index.KeySchema), projection(index.Projection))
+	}
+	for _, index := range description.LocalSecondaryIndexes {
+		fmt.Fprintf(tw, "  %s\tlocal\t%s\t%s\n", aws.ToString(index.IndexName), keys(index.KeySchema), projection(index.Projection))
+	}
+	return tw.Flush()
+}
+
+// print shows items as a table or, with \x, as a block per item; \x auto picks a block per item
+// when the table would be wider than shellWidth. It remembers the attributes for completion.
+func (s *shell) print(items []map[string]any, keyNames []string) error {
+	if info, err := s.info(s.table); err == nil {
+		for _, item := range items {
+			addPaths(info.attributes, "", item)
+		}
+	}
+
+	generic, err := toGeneric(items)
+	if err != nil {
+		return err
+	}
+	rows, _ := generic.([]any)
+	if len(rows) == 0 {
+		return nil
+	}
+
+	if s.expanded != "on" {
+		var table bytes.Buffer
+		if err := (&printer{format: "table", w: &table}).print(items, keyNames...); err != nil {
+			return err
+		}
+		if s.expanded == "off" || maxLineWidth(table.String()) <= shellWidth {
+			_, err := s.out.Write(table.Bytes())
+			return err
+		}
+	}
+
+	for i, row := range rows {
+		item, _ := row.(map[string]any)
+		fmt.Fprintf(s.out, "-[ %d ]%s\n", i+1, strings.Repeat("-", 30))
+		tw := tabwriter.NewWriter(s.out, 0, 0, 2, ' ', 0)
+		writeExpanded(tw, "", item, tableColumns([]map[string]any{item}, keyNames))
+		if err := tw.Flush(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// writeExpanded writes an attribute per line, with the attributes of nested maps indented below
+// their name, so an item's info reads as a block of its own.
+func writeExpanded(w io.Writer, indent string, m map[string]any, names []string) {
+	for _, name := range names {
+		switch value := m[name].(type) {
+		case map[string]any:
+			fmt.Fprintf(w, "%s%s\t\n", indent, name)
+			writeExpanded(w, indent+"  ", value, tableColumns([]map[string]any{value}, nil))
+		case []any:
+			if !containsMap(value) {
+				fmt.Fprintf(w, "%s%s\t%s\n", indent, name, listText(value))
+				continue
+			}
+			fmt.Fprintf(w, "%s%s\t\n", indent, name)
+			for i, element := range value {
+				element := map[string]any{fmt.Sprintf("[%d]", i): element}
+				writeExpanded(w, indent+"  ", element, tableColumns([]map[string]any{element}, nil))
+			}
+		default:
+			fmt.Fprintf(w, "%s%s\t%s\n", indent, name, cellText(value))
+		}
+	}
+}
+
+func containsMap(list []any) bool {
+	for _, element := range list {
+		if _, ok := element.(map[string]any); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// listText shows a list of scalars as a, b, c.
+func listText(list []any) string {
+	parts := make([]string, len(list))
+	for i, element := range list {
+		parts[i] = cellText(element)
+	}
+	return strings.Join(parts, ", ")
+}
+
+func maxLineWidth(text string) int {
+	width := 0
+	for _, line := range strings.Split(text, "\n") {
+		width = max(width, len([]rune(line)))
+	}
+	return width
+}
+
+// toItems turns a list of names into rows of one column.
+func toItems(column string, names []string) []map[string]any {
+	items := make([]map[string]any, len(names))
+	for i, name := range names {
+		items[i] = map[string]any{column: name}
+	}
+	return items
+}
+
+// info returns what completion knows of a table, describing it the first time.
+func (s *shell) info(tableName string) (*tableInfo, error) {
+	if info, ok := s.tables[tableName]; ok {
+		return info, nil
+	}
+	description, err := s.env.client.DescribeTable(s.ctx, tableName)
+	if err != nil {
+		return nil, err
+	}
+	info := &tableInfo{attributes: map[string]bool{}}
+	for _, definition := range description.AttributeDefinitions {
+		info.attributes[aws.ToString(definition.AttributeName)] = true
+	}
+	for _, index := range description.GlobalSecondaryIndexes {
+		info.indexes = append(info.indexes, aws.ToString(index.IndexName))
+	}
+	for _, index := range description.LocalSecondaryIndexes {
+		info.indexes = append(info.indexes, aws.ToString(index.IndexName))
+	}
+	s.tables[tableName] = info
+	return info, nil
+}
+
+// addPaths records the attribute paths of an item, nested ones as info.rating.
+func addPaths(paths map[string]bool, prefix string, m map[string]any) {
+	for name, value := range m {
+		paths[prefix+name] = true
+		if nested, ok := value.(map[string]any); ok {
+			addPaths(paths, prefix+name+".", nested)
+		}
+	}
+}
+
+// complete completes the word at pos: a command first, then table names after \d and \use, index
+// names after index, and otherwise the attribute names of the current table. Conditions are
+// completed after their last comma.
+func (s *shell) complete(line string, pos int) (string, []string, string) {
+	head, tail := line[:pos], line[pos:]
+	start := strings.LastIndexAny(head, " ,") + 1
+	word := head[start:]
+	before := strings.Fields(head[:start])
+
+	var candidates []string
+	switch {
+	case len(before) == 0:
+		candidates = shellKeywords
+	case before[len(before)-1] == "index":
+		if info, err := s.info(s.table); err == nil {
+			candidates = info.indexes
+		}
+	case before[0] == `\d` || before[0] == `\describe` || before[0] == `\use` || before[0] == `\c`:
+		candidates, _ = s.env.client.ListTables(s.ctx)
+	default:
+		if info, err := s.info(s.table); err == nil {
+			for path := range info.attributes {
+				candidates = append(candidates, path)
+			}
+		}
+		if before[0] == "query" || before[0] == "scan" {
+			candidates = append(candidates, "where", "fields", "index", "desc")
+		}
+	}
+
+	var completions []string
+	for _, candidate := range candidates {
+		if strings.HasPrefix(candidate, word) {
+			completions = append(completions, candidate)
+		}
+	}
+	sort.Strings(completions)
+	return head[:start], completions, tail
+}
+
+// shellFields splits a line at spaces outside double and single quotes. The quotes stay, since
+// filters use them to mark strings.
+func shellFields(line string) []string {
+	var fields []string
+	var field strings.Builder
+	var quote rune
+	inField := false
+	for _, r := range line {
+		switch {
+		case quote != 0:
+			if r == quote {
+				quote = 0
+			}
+		case r == '"' || r == '\'':
+			quote = r
+		case r == ' ' || r == '\t':
+			if inField {
+				fields = append(fields, field.String())
+				field.Reset()
+				inField = false
+			}
+			continue
+		}
+		field.WriteRune(r)
+		inField = true
+	}
+	if inField {
+		fields = append(fields, field.String())
+	}
+	return fields
+}
+
+// unquoteValues strips the quotes around the values of name=value arguments.
+func unquoteValues(args []string) []string {
+	unquoted := make([]string, len(args))
+	for i, arg := range args {
+		name, value, ok := strings.Cut(arg, "=")
+		if ok && len(value) >= 2 && (value[0] == '"' || value[0] == '\'') && value[len(value)-1] == value[0] {
+			value = value[1 : len(value)-1]
+		}
+		unquoted[i] = name + "=" + value
+		if !ok {
+			unquoted[i] = arg
+		}
+	}
+	return unquoted
+}
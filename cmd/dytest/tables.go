@@ -0,0 +1,73 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+
+	"dytest/fixtures"
+	"dytest/model"
+)
+
+// runTables manages tables: dytest tables list|describe|create|delete.
+func runTables(ctx context.Context, env *env, args []string) error {
+	if len(args) == 0 {
+		return errors.New("usage: dytest tables list|describe <table>|create -file <schema>|delete <table>")
+	}
+
+	fs := flag.NewFlagSet("tables "+args[0], flag.ContinueOnError)
+	out := outputFlag(fs)
+	file := fs.String("file", "", "create: JSON or YAML schema file")
+	if err := fs.Parse(args[1:]); err != nil {
+		return err
+	}
+	if err := out.check(); err != nil {
+		return err
+	}
+
+	switch args[0] {
+	case "list":
+		names, err := env.client.ListTables(ctx)
+		if err != nil {
+			return err
+		}
+		return out.print(names)
+	case "describe":
+		if fs.NArg() != 1 {
+			return errors.New("usage: dytest tables describe <table>")
+		}
+		description, err := env.client.DescribeTable(ctx, fs.Arg(0))
+		if err != nil {
+			return err
+		}
+		ttl, err := env.client.DescribeTimeToLive(ctx, fs.Arg(0))
+		if err != nil {
+			return err
+		}
+		return out.print(map[string]any{"table": description, "timeToLive": ttl})
+	case "create":
+		if *file == "" {
+			return errors.New("usage: dytest tables create -file <schema>")
+		}
+		var schema model.TableSchema
+		if err := fixtures.Decode(*file, &schema); err != nil {
+			return err
+		}
+		if err := fixtures.CreateTable(ctx, env.client, &schema); err != nil {
+			return err
+		}
+		fmt.Printf("created %s\n", schema.TableName)
+		return nil
+	case "delete":
+		if fs.NArg() != 1 {
+			return errors.New("usage: dytest tables delete <table>")
+		}
+		if err := env.client.DeleteTable(ctx, fs.Arg(0)); err != nil {
+			return err
+		}
+		fmt.Printf("deleted %s\n", fs.Arg(0))
+		return nil
+	}
+	return fmt.Errorf("unknown tables command %q", args[0])
+}
@@ -0,0 +1,528 @@
+package config
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/BurntSushi/toml"
+	"gopkg.in/yaml.v3"
+)
+
+type Config struct {
+	Server   ServerConfig   `yaml:"server" toml:"server"`
+	DynamoDB DynamoDBConfig `yaml:"dynamodb" toml:"dynamodb"`
+
+	// Clients are additional named DynamoDB connections next to the default one above.
+	Clients map[string]DynamoDBConfig `yaml:"clients" toml:"clients"`
+	// Tables maps a table name to the client that serves it. Unmapped tables use the default client.
+	Tables map[string]string `yaml:"tables" toml:"tables"`
+
+	Tenant   TenantConfig   `yaml:"tenant" toml:"tenant"`
+	Streams  StreamsConfig  `yaml:"streams" toml:"streams"`
+	Events   EventsConfig   `yaml:"events" toml:"events"`
+	Outbox   OutboxConfig   `yaml:"outbox" toml:"outbox"`
+	Webhooks WebhooksConfig `yaml:"webhooks" toml:"webhooks"`
+	PartiQL  PartiQLConfig  `yaml:"partiql" toml:"partiql"`
+	GRPC     GRPCConfig     `yaml:"grpc" toml:"grpc"`
+	Export   ExportConfig   `yaml:"export" toml:"export"`
+	Import   ImportConfig   `yaml:"import" toml:"import"`
+	Jobs     JobsConfig     `yaml:"jobs" toml:"jobs"`
+	Seed     SeedConfig     `yaml:"seed" toml:"seed"`
+}
+
+type SeedConfig struct {
+	// Paths are seed files, or directories of them, that the server loads at startup; see package fixtures.
+	Paths []string `yaml:"paths" toml:"paths"`
+}
+
+type JobsConfig struct {
+	TableName string `yaml:"tableName" toml:"tableName"`
+	Workers   int    `yaml:"workers" toml:"workers"`
+	// PollInterval is how often the table is checked for queued jobs and jobs left by a stopped server.
+	PollInterval time.Duration `yaml:"pollInterval" toml:"pollInterval"`
+	// Lease is how long a job stays with a server that stopped renewing it.
+	Lease time.Duration `yaml:"lease" toml:"lease"`
+	// DataDir keeps uploads waiting to be imported and finished exports.
+	DataDir string `yaml:"dataDir" toml:"dataDir"`
+}
+
+type ImportConfig struct {
+	// TableName keeps the checkpoints of HTTP imports.
+	TableName string `yaml:"tableName" toml:"tableName"`
+	// Rate caps the items an import writes per second; 0 does not limit.
+	Rate int `yaml:"rate" toml:"rate"`
+	// MaxUploadBytes is the largest file POST /imports accepts.
+	MaxUploadBytes int `yaml:"maxUploadBytes" toml:"maxUploadBytes"`
+}
+
+type ExportConfig struct {
+	// PageSize is how many items an export reads and writes at a time.
+	PageSize int `yaml:"pageSize" toml:"pageSize"`
+	// CSVColumns are the default CSV columns, each "path" or "header=path", e.g. "rating=info.rating".
+	CSVColumns []string `yaml:"csvColumns" toml:"csvColumns"`
+}
+
+type GRPCConfig struct {
+	// Enabled serves the MovieService, health checks and reflection on Addr next to the HTTP server.
+	Enabled bool   `yaml:"enabled" toml:"enabled"`
+	Addr    string `yaml:"addr" toml:"addr"`
+}
+
+type PartiQLConfig struct {
+	// Enabled exposes the admin /partiql endpoint.
+	Enabled bool `yaml:"enabled" toml:"enabled"`
+	// AllowWrites lets the endpoint run INSERT, UPDATE and DELETE as well as SELECT.
+	AllowWrites bool `yaml:"allowWrites" toml:"allowWrites"`
+}
+
+type WebhooksConfig struct {
+	Enabled            bool          `yaml:"enabled" toml:"enabled"`
+	SubscriptionsTable string        `yaml:"subscriptionsTable" toml:"subscriptionsTable"`
+	DeliveriesTable    string        `yaml:"deliveriesTable" toml:"deliveriesTable"`
+	Workers            int           `yaml:"workers" toml:"workers"`
+	Timeout            time.Duration `yaml:"timeout" toml:"timeout"`
+	// MaxAttempts is how often a delivery is tried before it is dead-lettered.
+	MaxAttempts int           `yaml:"maxAttempts" toml:"maxAttempts"`
+	Backoff     time.Duration `yaml:"backoff" toml:"backoff"`
+	MaxBackoff  time.Duration `yaml:"maxBackoff" toml:"maxBackoff"`
+	// SweepInterval is how often failed deliveries that are due are retried.
+	SweepInterval time.Duration `yaml:"sweepInterval" toml:"sweepInterval"`
+	// CacheTTL is how long a server keeps the subscriptions to an event; 0 reads them on every write.
+	CacheTTL time.Duration `yaml:"cacheTTL" toml:"cacheTTL"`
+	// AllowPrivateAddresses lets webhooks reach loopback, link-local and private addresses,
+	// e.g. a receiver on localhost during development. Keep it off where tenants register webhooks.
+	AllowPrivateAddresses bool `yaml:"allowPrivateAddresses" toml:"allowPrivateAddresses"`
+}
+
+type OutboxConfig struct {
+	Enabled   bool   `yaml:"enabled" toml:"enabled"`
+	TableName string `yaml:"tableName" toml:"tableName"`
+	// Tables whose writes get an outbox record.
+	Tables []string `yaml:"tables" toml:"tables"`
+	// Sink is "stdout", "file" (FilePath) or "webhook" (WebhookURL).
+	Sink        string        `yaml:"sink" toml:"sink"`
+	FilePath    string        `yaml:"filePath" toml:"filePath"`
+	WebhookURL  string        `yaml:"webhookUrl" toml:"webhookUrl"`
+	Interval    time.Duration `yaml:"interval" toml:"interval"`
+	MaxAttempts int           `yaml:"maxAttempts" toml:"maxAttempts"`
+	Backoff     time.Duration `yaml:"backoff" toml:"backoff"`
+	MaxBackoff  time.Duration `yaml:"maxBackoff" toml:"maxBackoff"`
+	Retention   time.Duration `yaml:"retention" toml:"retention"`
+}
+
+type EventsConfig struct {
+	// Source is "hook" to publish the server's own writes, or "stream" to publish from the
+	// Movies stream, which also sees writes from other processes and needs streams enabled.
+	Source string `yaml:"source" toml:"source"`
+	// History is how many events are kept for clients resuming with Last-Event-ID.
+	History int `yaml:"history" toml:"history"`
+}
+
+type StreamsConfig struct {
+	Enabled bool `yaml:"enabled" toml:"enabled"`
+	// LeaseTable stores the per-shard checkpoints.
+	LeaseTable   string        `yaml:"leaseTable" toml:"leaseTable"`
+	PollInterval time.Duration `yaml:"pollInterval" toml:"pollInterval"`
+}
+
+type TenantConfig struct {
+	// Header carries the tenant ID when no JWT claim is configured.
+	Header string `yaml:"header" toml:"header"`
+	// JWTClaim is read from an HS256 bearer token signed with JWTSecret. When it is set, Header is
+	// ignored and a request naming a tenant without a valid token is refused with 401.
+	JWTClaim  string `yaml:"jwtClaim" toml:"jwtClaim"`
+	JWTSecret string `yaml:"jwtSecret" toml:"jwtSecret"`
+	// Tables lists the tenant-scoped tables by logical name.
+	Tables map[string]TenantTableConfig `yaml:"tables" toml:"tables"`
+}
+
+type TenantTableConfig struct {
+	// Mode is "prefix" to prepend the tenant to the partition key, or "table" to use a
+	// <table>_<tenant> table per tenant.
+	Mode         string `yaml:"mode" toml:"mode"`
+	PartitionKey string `yaml:"partitionKey" toml:"partitionKey"`
+}
+
+type ServerConfig struct {
+	Addr        string `yaml:"addr" toml:"addr"`
+	LazyConnect bool   `yaml:"lazyConnect" toml:"lazyConnect"`
+	// RequiredTables must exist, and DynamoDB be reachable, for /readyz to report ready.
+	RequiredTables []string `yaml:"requiredTables" toml:"requiredTables"`
+}
+
+type DynamoDBConfig struct {
+	// Endpoint overrides the service endpoint, e.g. http://localhost:8000 for dynamodb-local.
+	// Leave it empty to use the regional AWS endpoint.
+	Endpoint string `yaml:"endpoint" toml:"endpoint"`
+	Region   string `yaml:"region" toml:"region"`
+	Profile  string `yaml:"profile" toml:"profile"`
+
+	AccessKeyID     string `yaml:"accessKeyId" toml:"accessKeyId"`
+	SecretAccessKey string `yaml:"secretAccessKey" toml:"secretAccessKey"`
+	SessionToken    string `yaml:"sessionToken" toml:"sessionToken"`
+
+	AssumeRoleARN         string `yaml:"assumeRoleArn" toml:"assumeRoleArn"`
+	AssumeRoleSessionName string `yaml:"assumeRoleSessionName" toml:"assumeRoleSessionName"`
+	AssumeRoleExternalID  string `yaml:"assumeRoleExternalId" toml:"assumeRoleExternalId"`
+
+	HTTPTimeout time.Duration `yaml:"httpTimeout" toml:"httpTimeout"`
+	MaxConns    int           `yaml:"maxConns" toml:"maxConns"`
+
+	// TablePrefix and TableSuffix namespace every physical table name, e.g. "dev_alice_"
+	// turns Movies into dev_alice_Movies on a shared dynamodb-local.
+	TablePrefix string `yaml:"tablePrefix" toml:"tablePrefix"`
+	TableSuffix string `yaml:"tableSuffix" toml:"tableSuffix"`
+
+	// FilterExpired hides items past their TTL on reads, before DynamoDB gets around to deleting them.
+	FilterExpired bool `yaml:"filterExpired" toml:"filterExpired"`
+
+	// BreakerThreshold consecutive failed calls open the circuit for BreakerCooldown.
+	// A threshold of 0 disables the breaker.
+	BreakerThreshold int           `yaml:"breakerThreshold" toml:"breakerThreshold"`
+	BreakerCooldown  time.Duration `yaml:"breakerCooldown" toml:"breakerCooldown"`
+}
+
+// Default targets the dynamodb-local container from docker-compose.yml.
+func Default() *Config {
+	return &Config{
+		Server: ServerConfig{
+			Addr:           ":3000",
+			RequiredTables: []string{"Movies"},
+		},
+		GRPC: GRPCConfig{
+			Enabled: true,
+			Addr:    ":50051",
+		},
+		Tenant: TenantConfig{
+			Header: "X-Tenant-ID",
+		},
+		Import: ImportConfig{
+			TableName:      "Imports",
+			Rate:           500,
+			MaxUploadBytes: 64 << 20,
+		},
+		Jobs: JobsConfig{
+			TableName:    "Jobs",
+			Workers:      2,
+			PollInterval: 5 * time.Second,
+			Lease:        30 * time.Second,
+			DataDir:      "data/jobs",
+		},
+		Export: ExportConfig{
+			PageSize: 100,
+			CSVColumns: []string{
+				"title", "year",
+				"release_date=info.release_date", "rating=info.rating", "rank=info.rank",
+				"running_time_secs=info.running_time_secs", "genres=info.genres",
+				"directors=info.directors", "actors=info.actors", "plot=info.plot", "image_url=info.image_url",
+			},
+		},
+		Streams: StreamsConfig{
+			LeaseTable:   "StreamLeases",
+			PollInterval: time.Second,
+		},
+		Events: EventsConfig{
+			Source:  "hook",
+			History: 1000,
+		},
+		Outbox: OutboxConfig{
+			TableName:   "Outbox",
+			Tables:      []string{"Movies"},
+			Sink:        "stdout",
+			Interval:    2 * time.Second,
+			MaxAttempts: 10,
+			Backoff:     time.Second,
+			MaxBackoff:  5 * time.Minute,
+			Retention:   24 * time.Hour,
+		},
+		Webhooks: WebhooksConfig{
+			SubscriptionsTable: "WebhookSubscriptions",
+			DeliveriesTable:    "WebhookDeliveries",
+			Workers:            4,
+			Timeout:            10 * time.Second,
+			MaxAttempts:        8,
+			Backoff:            2 * time.Second,
+			MaxBackoff:         10 * time.Minute,
+			SweepInterval:      5 * time.Second,
+			CacheTTL:           30 * time.Second,
+		},
+		DynamoDB: DynamoDBConfig{
+			Endpoint:         "http://localhost:8000",
+			Region:           "localhost",
+			HTTPTimeout:      30 * time.Second,
+			MaxConns:         100,
+			BreakerThreshold: 5,
+			BreakerCooldown:  30 * time.Second,
+		},
+	}
+}
+
+// Load builds the configuration from defaults, an optional YAML or TOML file,
+// environment variables and command-line flags, each overriding the previous one.
+// The file is taken from -config or DYTEST_CONFIG.
+func Load(args []string) (*Config, error) {
+	cfg, _, err := LoadArgs(args)
+	return cfg, err
+}
+
+// LoadArgs is Load for commands: it also returns the arguments after the flags.
+func LoadArgs(args []string) (*Config, []string, error) {
+	cfg := Default()
+
+	fs := flag.NewFlagSet("dytest", flag.ContinueOnError)
+	var (
+		path        = fs.String("config", os.Getenv("DYTEST_CONFIG"), "path to a YAML or TOML config file")
+		addr        = fs.String("addr", "", "HTTP listen address")
+		grpcAddr    = fs.String("grpc-addr", "", "gRPC listen address")
+		lazyConnect = fs.Bool("lazy-connect", false, "start without waiting for DynamoDB")
+		endpoint    = fs.String("endpoint", "", "DynamoDB endpoint override, empty for the AWS regional endpoint")
+		region      = fs.String("region", "", "AWS region")
+		profile     = fs.String("profile", "", "AWS shared config profile")
+		roleARN     = fs.String("assume-role-arn", "", "IAM role to assume")
+		httpTimeout = fs.Duration("http-timeout", 0, "HTTP client timeout")
+		maxConns    = fs.Int("max-conns", 0, "maximum connections per host")
+		tablePrefix = fs.String("table-prefix", "", "prefix added to every table name")
+		tableSuffix = fs.String("table-suffix", "", "suffix added to every table name")
+		seed        = fs.String("seed", "", "comma-separated seed files or directories to load at startup")
+	)
+	if err := fs.Parse(args); err != nil {
+		return nil, nil, err
+	}
+
+	if *path != "" {
+		if err := loadFile(*path, cfg); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	if err := loadEnv(cfg); err != nil {
+		return nil, nil, err
+	}
+
+	fs.Visit(func(f *flag.Flag) {
+		switch f.Name {
+		case "addr":
+			cfg.Server.Addr = *addr
+		case "grpc-addr":
+			cfg.GRPC.Addr = *grpcAddr
+		case "lazy-connect":
+			cfg.Server.LazyConnect = *lazyConnect
+		case "endpoint":
+			cfg.DynamoDB.Endpoint = *endpoint
+		case "region":
+			cfg.DynamoDB.Region = *region
+		case "profile":
+			cfg.DynamoDB.Profile = *profile
+		case "assume-role-arn":
+			cfg.DynamoDB.AssumeRoleARN = *roleARN
+		case "http-timeout":
+			cfg.DynamoDB.HTTPTimeout = *httpTimeout
+		case "max-conns":
+			cfg.DynamoDB.MaxConns = *maxConns
+		case "table-prefix":
+			cfg.DynamoDB.TablePrefix = *tablePrefix
+		case "table-suffix":
+			cfg.DynamoDB.TableSuffix = *tableSuffix
+		case "seed":
+			cfg.Seed.Paths = splitList(*seed)
+		}
+	})
+
+	if err := validate(cfg); err != nil {
+		return nil, nil, err
+	}
+	return cfg, fs.Args(), nil
+}
+
+// validate refuses settings that would only fail once the server runs.
+func validate(cfg *Config) error {
+	if cfg.Jobs.PollInterval <= 0 {
+		return fmt.Errorf("invalid jobs.pollInterval %s: must be positive", cfg.Jobs.PollInterval)
+	}
+	if cfg.Jobs.Lease <= 0 {
+		return fmt.Errorf("invalid jobs.lease %s: must be positive", cfg.Jobs.Lease)
+	}
+	return nil
+}
+
+// ClientConfigFor returns the settings of the client a table is mapped to.
+func (c *Config) ClientConfigFor(tableName string) DynamoDBConfig {
+	if clientCfg, ok := c.Clients[c.Tables[tableName]]; ok {
+		return clientCfg
+	}
+	return c.DynamoDB
+}
+
+func loadFile(path string, cfg *Config) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("unable to read config file: %v", err)
+	}
+
+	switch filepath.Ext(path) {
+	case ".yaml", ".yml":
+		err = yaml.Unmarshal(data, cfg)
+	case ".toml":
+		err = toml.Unmarshal(data, cfg)
+	default:
+		return fmt.Errorf("unsupported config file type %q", filepath.Ext(path))
+	}
+	if err != nil {
+		return fmt.Errorf("unable to parse config file %s: %v", path, err)
+	}
+	return nil
+}
+
+func loadEnv(cfg *Config) error {
+	fields := map[string]*string{
+		"SERVER_ADDR":                      &cfg.Server.Addr,
+		"GRPC_ADDR":                        &cfg.GRPC.Addr,
+		"DYNAMODB_ENDPOINT":                &cfg.DynamoDB.Endpoint,
+		"AWS_REGION":                       &cfg.DynamoDB.Region,
+		"AWS_PROFILE":                      &cfg.DynamoDB.Profile,
+		"AWS_ACCESS_KEY_ID":                &cfg.DynamoDB.AccessKeyID,
+		"AWS_SECRET_ACCESS_KEY":            &cfg.DynamoDB.SecretAccessKey,
+		"AWS_SESSION_TOKEN":                &cfg.DynamoDB.SessionToken,
+		"DYNAMODB_ASSUME_ROLE_ARN":         &cfg.DynamoDB.AssumeRoleARN,
+		"DYNAMODB_ASSUME_ROLE_SESSION":     &cfg.DynamoDB.AssumeRoleSessionName,
+		"DYNAMODB_ASSUME_ROLE_EXTERNAL_ID": &cfg.DynamoDB.AssumeRoleExternalID,
+		"DYNAMODB_TABLE_PREFIX":            &cfg.DynamoDB.TablePrefix,
+		"DYNAMODB_TABLE_SUFFIX":            &cfg.DynamoDB.TableSuffix,
+		"TENANT_HEADER":                    &cfg.Tenant.Header,
+		"TENANT_JWT_CLAIM":                 &cfg.Tenant.JWTClaim,
+		"TENANT_JWT_SECRET":                &cfg.Tenant.JWTSecret,
+		"EVENTS_SOURCE":                    &cfg.Events.Source,
+		"OUTBOX_SINK":                      &cfg.Outbox.Sink,
+		"OUTBOX_FILE":                      &cfg.Outbox.FilePath,
+		"OUTBOX_WEBHOOK_URL":               &cfg.Outbox.WebhookURL,
+		"JOBS_DATA_DIR":                    &cfg.Jobs.DataDir,
+	}
+	for name, field := range fields {
+		// LookupEnv so that e.g. DYNAMODB_ENDPOINT= clears the dynamodb-local default.
+		if v, ok := os.LookupEnv(name); ok {
+			*field = v
+		}
+	}
+
+	if v, ok := os.LookupEnv("DYNAMODB_LAZY_CONNECT"); ok {
+		b, err := strconv.ParseBool(v)
+		if err != nil {
+			return fmt.Errorf("invalid DYNAMODB_LAZY_CONNECT: %v", err)
+		}
+		cfg.Server.LazyConnect = b
+	}
+	if v, ok := os.LookupEnv("DYNAMODB_FILTER_EXPIRED"); ok {
+		b, err := strconv.ParseBool(v)
+		if err != nil {
+			return fmt.Errorf("invalid DYNAMODB_FILTER_EXPIRED: %v", err)
+		}
+		cfg.DynamoDB.FilterExpired = b
+	}
+	if v, ok := os.LookupEnv("DYNAMODB_STREAMS_ENABLED"); ok {
+		b, err := strconv.ParseBool(v)
+		if err != nil {
+			return fmt.Errorf("invalid DYNAMODB_STREAMS_ENABLED: %v", err)
+		}
+		cfg.Streams.Enabled = b
+	}
+	if v, ok := os.LookupEnv("OUTBOX_ENABLED"); ok {
+		b, err := strconv.ParseBool(v)
+		if err != nil {
+			return fmt.Errorf("invalid OUTBOX_ENABLED: %v", err)
+		}
+		cfg.Outbox.Enabled = b
+	}
+	if v, ok := os.LookupEnv("WEBHOOKS_ENABLED"); ok {
+		b, err := strconv.ParseBool(v)
+		if err != nil {
+			return fmt.Errorf("invalid WEBHOOKS_ENABLED: %v", err)
+		}
+		cfg.Webhooks.Enabled = b
+	}
+	if v, ok := os.LookupEnv("GRPC_ENABLED"); ok {
+		b, err := strconv.ParseBool(v)
+		if err != nil {
+			return fmt.Errorf("invalid GRPC_ENABLED: %v", err)
+		}
+		cfg.GRPC.Enabled = b
+	}
+	if v, ok := os.LookupEnv("PARTIQL_ENABLED"); ok {
+		b, err := strconv.ParseBool(v)
+		if err != nil {
+			return fmt.Errorf("invalid PARTIQL_ENABLED: %v", err)
+		}
+		cfg.PartiQL.Enabled = b
+	}
+	if v, ok := os.LookupEnv("DYNAMODB_HTTP_TIMEOUT"); ok {
+		d, err := time.ParseDuration(v)
+		if err != nil {
+			return fmt.Errorf("invalid DYNAMODB_HTTP_TIMEOUT: %v", err)
+		}
+		cfg.DynamoDB.HTTPTimeout = d
+	}
+	if v, ok := os.LookupEnv("DYNAMODB_MAX_CONNS"); ok {
+		n, err := strconv.Atoi(v)
+		if err != nil {
+			return fmt.Errorf("invalid DYNAMODB_MAX_CONNS: %v", err)
+		}
+		cfg.DynamoDB.MaxConns = n
+	}
+	if v, ok := os.LookupEnv("EXPORT_PAGE_SIZE"); ok {
+		n, err := strconv.Atoi(v)
+		if err != nil {
+			return fmt.Errorf("invalid EXPORT_PAGE_SIZE: %v", err)
+		}
+		cfg.Export.PageSize = n
+	}
+	if v, ok := os.LookupEnv("IMPORT_RATE"); ok {
+		n, err := strconv.Atoi(v)
+		if err != nil {
+			return fmt.Errorf("invalid IMPORT_RATE: %v", err)
+		}
+		cfg.Import.Rate = n
+	}
+	if v, ok := os.LookupEnv("SERVER_REQUIRED_TABLES"); ok {
+		cfg.Server.RequiredTables = splitList(v)
+	}
+	if v, ok := os.LookupEnv("DYTEST_SEED"); ok {
+		cfg.Seed.Paths = splitList(v)
+	}
+	if v, ok := os.LookupEnv("JOBS_WORKERS"); ok {
+		n, err := strconv.Atoi(v)
+		if err != nil {
+			return fmt.Errorf("invalid JOBS_WORKERS: %v", err)
+		}
+		cfg.Jobs.Workers = n
+	}
+	if v, ok := os.LookupEnv("DYNAMODB_BREAKER_THRESHOLD"); ok {
+		n, err := strconv.Atoi(v)
+		if err != nil {
+			return fmt.Errorf("invalid DYNAMODB_BREAKER_THRESHOLD: %v", err)
+		}
+		cfg.DynamoDB.BreakerThreshold = n
+	}
+	if v, ok := os.LookupEnv("DYNAMODB_BREAKER_COOLDOWN"); ok {
+		d, err := time.ParseDuration(v)
+		if err != nil {
+			return fmt.Errorf("invalid DYNAMODB_BREAKER_COOLDOWN: %v", err)
+		}
+		cfg.DynamoDB.BreakerCooldown = d
+	}
+	return nil
+}
+
+// splitList splits a comma-separated list, dropping empty entries.
+func splitList(v string) []string {
+	var list []string
+	for _, entry := range strings.Split(v, ",") {
+		if entry = strings.TrimSpace(entry); entry != "" {
+			list = append(list, entry)
+		}
+	}
+	return list
+}
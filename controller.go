@@ -25,6 +25,10 @@ type Controller interface {
 	UpdateMovieItem(c *fiber.Ctx) error
 }
 
+type ErrorMessage struct {
+	Error string `json:"error"`
+}
+
 type DynamoDBController struct {
 	Client *dynamodb.Client
 	Service *DynamoDBService
@@ -160,7 +164,7 @@ func (ctrl *DynamoDBController) UpdateMovieItem(c *fiber.Ctx) error {
 	titleAttr, _ := attributevalue.Marshal(requestBody.Title)
 	yearAttr, _ := attributevalue.Marshal(requestBody.Year)
 
-	err := UpdateMovieItem(ctrl.Client, requestBody.TableName, keys{"title": titleAttr, "year": yearAttr}, requestBody.UpdateExpression, avMap)
+	err := UpdateMovieItem(ctrl.Client, "Movies", keys{"title": titleAttr, "year": yearAttr}, requestBody.UpdateExpression, avMap)
 	if err != nil {
 		return c.Status(http.StatusInternalServerError).SendString("Failed to update movie item: " + err.Error())
 	}
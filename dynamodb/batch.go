@@ -0,0 +1,169 @@
+package dynamodbClient
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+const (
+	batchGetLimit   = 100
+	batchWriteLimit = 25
+	batchRetries    = 5
+)
+
+// use case When many items are read by key at once, e.g. resolving a list of references.
+// Items that are not found are simply missing from result, which must be a pointer to a slice.
+func (c *DynamodbClientImpl) BatchGetItem(ctx context.Context, tableName string, keys []map[string]types.AttributeValue, result any) error {
+	physical, err := c.physicalTable(ctx, tableName)
+	if err != nil {
+		return err
+	}
+
+	scopedKeys := make([]map[string]types.AttributeValue, len(keys))
+	for i, key := range keys {
+		if scopedKeys[i], err = c.scopeItem(ctx, tableName, key); err != nil {
+			return err
+		}
+	}
+	keys = scopedKeys
+
+	var items []map[string]types.AttributeValue
+	for start := 0; start < len(keys); start += batchGetLimit {
+		end := min(start+batchGetLimit, len(keys))
+		request := map[string]types.KeysAndAttributes{
+			physical: {Keys: keys[start:end]},
+		}
+
+		for attempt := 0; len(request) > 0; attempt++ {
+			if attempt > batchRetries {
+				return fmt.Errorf("batch get on `%s` still has unprocessed keys after %d retries", tableName, batchRetries)
+			}
+			if attempt > 0 {
+				if err := sleepBackoff(ctx, attempt); err != nil {
+					return err
+				}
+			}
+
+			output, err := c.serviceClient.BatchGetItem(ctx, &dynamodb.BatchGetItemInput{RequestItems: request})
+			if err != nil {
+				return err
+			}
+			items = append(items, output.Responses[physical]...)
+			request = output.UnprocessedKeys
+		}
+	}
+
+	items = c.withoutExpired(tableName, items)
+	for _, item := range items {
+		if err := c.unscopeItem(ctx, tableName, item); err != nil {
+			return err
+		}
+	}
+
+	return attributevalue.UnmarshalListOfMaps(items, result)
+}
+
+// use case When loading many items at once; writes are sent in chunks of 25 and unprocessed items are retried.
+func (c *DynamodbClientImpl) BatchWriteItem(ctx context.Context, tableName string, items []any) error {
+	physical, err := c.physicalTable(ctx, tableName)
+	if err != nil {
+		return err
+	}
+
+	for start := 0; start < len(items); start += batchWriteLimit {
+		end := min(start+batchWriteLimit, len(items))
+
+		requests := make([]types.WriteRequest, 0, end-start)
+		written := make([]map[string]types.AttributeValue, 0, end-start)
+		for _, item := range items[start:end] {
+			av, err := attributevalue.MarshalMap(item)
+			if err != nil {
+				return err
+			}
+			scoped, err := c.scopeItem(ctx, tableName, av)
+			if err != nil {
+				return err
+			}
+			if err := checkItemSize(tableName, scoped); err != nil {
+				return err
+			}
+			requests = append(requests, types.WriteRequest{PutRequest: &types.PutRequest{Item: scoped}})
+			written = append(written, av)
+		}
+
+		recorded, err := c.batchWithOutbox(ctx, tableName, physical, requests, written)
+		if err != nil {
+			return err
+		}
+		if recorded {
+			for _, av := range written {
+				c.afterWrite(ctx, WriteEvent{TableName: tableName, Op: WriteOpPut, Item: av})
+			}
+			continue
+		}
+
+		request := map[string][]types.WriteRequest{physical: requests}
+		for attempt := 0; len(request) > 0; attempt++ {
+			if attempt > batchRetries {
+				return fmt.Errorf("batch write on `%s` still has unprocessed items after %d retries", tableName, batchRetries)
+			}
+			if attempt > 0 {
+				if err := sleepBackoff(ctx, attempt); err != nil {
+					return err
+				}
+			}
+
+			output, err := c.serviceClient.BatchWriteItem(ctx, &dynamodb.BatchWriteItemInput{RequestItems: request})
+			if err != nil {
+				return err
+			}
+			request = output.UnprocessedItems
+		}
+
+		for _, av := range written {
+			c.afterWrite(ctx, WriteEvent{TableName: tableName, Op: WriteOpPut, Item: av})
+		}
+	}
+	return nil
+}
+
+// batchWithOutbox writes a chunk and its outbox records in one transaction, which a chunk of
+// batchWriteLimit puts and their records fits into. It reports false, having written nothing,
+// when no item of the chunk needs a record.
+func (c *DynamodbClientImpl) batchWithOutbox(ctx context.Context, tableName, physical string, requests []types.WriteRequest, written []map[string]types.AttributeValue) (bool, error) {
+	var records []types.TransactWriteItem
+	for _, av := range written {
+		outboxItem, err := c.outboxItem(ctx, WriteEvent{TableName: tableName, Op: WriteOpPut, Item: av})
+		if err != nil {
+			return false, err
+		}
+		if outboxItem != nil {
+			records = append(records, *outboxItem)
+		}
+	}
+	if len(records) == 0 {
+		return false, nil
+	}
+
+	items := make([]types.TransactWriteItem, 0, len(requests)+len(records))
+	for _, request := range requests {
+		items = append(items, types.TransactWriteItem{Put: &types.Put{TableName: aws.String(physical), Item: request.PutRequest.Item}})
+	}
+	_, err := c.serviceClient.TransactWriteItems(ctx, &dynamodb.TransactWriteItemsInput{TransactItems: append(items, records...)})
+	return true, translateError(err)
+}
+
+func sleepBackoff(ctx context.Context, attempt int) error {
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-time.After(time.Duration(50<<attempt) * time.Millisecond):
+		return nil
+	}
+}
@@ -0,0 +1,85 @@
+package dynamodbClient
+
+import (
+	"context"
+	"errors"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/aws/smithy-go"
+	"github.com/aws/smithy-go/middleware"
+)
+
+// CircuitBreaker fails calls fast with ErrCircuitOpen once Threshold calls in a row
+// have failed, until Cooldown has passed. Then a single trial call decides whether it closes again.
+type CircuitBreaker struct {
+	Threshold int
+	Cooldown  time.Duration
+
+	mu       sync.Mutex
+	failures int
+	openedAt time.Time
+	trial    bool
+}
+
+func (b *CircuitBreaker) allow() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.failures < b.Threshold {
+		return true
+	}
+	if b.trial || time.Since(b.openedAt) < b.Cooldown {
+		return false
+	}
+	b.trial = true
+	return true
+}
+
+func (b *CircuitBreaker) record(err error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.trial = false
+	if !isFailure(err) {
+		b.failures = 0
+		return
+	}
+	b.failures++
+	if b.failures >= b.Threshold {
+		if b.failures == b.Threshold {
+			log.Printf("DynamoDB circuit opened after %d failures: %v\n", b.failures, err)
+		}
+		b.openedAt = time.Now()
+	}
+}
+
+// isFailure reports whether err says something about DynamoDB's health, rather than about the request.
+func isFailure(err error) bool {
+	if err == nil || errors.Is(err, context.Canceled) {
+		return false
+	}
+	var apiErr smithy.APIError
+	if errors.As(err, &apiErr) {
+		return throttlingCodes[apiErr.ErrorCode()] || apiErr.ErrorFault() == smithy.FaultServer
+	}
+	return true
+}
+
+// errorMiddleware runs every operation through the breaker, when there is one, and translateError.
+func errorMiddleware(breaker *CircuitBreaker) func(*middleware.Stack) error {
+	return func(stack *middleware.Stack) error {
+		return stack.Initialize.Add(middleware.InitializeMiddlewareFunc("DytestErrors",
+			func(ctx context.Context, in middleware.InitializeInput, next middleware.InitializeHandler) (middleware.InitializeOutput, middleware.Metadata, error) {
+				if breaker != nil && !breaker.allow() {
+					return middleware.InitializeOutput{}, middleware.Metadata{}, ErrCircuitOpen
+				}
+				out, metadata, err := next.HandleInitialize(ctx, in)
+				if breaker != nil {
+					breaker.record(err)
+				}
+				return out, metadata, translateError(err)
+			}), middleware.Before)
+	}
+}
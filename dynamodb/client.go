@@ -2,66 +2,160 @@ package dynamodbClient
 
 import (
 	"context"
+	appConfig "dytest/config"
 	"fmt"
+	"net/http"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
-	"github.com/aws/aws-sdk-go-v2/config"
+	awshttp "github.com/aws/aws-sdk-go-v2/aws/transport/http"
+	awsConfig "github.com/aws/aws-sdk-go-v2/config"
 	"github.com/aws/aws-sdk-go-v2/credentials"
+	"github.com/aws/aws-sdk-go-v2/credentials/stscreds"
 	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
 	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
 	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/aws/aws-sdk-go-v2/service/sts"
 )
 
 type DynamodbClient interface {
 	TransactGetItem(ctx context.Context, tableName string, key map[string]types.AttributeValue, result any) (*dynamodb.TransactGetItemsOutput, error)
 	Scan(ctx context.Context, tableName string, result any) (*dynamodb.ScanOutput, error)
-	TransactWriteItems(ctx context.Context, tableName string, body any) error
-	DeleteItem(ctx context.Context, tableName string, key map[string]types.AttributeValue) error
-	UpdateItem(ctx context.Context, tableName string, key map[string]types.AttributeValue, updateExpression string, requestBody any) error
+	GetItem(ctx context.Context, tableName string, key map[string]types.AttributeValue, result any) error
+	Query(ctx context.Context, tableName string, query QueryRequest, result any) (string, error)
+	ScanPage(ctx context.Context, tableName string, scan ScanRequest, result any) (string, error)
+	TransactWriteItems(ctx context.Context, tableName string, body any, opts ...WriteOption) error
+	DeleteItem(ctx context.Context, tableName string, key map[string]types.AttributeValue, opts ...WriteOption) error
+	UpdateItem(ctx context.Context, tableName string, key map[string]types.AttributeValue, updateExpression string, requestBody any, opts ...WriteOption) error
+	BatchGetItem(ctx context.Context, tableName string, keys []map[string]types.AttributeValue, result any) error
+	BatchWriteItem(ctx context.Context, tableName string, items []any) error
+	ListTables(ctx context.Context) ([]string, error)
+	DescribeTable(ctx context.Context, tableName string) (*types.TableDescription, error)
+	CreateTable(ctx context.Context, tableName string, input *dynamodb.CreateTableInput) error
+	DeleteTable(ctx context.Context, tableName string) error
+	UpdateTimeToLive(ctx context.Context, tableName string, attributeName string, enabled bool) error
+	DescribeTimeToLive(ctx context.Context, tableName string) (*types.TimeToLiveDescription, error)
+	ExecuteStatement(ctx context.Context, statement string, params []any, result any) error
+	BatchExecuteStatement(ctx context.Context, statements []Statement) ([]StatementResult, error)
+	ExecuteTransaction(ctx context.Context, statements []Statement, result any) error
+	Ping(ctx context.Context) error
+	HealthCheck(ctx context.Context, tableNames ...string) error
 }
 
 type DynamodbClientImpl struct {
 	serviceClient *dynamodb.Client
+	tableNames    TableNameResolver
+	tenantTables  map[string]appConfig.TenantTableConfig
+	ttlAttributes map[string]string
+	filterExpired bool
+	writeHooks    []WriteHook
+	outbox        OutboxFunc
 }
 
-func NewDynamodbClient(ctx context.Context, profile string) (DynamodbClient, error) {
-	cfg, err := config.LoadDefaultConfig(ctx,
-		config.WithRegion("localhost"),
-		config.WithEndpointResolverWithOptions(aws.EndpointResolverWithOptionsFunc(
-			func(service, region string, options ...interface{}) (aws.Endpoint, error) {
-				return aws.Endpoint{URL: "http://localhost:8000"}, nil 
-			})),
-		config.WithCredentialsProvider(credentials.StaticCredentialsProvider{
-			Value: aws.Credentials{
-				AccessKeyID: "", SecretAccessKey: "", SessionToken: "",
-				Source: "",
-			},
-		}), // IAM ROlE Connect append Middleware
-	)
+type Option func(*DynamodbClientImpl)
+
+// WithTenantTables scopes the given logical tables to the tenant found in the request context.
+func WithTenantTables(tables map[string]appConfig.TenantTableConfig) Option {
+	return func(c *DynamodbClientImpl) {
+		c.tenantTables = tables
+	}
+}
+
+func NewDynamodbClient(ctx context.Context, cfg appConfig.DynamoDBConfig, opts ...Option) (DynamodbClient, error) {
+	awsCfg, err := LoadAWSConfig(ctx, cfg)
 	if err != nil {
-		return nil, fmt.Errorf("unable to load SDK config: %v", err)
+		return nil, err
 	}
-	c := dynamodb.NewFromConfig(cfg)
+	var breaker *CircuitBreaker
+	if cfg.BreakerThreshold > 0 {
+		breaker = &CircuitBreaker{Threshold: cfg.BreakerThreshold, Cooldown: cfg.BreakerCooldown}
+	}
+	c := dynamodb.NewFromConfig(awsCfg, func(o *dynamodb.Options) {
+		if cfg.Endpoint != "" {
+			o.BaseEndpoint = aws.String(cfg.Endpoint)
+		}
+		o.APIOptions = append(o.APIOptions, errorMiddleware(breaker))
+	})
 
 	finalDynamodbClient := &DynamodbClientImpl{
 		serviceClient: c,
+		tableNames:    TableNameResolver{Prefix: cfg.TablePrefix, Suffix: cfg.TableSuffix},
+		filterExpired: cfg.FilterExpired,
+	}
+	for _, opt := range opts {
+		opt(finalDynamodbClient)
+	}
+
+	// The connection is lazy: use Ping, HealthCheck or WaitForConnection to verify it.
+	return finalDynamodbClient, nil
+}
+
+// LoadAWSConfig resolves region, credentials and HTTP settings for cfg.
+// Static credentials win over the shared profile, and an assume-role ARN wraps whichever was picked.
+func LoadAWSConfig(ctx context.Context, cfg appConfig.DynamoDBConfig) (aws.Config, error) {
+	httpClient := awshttp.NewBuildableClient().WithTransportOptions(func(tr *http.Transport) {
+		if cfg.MaxConns > 0 {
+			tr.MaxConnsPerHost = cfg.MaxConns
+			tr.MaxIdleConnsPerHost = cfg.MaxConns
+		}
+	})
+	if cfg.HTTPTimeout > 0 {
+		httpClient = httpClient.WithTimeout(cfg.HTTPTimeout)
+	}
+
+	opts := []func(*awsConfig.LoadOptions) error{
+		awsConfig.WithRegion(cfg.Region),
+		awsConfig.WithHTTPClient(httpClient),
+	}
+	if cfg.Profile != "" {
+		opts = append(opts, awsConfig.WithSharedConfigProfile(cfg.Profile))
+	}
+
+	switch {
+	case cfg.AccessKeyID != "":
+		opts = append(opts, awsConfig.WithCredentialsProvider(
+			credentials.NewStaticCredentialsProvider(cfg.AccessKeyID, cfg.SecretAccessKey, cfg.SessionToken)))
+	case cfg.Endpoint != "" && cfg.Profile == "":
+		// dynamodb-local accepts any credentials but the SDK still has to sign the request.
+		opts = append(opts, awsConfig.WithCredentialsProvider(
+			credentials.NewStaticCredentialsProvider("local", "local", "")))
 	}
 
-	//Test connection with DynamoDB using TableList
-	_, err = c.ListTables(ctx, &dynamodb.ListTablesInput{})
+	awsCfg, err := awsConfig.LoadDefaultConfig(ctx, opts...)
 	if err != nil {
-		panic(fmt.Errorf("failed to connect to Dynamodb: %v", err))
+		return aws.Config{}, fmt.Errorf("unable to load SDK config: %v", err)
 	}
-	return finalDynamodbClient, nil
+
+	if cfg.AssumeRoleARN != "" {
+		provider := stscreds.NewAssumeRoleProvider(sts.NewFromConfig(awsCfg), cfg.AssumeRoleARN, func(o *stscreds.AssumeRoleOptions) {
+			if cfg.AssumeRoleSessionName != "" {
+				o.RoleSessionName = cfg.AssumeRoleSessionName
+			}
+			if cfg.AssumeRoleExternalID != "" {
+				o.ExternalID = aws.String(cfg.AssumeRoleExternalID)
+			}
+		})
+		awsCfg.Credentials = aws.NewCredentialsCache(provider)
+	}
+
+	return awsCfg, nil
 }
 
 // use case When atomicity and consistency across multiple items are required.
 func (c *DynamodbClientImpl) TransactGetItem(ctx context.Context, tableName string, key map[string]types.AttributeValue, result any) (*dynamodb.TransactGetItemsOutput, error) {
+	physical, err := c.physicalTable(ctx, tableName)
+	if err != nil {
+		return nil, err
+	}
+	key, err = c.scopeItem(ctx, tableName, key)
+	if err != nil {
+		return nil, err
+	}
+
 	input := &dynamodb.TransactGetItemsInput{ //ReturnConsumedCapacity types.ReturnConsumedCapacity
 		TransactItems: []types.TransactGetItem{
 			{
 				Get: &types.Get{
-					TableName: aws.String(tableName),
+					TableName: aws.String(physical),
 					Key:       key,
 				},
 			},
@@ -73,7 +167,17 @@ func (c *DynamodbClientImpl) TransactGetItem(ctx context.Context, tableName stri
 		return nil, err
 	}
 
-	if err := attributevalue.UnmarshalMap(output.Responses[0].Item, &result); err != nil {
+	item := output.Responses[0].Item
+	if c.expired(tableName, item) {
+		item = nil
+	}
+	if item != nil {
+		if err := c.unscopeItem(ctx, tableName, item); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := attributevalue.UnmarshalMap(item, &result); err != nil {
 		return nil, err
 	}
 
@@ -82,15 +186,30 @@ func (c *DynamodbClientImpl) TransactGetItem(ctx context.Context, tableName stri
 
 // use case When you need to read every item in a table, often for reporting or bulk data operations.
 func (c *DynamodbClientImpl) Scan(ctx context.Context, tableName string, result any) (*dynamodb.ScanOutput, error) {
+	physical, err := c.physicalTable(ctx, tableName)
+	if err != nil {
+		return nil, err
+	}
+
 	input := &dynamodb.ScanInput{
-		TableName: aws.String(tableName),
+		TableName: aws.String(physical),
 	}
+	if err := c.scopeScan(ctx, tableName, input); err != nil {
+		return nil, err
+	}
+	c.filterExpiredScan(tableName, input)
 
 	output, err := c.serviceClient.Scan(ctx, input)
 	if err != nil {
 		return nil, err
 	}
 
+	for _, item := range output.Items {
+		if err := c.unscopeItem(ctx, tableName, item); err != nil {
+			return nil, err
+		}
+	}
+
 	if err := attributevalue.UnmarshalListOfMaps(output.Items, &result); err != nil {
 		return nil, err
 	}
@@ -98,60 +217,181 @@ func (c *DynamodbClientImpl) Scan(ctx context.Context, tableName string, result
 	return output, nil
 }
 
-func (c *DynamodbClientImpl) TransactWriteItems(ctx context.Context, tableName string, body any) error {
+func (c *DynamodbClientImpl) TransactWriteItems(ctx context.Context, tableName string, body any, opts ...WriteOption) error {
+	physical, err := c.physicalTable(ctx, tableName)
+	if err != nil {
+		return err
+	}
+	o, err := applyWriteOptions(opts)
+	if err != nil {
+		return err
+	}
 	av, err := attributevalue.MarshalMap(body)
 	if err != nil {
 		return err
 	}
+	item, err := c.scopeItem(ctx, tableName, av)
+	if err != nil {
+		return err
+	}
+	if err := checkItemSize(tableName, item); err != nil {
+		return err
+	}
 	input := &dynamodb.TransactWriteItemsInput{
 		TransactItems: []types.TransactWriteItem{
 			{
 				Put: &types.Put{
-					TableName: aws.String(tableName),
-					Item:      av,
+					TableName:                 aws.String(physical),
+					Item:                      item,
+					ConditionExpression:       o.conditionExpression(),
+					ExpressionAttributeNames:  o.names,
+					ExpressionAttributeValues: o.mergeValues(nil),
 				},
 			},
 		},
 	}
 
-	_, err = c.serviceClient.TransactWriteItems(ctx, input)
+	event := WriteEvent{TableName: tableName, Op: WriteOpPut, Item: av}
+	outboxItem, err := c.outboxItem(ctx, event)
 	if err != nil {
 		return err
 	}
+	if outboxItem != nil {
+		input.TransactItems = append(input.TransactItems, *outboxItem)
+	}
 
+	_, err = c.serviceClient.TransactWriteItems(ctx, input)
+	if err != nil {
+		return translateError(err)
+	}
+
+	c.afterWrite(ctx, event)
 	return nil
 }
 
-func (c *DynamodbClientImpl) DeleteItem(ctx context.Context, tableName string, key map[string]types.AttributeValue) error {
-	input := &dynamodb.DeleteItemInput{
-		TableName: aws.String(tableName),
-		Key:       key,
+func (c *DynamodbClientImpl) DeleteItem(ctx context.Context, tableName string, key map[string]types.AttributeValue, opts ...WriteOption) error {
+	physical, err := c.physicalTable(ctx, tableName)
+	if err != nil {
+		return err
 	}
-	_, err := c.serviceClient.DeleteItem(ctx, input)
+	o, err := applyWriteOptions(opts)
 	if err != nil {
 		return err
 	}
+	scopedKey, err := c.scopeItem(ctx, tableName, key)
+	if err != nil {
+		return err
+	}
+
+	event := WriteEvent{TableName: tableName, Op: WriteOpDelete, Key: key}
+	outboxItem, err := c.outboxItem(ctx, event)
+	if err != nil {
+		return err
+	}
+
+	if outboxItem != nil {
+		_, err = c.serviceClient.TransactWriteItems(ctx, &dynamodb.TransactWriteItemsInput{
+			TransactItems: []types.TransactWriteItem{
+				{Delete: &types.Delete{
+					TableName:                 aws.String(physical),
+					Key:                       scopedKey,
+					ConditionExpression:       o.conditionExpression(),
+					ExpressionAttributeNames:  o.names,
+					ExpressionAttributeValues: o.mergeValues(nil),
+				}},
+				*outboxItem,
+			},
+		})
+	} else {
+		input := &dynamodb.DeleteItemInput{
+			TableName:                 aws.String(physical),
+			Key:                       scopedKey,
+			ConditionExpression:       o.conditionExpression(),
+			ExpressionAttributeNames:  o.names,
+			ExpressionAttributeValues: o.mergeValues(nil),
+		}
+		_, err = c.serviceClient.DeleteItem(ctx, input)
+	}
+	if err != nil {
+		return translateError(err)
+	}
+
+	c.afterWrite(ctx, event)
 	return nil
 }
 
-func (c *DynamodbClientImpl) UpdateItem(ctx context.Context, tableName string, key map[string]types.AttributeValue, updateExpression string, requestBody any) error {
+func (c *DynamodbClientImpl) UpdateItem(ctx context.Context, tableName string, key map[string]types.AttributeValue, updateExpression string, requestBody any, opts ...WriteOption) error {
+	physical, err := c.physicalTable(ctx, tableName)
+	if err != nil {
+		return err
+	}
+	o, err := applyWriteOptions(opts)
+	if err != nil {
+		return err
+	}
+	scopedKey, err := c.scopeItem(ctx, tableName, key)
+	if err != nil {
+		return err
+	}
+
 	expressionAttributeValues, err := attributevalue.MarshalMap(requestBody)
 	if err != nil {
 		return err
 	}
 
+	event := WriteEvent{TableName: tableName, Op: WriteOpUpdate, Key: key, UpdateExpression: updateExpression, Values: expressionAttributeValues}
+	outboxItem, err := c.outboxItem(ctx, event)
+	if err != nil {
+		return err
+	}
+
+	values := o.mergeValues(expressionAttributeValues)
+	if len(values) == 0 {
+		// DynamoDB rejects an empty map, e.g. for REMOVE-only updates.
+		values = nil
+	}
+
+	if outboxItem != nil {
+		// Transactions return no item, so the event only carries the key.
+		_, err = c.serviceClient.TransactWriteItems(ctx, &dynamodb.TransactWriteItemsInput{
+			TransactItems: []types.TransactWriteItem{
+				{Update: &types.Update{
+					TableName:                 aws.String(physical),
+					Key:                       scopedKey,
+					UpdateExpression:          aws.String(updateExpression),
+					ConditionExpression:       o.conditionExpression(),
+					ExpressionAttributeNames:  o.names,
+					ExpressionAttributeValues: values,
+				}},
+				*outboxItem,
+			},
+		})
+		if err != nil {
+			return translateError(err)
+		}
+		c.afterWrite(ctx, event)
+		return nil
+	}
+
 	input := &dynamodb.UpdateItemInput{
-		TableName:                 aws.String(tableName),
-		Key:                       key,
+		TableName:                 aws.String(physical),
+		Key:                       scopedKey,
 		UpdateExpression:          aws.String(updateExpression),
-		ExpressionAttributeValues: expressionAttributeValues,
-		ReturnValues:              types.ReturnValueUpdatedNew,
+		ConditionExpression:       o.conditionExpression(),
+		ExpressionAttributeNames:  o.names,
+		ExpressionAttributeValues: values,
+		ReturnValues:              types.ReturnValueAllNew,
 	}
 
-	_, err = c.serviceClient.UpdateItem(ctx, input)
+	output, err := c.serviceClient.UpdateItem(ctx, input)
 	if err != nil {
-		return err
+		return translateError(err)
 	}
 
+	if err := c.unscopeItem(ctx, tableName, output.Attributes); err != nil {
+		return err
+	}
+	event.Item = output.Attributes
+	c.afterWrite(ctx, event)
 	return nil
 }
@@ -0,0 +1,72 @@
+package dynamodbClient
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/aws/smithy-go"
+)
+
+var (
+	ErrNotFound        = errors.New("item not found")
+	ErrConditionFailed = errors.New("condition check failed")
+	ErrInvalidCursor   = errors.New("invalid cursor")
+	ErrThrottled       = errors.New("request throttled")
+	ErrValidation      = errors.New("invalid request")
+	ErrCircuitOpen     = errors.New("circuit open")
+)
+
+var throttlingCodes = map[string]bool{
+	"ProvisionedThroughputExceededException": true,
+	"RequestLimitExceeded":                   true,
+	"ThrottlingException":                    true,
+}
+
+// translateError wraps SDK errors in the sentinels above so callers can use errors.Is.
+// The SDK error stays in the chain for errors.As.
+func translateError(err error) error {
+	if err == nil || isTranslated(err) {
+		return err
+	}
+
+	var conditionFailed *types.ConditionalCheckFailedException
+	if errors.As(err, &conditionFailed) {
+		return fmt.Errorf("%w: %w", ErrConditionFailed, err)
+	}
+
+	var canceled *types.TransactionCanceledException
+	if errors.As(err, &canceled) {
+		for _, reason := range canceled.CancellationReasons {
+			switch aws.ToString(reason.Code) {
+			case "ConditionalCheckFailed":
+				return fmt.Errorf("%w: %w", ErrConditionFailed, err)
+			case "ThrottlingError", "ProvisionedThroughputExceeded":
+				return fmt.Errorf("%w: %w", ErrThrottled, err)
+			case "ValidationError":
+				return fmt.Errorf("%w: %w", ErrValidation, err)
+			}
+		}
+	}
+
+	var apiErr smithy.APIError
+	if errors.As(err, &apiErr) {
+		switch {
+		case throttlingCodes[apiErr.ErrorCode()]:
+			return fmt.Errorf("%w: %w", ErrThrottled, err)
+		case apiErr.ErrorCode() == "ValidationException":
+			return fmt.Errorf("%w: %w", ErrValidation, err)
+		}
+	}
+	return err
+}
+
+func isTranslated(err error) bool {
+	for _, sentinel := range []error{ErrNotFound, ErrConditionFailed, ErrInvalidCursor, ErrThrottled, ErrValidation, ErrCircuitOpen} {
+		if errors.Is(err, sentinel) {
+			return true
+		}
+	}
+	return false
+}
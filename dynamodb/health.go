@@ -0,0 +1,77 @@
+package dynamodbClient
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+type Backoff struct {
+	Attempts int
+	Initial  time.Duration
+	Max      time.Duration
+}
+
+var DefaultBackoff = Backoff{
+	Attempts: 10,
+	Initial:  500 * time.Millisecond,
+	Max:      10 * time.Second,
+}
+
+// Ping checks that the DynamoDB endpoint is reachable.
+func (c *DynamodbClientImpl) Ping(ctx context.Context) error {
+	_, err := c.serviceClient.ListTables(ctx, &dynamodb.ListTablesInput{Limit: aws.Int32(1)})
+	if err != nil {
+		return fmt.Errorf("failed to connect to Dynamodb: %v", err)
+	}
+	return nil
+}
+
+// HealthCheck verifies connectivity and that every given table exists and is ACTIVE.
+func (c *DynamodbClientImpl) HealthCheck(ctx context.Context, tableNames ...string) error {
+	if err := c.Ping(ctx); err != nil {
+		return err
+	}
+
+	for _, tableName := range tableNames {
+		table, err := c.DescribeTable(ctx, tableName)
+		if err != nil {
+			return fmt.Errorf("failed to describe table `%s`: %v", tableName, err)
+		}
+		if table.TableStatus != types.TableStatusActive {
+			return fmt.Errorf("table `%s` is %s", tableName, table.TableStatus)
+		}
+	}
+	return nil
+}
+
+// WaitForConnection pings the client with exponential backoff until it answers,
+// the attempts are used up or ctx is done.
+func WaitForConnection(ctx context.Context, client DynamodbClient, backoff Backoff) error {
+	delay := backoff.Initial
+	var err error
+	for attempt := 1; attempt <= backoff.Attempts; attempt++ {
+		if err = client.Ping(ctx); err == nil {
+			return nil
+		}
+		if attempt == backoff.Attempts {
+			break
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(delay):
+		}
+
+		delay *= 2
+		if delay > backoff.Max {
+			delay = backoff.Max
+		}
+	}
+	return fmt.Errorf("giving up after %d attempts: %v", backoff.Attempts, err)
+}
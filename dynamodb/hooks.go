@@ -0,0 +1,39 @@
+package dynamodbClient
+
+import (
+	"context"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+const (
+	WriteOpPut    = "put"
+	WriteOpUpdate = "update"
+	WriteOpDelete = "delete"
+)
+
+// WriteEvent describes a successful write with logical table name and unscoped attributes.
+// Item is the written item for puts and the updated item for updates; Key is set for updates and deletes.
+type WriteEvent struct {
+	TableName        string
+	Op               string
+	Key              map[string]types.AttributeValue
+	Item             map[string]types.AttributeValue
+	UpdateExpression string
+	Values           map[string]types.AttributeValue
+}
+
+// WriteHook runs synchronously after every successful write and must not block.
+type WriteHook func(ctx context.Context, event WriteEvent)
+
+func WithWriteHook(hook WriteHook) Option {
+	return func(c *DynamodbClientImpl) {
+		c.writeHooks = append(c.writeHooks, hook)
+	}
+}
+
+func (c *DynamodbClientImpl) afterWrite(ctx context.Context, event WriteEvent) {
+	for _, hook := range c.writeHooks {
+		hook(ctx, event)
+	}
+}
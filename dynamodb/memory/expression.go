@@ -0,0 +1,900 @@
+package memory
+
+import (
+	"bytes"
+	"fmt"
+	"math/big"
+	"reflect"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+// item is one stored item; a nil attribute value means the attribute is missing.
+type item = map[string]types.AttributeValue
+
+// expression holds the placeholders shared by an expression's parts.
+type expression struct {
+	names  map[string]string
+	values map[string]types.AttributeValue
+	tokens []string
+	pos    int
+}
+
+func parseExpression(text string, names map[string]string, values map[string]types.AttributeValue) (*expression, error) {
+	tokens, err := tokenize(text)
+	if err != nil {
+		return nil, err
+	}
+	return &expression{names: names, values: values, tokens: tokens}, nil
+}
+
+func tokenize(text string) ([]string, error) {
+	var tokens []string
+	for i := 0; i < len(text); {
+		c := rune(text[i])
+		switch {
+		case unicode.IsSpace(c):
+			i++
+		case strings.ContainsRune("(),.[]+-=", c):
+			tokens = append(tokens, string(c))
+			i++
+		case c == '<' || c == '>':
+			if i+1 < len(text) && (text[i+1] == '=' || (c == '<' && text[i+1] == '>')) {
+				tokens = append(tokens, text[i:i+2])
+				i += 2
+			} else {
+				tokens = append(tokens, string(c))
+				i++
+			}
+		case c == '#' || c == ':' || c == '_' || unicode.IsLetter(c) || unicode.IsDigit(c):
+			j := i + 1
+			for j < len(text) && (text[j] == '_' || unicode.IsLetter(rune(text[j])) || unicode.IsDigit(rune(text[j]))) {
+				j++
+			}
+			tokens = append(tokens, text[i:j])
+			i = j
+		default:
+			return nil, fmt.Errorf("unexpected %q in expression", c)
+		}
+	}
+	return tokens, nil
+}
+
+func (e *expression) peek() string {
+	if e.pos < len(e.tokens) {
+		return e.tokens[e.pos]
+	}
+	return ""
+}
+
+func (e *expression) keyword(word string) bool {
+	if strings.EqualFold(e.peek(), word) {
+		e.pos++
+		return true
+	}
+	return false
+}
+
+func (e *expression) expect(token string) error {
+	if e.peek() != token {
+		return fmt.Errorf("expected %q, found %q", token, e.peek())
+	}
+	e.pos++
+	return nil
+}
+
+func (e *expression) done() error {
+	if e.pos < len(e.tokens) {
+		return fmt.Errorf("unexpected %q", e.peek())
+	}
+	return nil
+}
+
+// Conditions.
+
+type condition func(it item) bool
+
+func (e *expression) condition() (condition, error) {
+	left, err := e.and()
+	if err != nil {
+		return nil, err
+	}
+	for e.keyword("OR") {
+		right, err := e.and()
+		if err != nil {
+			return nil, err
+		}
+		l := left
+		left = func(it item) bool { return l(it) || right(it) }
+	}
+	return left, nil
+}
+
+func (e *expression) and() (condition, error) {
+	left, err := e.not()
+	if err != nil {
+		return nil, err
+	}
+	for e.keyword("AND") {
+		right, err := e.not()
+		if err != nil {
+			return nil, err
+		}
+		l := left
+		left = func(it item) bool { return l(it) && right(it) }
+	}
+	return left, nil
+}
+
+func (e *expression) not() (condition, error) {
+	if e.keyword("NOT") {
+		inner, err := e.not()
+		if err != nil {
+			return nil, err
+		}
+		return func(it item) bool { return !inner(it) }, nil
+	}
+	return e.primary()
+}
+
+func (e *expression) primary() (condition, error) {
+	if e.peek() == "(" {
+		e.pos++
+		inner, err := e.condition()
+		if err != nil {
+			return nil, err
+		}
+		return inner, e.expect(")")
+	}
+
+	switch name := strings.ToLower(e.peek()); name {
+	case "attribute_exists", "attribute_not_exists", "begins_with", "contains", "attribute_type":
+		e.pos++
+		if err := e.expect("("); err != nil {
+			return nil, err
+		}
+		p, err := e.path()
+		if err != nil {
+			return nil, err
+		}
+		var arg operand
+		if name != "attribute_exists" && name != "attribute_not_exists" {
+			if err := e.expect(","); err != nil {
+				return nil, err
+			}
+			if arg, err = e.operand(); err != nil {
+				return nil, err
+			}
+		}
+		if err := e.expect(")"); err != nil {
+			return nil, err
+		}
+		return function(name, p, arg), nil
+	}
+
+	left, err := e.operand()
+	if err != nil {
+		return nil, err
+	}
+	switch op := e.peek(); {
+	case op == "=" || op == "<>" || op == "<" || op == "<=" || op == ">" || op == ">=":
+		e.pos++
+		right, err := e.operand()
+		if err != nil {
+			return nil, err
+		}
+		return func(it item) bool { return compareOp(op, left(it), right(it)) }, nil
+	case strings.EqualFold(op, "BETWEEN"):
+		e.pos++
+		low, err := e.operand()
+		if err != nil {
+			return nil, err
+		}
+		if !e.keyword("AND") {
+			return nil, fmt.Errorf("expected AND in BETWEEN")
+		}
+		high, err := e.operand()
+		if err != nil {
+			return nil, err
+		}
+		return func(it item) bool {
+			v := left(it)
+			return compareOp(">=", v, low(it)) && compareOp("<=", v, high(it))
+		}, nil
+	case strings.EqualFold(op, "IN"):
+		e.pos++
+		if err := e.expect("("); err != nil {
+			return nil, err
+		}
+		var options []operand
+		for {
+			option, err := e.operand()
+			if err != nil {
+				return nil, err
+			}
+			options = append(options, option)
+			if e.peek() != "," {
+				break
+			}
+			e.pos++
+		}
+		if err := e.expect(")"); err != nil {
+			return nil, err
+		}
+		return func(it item) bool {
+			v := left(it)
+			for _, option := range options {
+				if compareOp("=", v, option(it)) {
+					return true
+				}
+			}
+			return false
+		}, nil
+	}
+	return nil, fmt.Errorf("expected a comparison, found %q", e.peek())
+}
+
+func function(name string, p path, arg operand) condition {
+	return func(it item) bool {
+		v := p.get(it)
+		switch name {
+		case "attribute_exists":
+			return v != nil
+		case "attribute_not_exists":
+			return v == nil
+		case "begins_with":
+			switch prefix := arg(it).(type) {
+			case *types.AttributeValueMemberS:
+				s, ok := v.(*types.AttributeValueMemberS)
+				return ok && strings.HasPrefix(s.Value, prefix.Value)
+			case *types.AttributeValueMemberB:
+				b, ok := v.(*types.AttributeValueMemberB)
+				return ok && bytes.HasPrefix(b.Value, prefix.Value)
+			}
+			return false
+		case "contains":
+			want := arg(it)
+			switch value := v.(type) {
+			case *types.AttributeValueMemberS:
+				s, ok := want.(*types.AttributeValueMemberS)
+				return ok && strings.Contains(value.Value, s.Value)
+			case *types.AttributeValueMemberL:
+				for _, element := range value.Value {
+					if equal(element, want) {
+						return true
+					}
+				}
+			case *types.AttributeValueMemberSS:
+				s, ok := want.(*types.AttributeValueMemberS)
+				return ok && containsString(value.Value, s.Value)
+			case *types.AttributeValueMemberNS:
+				n, ok := want.(*types.AttributeValueMemberN)
+				if ok {
+					for _, element := range value.Value {
+						if equal(&types.AttributeValueMemberN{Value: element}, n) {
+							return true
+						}
+					}
+				}
+			}
+			return false
+		case "attribute_type":
+			s, ok := arg(it).(*types.AttributeValueMemberS)
+			return ok && v != nil && typeName(v) == s.Value
+		}
+		return false
+	}
+}
+
+// Operands.
+
+type operand func(it item) types.AttributeValue
+
+func (e *expression) operand() (operand, error) {
+	token := e.peek()
+	switch {
+	case strings.HasPrefix(token, ":"):
+		e.pos++
+		v, ok := e.values[token]
+		if !ok {
+			return nil, fmt.Errorf("value %s is not defined", token)
+		}
+		return func(item) types.AttributeValue { return v }, nil
+	case strings.EqualFold(token, "size"):
+		e.pos++
+		if err := e.expect("("); err != nil {
+			return nil, err
+		}
+		p, err := e.path()
+		if err != nil {
+			return nil, err
+		}
+		if err := e.expect(")"); err != nil {
+			return nil, err
+		}
+		return func(it item) types.AttributeValue {
+			if n, ok := size(p.get(it)); ok {
+				return &types.AttributeValueMemberN{Value: strconv.Itoa(n)}
+			}
+			return nil
+		}, nil
+	}
+	p, err := e.path()
+	if err != nil {
+		return nil, err
+	}
+	return p.get, nil
+}
+
+// updateValue is the right-hand side of a SET action.
+func (e *expression) updateValue() (func(it item) (types.AttributeValue, error), error) {
+	left, err := e.updateOperand()
+	if err != nil {
+		return nil, err
+	}
+	op := e.peek()
+	if op != "+" && op != "-" {
+		return left, nil
+	}
+	e.pos++
+	right, err := e.updateOperand()
+	if err != nil {
+		return nil, err
+	}
+	return func(it item) (types.AttributeValue, error) {
+		a, err := left(it)
+		if err != nil {
+			return nil, err
+		}
+		b, err := right(it)
+		if err != nil {
+			return nil, err
+		}
+		return arithmetic(op, a, b)
+	}, nil
+}
+
+func (e *expression) updateOperand() (func(it item) (types.AttributeValue, error), error) {
+	switch name := strings.ToLower(e.peek()); name {
+	case "if_not_exists", "list_append":
+		e.pos++
+		if err := e.expect("("); err != nil {
+			return nil, err
+		}
+		first, err := e.updateOperand()
+		if err != nil {
+			return nil, err
+		}
+		if err := e.expect(","); err != nil {
+			return nil, err
+		}
+		second, err := e.updateOperand()
+		if err != nil {
+			return nil, err
+		}
+		if err := e.expect(")"); err != nil {
+			return nil, err
+		}
+		return func(it item) (types.AttributeValue, error) {
+			a, err := first(it)
+			if err != nil {
+				return nil, err
+			}
+			if name == "if_not_exists" && a != nil {
+				return a, nil
+			}
+			b, err := second(it)
+			if err != nil || name == "if_not_exists" {
+				return b, err
+			}
+			la, okA := a.(*types.AttributeValueMemberL)
+			lb, okB := b.(*types.AttributeValueMemberL)
+			if !okA || !okB {
+				return nil, fmt.Errorf("list_append needs two lists")
+			}
+			return &types.AttributeValueMemberL{Value: append(append([]types.AttributeValue{}, la.Value...), lb.Value...)}, nil
+		}, nil
+	}
+
+	get, err := e.operand()
+	if err != nil {
+		return nil, err
+	}
+	return func(it item) (types.AttributeValue, error) { return get(it), nil }, nil
+}
+
+// update parses SET, REMOVE, ADD and DELETE clauses into a function that applies them.
+func (e *expression) update() (func(it item) error, error) {
+	var actions []func(it item) error
+	for e.peek() != "" {
+		clause := strings.ToUpper(e.peek())
+		e.pos++
+		for {
+			p, err := e.path()
+			if err != nil {
+				return nil, err
+			}
+			action, err := e.action(clause, p)
+			if err != nil {
+				return nil, err
+			}
+			actions = append(actions, action)
+			if e.peek() != "," {
+				break
+			}
+			e.pos++
+		}
+	}
+	return func(it item) error {
+		for _, action := range actions {
+			if err := action(it); err != nil {
+				return err
+			}
+		}
+		return nil
+	}, nil
+}
+
+func (e *expression) action(clause string, p path) (func(it item) error, error) {
+	switch clause {
+	case "SET":
+		if err := e.expect("="); err != nil {
+			return nil, err
+		}
+		value, err := e.updateValue()
+		if err != nil {
+			return nil, err
+		}
+		return func(it item) error {
+			v, err := value(it)
+			if err != nil {
+				return err
+			}
+			if v == nil {
+				return fmt.Errorf("the value of %s refers to a missing attribute", p)
+			}
+			return p.set(it, clone(v))
+		}, nil
+	case "REMOVE":
+		return func(it item) error {
+			p.remove(it)
+			return nil
+		}, nil
+	case "ADD", "DELETE":
+		value, err := e.operand()
+		if err != nil {
+			return nil, err
+		}
+		return func(it item) error {
+			v, err := addOrDelete(clause, p.get(it), value(it))
+			if err != nil {
+				return err
+			}
+			if v == nil {
+				p.remove(it)
+				return nil
+			}
+			return p.set(it, v)
+		}, nil
+	}
+	return nil, fmt.Errorf("unknown update clause %q", clause)
+}
+
+// addOrDelete adds a number or set elements, or deletes set elements.
+func addOrDelete(clause string, current, v types.AttributeValue) (types.AttributeValue, error) {
+	if n, ok := v.(*types.AttributeValueMemberN); ok && clause == "ADD" {
+		if current == nil {
+			return n, nil
+		}
+		return arithmetic("+", current, n)
+	}
+
+	var have, change []string
+	switch set := v.(type) {
+	case *types.AttributeValueMemberSS:
+		change = set.Value
+		if c, ok := current.(*types.AttributeValueMemberSS); ok {
+			have = c.Value
+		} else if current != nil {
+			return nil, fmt.Errorf("%s needs an attribute of the same set type", clause)
+		}
+	case *types.AttributeValueMemberNS:
+		change = set.Value
+		if c, ok := current.(*types.AttributeValueMemberNS); ok {
+			have = c.Value
+		} else if current != nil {
+			return nil, fmt.Errorf("%s needs an attribute of the same set type", clause)
+		}
+	default:
+		return nil, fmt.Errorf("%s needs a number or a set", clause)
+	}
+
+	var result []string
+	if clause == "ADD" {
+		result = append(result, have...)
+		for _, element := range change {
+			if !containsString(result, element) {
+				result = append(result, element)
+			}
+		}
+	} else {
+		for _, element := range have {
+			if !containsString(change, element) {
+				result = append(result, element)
+			}
+		}
+	}
+	if len(result) == 0 {
+		return nil, nil
+	}
+	if _, ok := v.(*types.AttributeValueMemberNS); ok {
+		return &types.AttributeValueMemberNS{Value: result}, nil
+	}
+	return &types.AttributeValueMemberSS{Value: result}, nil
+}
+
+// projection parses a comma-separated list of paths.
+func (e *expression) projection() ([]path, error) {
+	var paths []path
+	for {
+		p, err := e.path()
+		if err != nil {
+			return nil, err
+		}
+		paths = append(paths, p)
+		if e.peek() != "," {
+			return paths, e.done()
+		}
+		e.pos++
+	}
+}
+
+// project keeps only the given paths of it. A path into a list keeps the whole list.
+func project(it item, paths []path) item {
+	projected := item{}
+	for _, p := range paths {
+		projectPath(projected, it, p)
+	}
+	return projected
+}
+
+func projectPath(dst, src item, p path) {
+	v, ok := src[p[0].name]
+	if !ok {
+		return
+	}
+	if len(p) == 1 || p[1].index >= 0 {
+		dst[p[0].name] = clone(v)
+		return
+	}
+	m, ok := v.(*types.AttributeValueMemberM)
+	if !ok {
+		return
+	}
+	child, ok := dst[p[0].name].(*types.AttributeValueMemberM)
+	if !ok {
+		child = &types.AttributeValueMemberM{Value: item{}}
+		dst[p[0].name] = child
+	}
+	projectPath(child.Value, m.Value, p[1:])
+}
+
+// clone copies a value, so stored items never share maps or lists with callers.
+func clone(v types.AttributeValue) types.AttributeValue {
+	switch value := v.(type) {
+	case *types.AttributeValueMemberM:
+		return &types.AttributeValueMemberM{Value: cloneItem(value.Value)}
+	case *types.AttributeValueMemberL:
+		list := make([]types.AttributeValue, len(value.Value))
+		for i, element := range value.Value {
+			list[i] = clone(element)
+		}
+		return &types.AttributeValueMemberL{Value: list}
+	case *types.AttributeValueMemberSS:
+		return &types.AttributeValueMemberSS{Value: append([]string{}, value.Value...)}
+	case *types.AttributeValueMemberNS:
+		return &types.AttributeValueMemberNS{Value: append([]string{}, value.Value...)}
+	case *types.AttributeValueMemberB:
+		return &types.AttributeValueMemberB{Value: append([]byte{}, value.Value...)}
+	}
+	return v
+}
+
+func cloneItem(it item) item {
+	copied := make(item, len(it))
+	for key, value := range it {
+		copied[key] = clone(value)
+	}
+	return copied
+}
+
+// Paths.
+
+type pathElement struct {
+	name  string
+	index int // -1 for a map key
+}
+
+type path []pathElement
+
+func (e *expression) path() (path, error) {
+	var p path
+	for {
+		token := e.peek()
+		name := token
+		if strings.HasPrefix(token, "#") {
+			var ok bool
+			if name, ok = e.names[token]; !ok {
+				return nil, fmt.Errorf("name %s is not defined", token)
+			}
+		} else if token == "" || strings.HasPrefix(token, ":") || !isIdentifier(token) {
+			return nil, fmt.Errorf("expected an attribute, found %q", token)
+		}
+		e.pos++
+		p = append(p, pathElement{name: name, index: -1})
+
+		for e.peek() == "[" {
+			e.pos++
+			index, err := strconv.Atoi(e.peek())
+			if err != nil {
+				return nil, fmt.Errorf("invalid list index %q", e.peek())
+			}
+			e.pos++
+			if err := e.expect("]"); err != nil {
+				return nil, err
+			}
+			p = append(p, pathElement{index: index})
+		}
+		if e.peek() != "." {
+			return p, nil
+		}
+		e.pos++
+	}
+}
+
+func isIdentifier(token string) bool {
+	r := rune(token[0])
+	return r == '_' || unicode.IsLetter(r)
+}
+
+func (p path) String() string {
+	var b strings.Builder
+	for i, element := range p {
+		if element.index >= 0 {
+			fmt.Fprintf(&b, "[%d]", element.index)
+			continue
+		}
+		if i > 0 {
+			b.WriteByte('.')
+		}
+		b.WriteString(element.name)
+	}
+	return b.String()
+}
+
+func (p path) get(it item) types.AttributeValue {
+	var v types.AttributeValue = &types.AttributeValueMemberM{Value: it}
+	for _, element := range p {
+		switch container := v.(type) {
+		case *types.AttributeValueMemberM:
+			if element.index >= 0 {
+				return nil
+			}
+			v = container.Value[element.name]
+		case *types.AttributeValueMemberL:
+			if element.index < 0 || element.index >= len(container.Value) {
+				return nil
+			}
+			v = container.Value[element.index]
+		default:
+			return nil
+		}
+		if v == nil {
+			return nil
+		}
+	}
+	return v
+}
+
+// set writes v at p. The parent of p must exist, as in DynamoDB; list indexes past the end append.
+func (p path) set(it item, v types.AttributeValue) error {
+	last := p[len(p)-1]
+	switch container := p[:len(p)-1].get(it).(type) {
+	case *types.AttributeValueMemberM:
+		if last.index >= 0 {
+			break
+		}
+		container.Value[last.name] = v
+		return nil
+	case *types.AttributeValueMemberL:
+		if last.index < 0 {
+			break
+		}
+		if last.index >= len(container.Value) {
+			container.Value = append(container.Value, v)
+		} else {
+			container.Value[last.index] = v
+		}
+		return nil
+	}
+	return fmt.Errorf("the document path %s is invalid for update", p)
+}
+
+func (p path) remove(it item) {
+	last := p[len(p)-1]
+	switch container := p[:len(p)-1].get(it).(type) {
+	case *types.AttributeValueMemberM:
+		delete(container.Value, last.name)
+	case *types.AttributeValueMemberL:
+		if last.index >= 0 && last.index < len(container.Value) {
+			container.Value = append(container.Value[:last.index], container.Value[last.index+1:]...)
+		}
+	}
+}
+
+// Values.
+
+func compareOp(op string, a, b types.AttributeValue) bool {
+	if a == nil || b == nil {
+		return op == "<>" && (a == nil) != (b == nil)
+	}
+	switch op {
+	case "=":
+		return equal(a, b)
+	case "<>":
+		return !equal(a, b)
+	}
+	c, ok := compare(a, b)
+	if !ok {
+		return false
+	}
+	switch op {
+	case "<":
+		return c < 0
+	case "<=":
+		return c <= 0
+	case ">":
+		return c > 0
+	case ">=":
+		return c >= 0
+	}
+	return false
+}
+
+// compare orders two scalars of the same type.
+func compare(a, b types.AttributeValue) (int, bool) {
+	switch x := a.(type) {
+	case *types.AttributeValueMemberN:
+		y, ok := b.(*types.AttributeValueMemberN)
+		if !ok {
+			return 0, false
+		}
+		fx, okX := new(big.Float).SetString(x.Value)
+		fy, okY := new(big.Float).SetString(y.Value)
+		if !okX || !okY {
+			return 0, false
+		}
+		return fx.Cmp(fy), true
+	case *types.AttributeValueMemberS:
+		y, ok := b.(*types.AttributeValueMemberS)
+		if !ok {
+			return 0, false
+		}
+		return strings.Compare(x.Value, y.Value), true
+	case *types.AttributeValueMemberB:
+		y, ok := b.(*types.AttributeValueMemberB)
+		if !ok {
+			return 0, false
+		}
+		return bytes.Compare(x.Value, y.Value), true
+	}
+	return 0, false
+}
+
+func equal(a, b types.AttributeValue) bool {
+	if c, ok := compare(a, b); ok {
+		return c == 0
+	}
+	switch x := a.(type) {
+	case *types.AttributeValueMemberL:
+		y, ok := b.(*types.AttributeValueMemberL)
+		if !ok || len(x.Value) != len(y.Value) {
+			return false
+		}
+		for i := range x.Value {
+			if !equal(x.Value[i], y.Value[i]) {
+				return false
+			}
+		}
+		return true
+	case *types.AttributeValueMemberM:
+		y, ok := b.(*types.AttributeValueMemberM)
+		if !ok || len(x.Value) != len(y.Value) {
+			return false
+		}
+		for key, value := range x.Value {
+			if other, ok := y.Value[key]; !ok || !equal(value, other) {
+				return false
+			}
+		}
+		return true
+	}
+	return reflect.DeepEqual(a, b)
+}
+
+func arithmetic(op string, a, b types.AttributeValue) (types.AttributeValue, error) {
+	x, okA := a.(*types.AttributeValueMemberN)
+	y, okB := b.(*types.AttributeValueMemberN)
+	if !okA || !okB {
+		return nil, fmt.Errorf("an operand of %s is not a number", op)
+	}
+	fx, _ := new(big.Float).SetString(x.Value)
+	fy, _ := new(big.Float).SetString(y.Value)
+	if fx == nil || fy == nil {
+		return nil, fmt.Errorf("an operand of %s is not a number", op)
+	}
+	if op == "+" {
+		fx.Add(fx, fy)
+	} else {
+		fx.Sub(fx, fy)
+	}
+	return &types.AttributeValueMemberN{Value: fx.Text('f', -1)}, nil
+}
+
+func size(v types.AttributeValue) (int, bool) {
+	switch value := v.(type) {
+	case *types.AttributeValueMemberS:
+		return len(value.Value), true
+	case *types.AttributeValueMemberB:
+		return len(value.Value), true
+	case *types.AttributeValueMemberL:
+		return len(value.Value), true
+	case *types.AttributeValueMemberM:
+		return len(value.Value), true
+	case *types.AttributeValueMemberSS:
+		return len(value.Value), true
+	case *types.AttributeValueMemberNS:
+		return len(value.Value), true
+	case *types.AttributeValueMemberBS:
+		return len(value.Value), true
+	}
+	return 0, false
+}
+
+func typeName(v types.AttributeValue) string {
+	switch v.(type) {
+	case *types.AttributeValueMemberS:
+		return "S"
+	case *types.AttributeValueMemberN:
+		return "N"
+	case *types.AttributeValueMemberB:
+		return "B"
+	case *types.AttributeValueMemberBOOL:
+		return "BOOL"
+	case *types.AttributeValueMemberNULL:
+		return "NULL"
+	case *types.AttributeValueMemberL:
+		return "L"
+	case *types.AttributeValueMemberM:
+		return "M"
+	case *types.AttributeValueMemberSS:
+		return "SS"
+	case *types.AttributeValueMemberNS:
+		return "NS"
+	case *types.AttributeValueMemberBS:
+		return "BS"
+	}
+	return ""
+}
+
+func containsString(values []string, s string) bool {
+	for _, v := range values {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
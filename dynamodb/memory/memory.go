@@ -0,0 +1,675 @@
+// Package memory is an in-memory DynamodbClient for tests, demos and the dytest shell. It keeps
+// tables in maps and evaluates key conditions, filters, projections, conditions and update
+// expressions itself. Scans return items in key order, tenants and TTLs are ignored, and PartiQL
+// is not supported.
+package memory
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	appConfig "dytest/config"
+	dynamodbClient "dytest/dynamodb"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+var errPartiQL = fmt.Errorf("%w: PartiQL is not supported by the in-memory client", dynamodbClient.ErrValidation)
+
+type Client struct {
+	mu     sync.Mutex
+	tables map[string]*table
+	// tenantTables puts a tenant's items in its own table in table mode; prefix mode is ignored.
+	tenantTables map[string]appConfig.TenantTableConfig
+}
+
+type Option func(*Client)
+
+// WithTenantTables is dynamodbClient.WithTenantTables for the in-memory client.
+func WithTenantTables(tables map[string]appConfig.TenantTableConfig) Option {
+	return func(c *Client) {
+		c.tenantTables = tables
+	}
+}
+
+type table struct {
+	description types.TableDescription
+	ttl         types.TimeToLiveDescription
+	// items are keyed by their primary key, see key.
+	items map[string]item
+}
+
+func New(opts ...Option) *Client {
+	c := &Client{tables: map[string]*table{}}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+var _ dynamodbClient.DynamodbClient = (*Client)(nil)
+
+func (c *Client) table(ctx context.Context, tableName string) (*table, error) {
+	tableName, err := dynamodbClient.TenantTableName(ctx, c.tenantTables, tableName)
+	if err != nil {
+		return nil, err
+	}
+	t, ok := c.tables[tableName]
+	if !ok {
+		return nil, &types.ResourceNotFoundException{Message: aws.String("Requested resource not found: Table: " + tableName + " not found")}
+	}
+	return t, nil
+}
+
+func invalid(format string, args ...any) error {
+	return fmt.Errorf("%w: %s", dynamodbClient.ErrValidation, fmt.Sprintf(format, args...))
+}
+
+func conditionFailed() error {
+	return fmt.Errorf("%w: %w", dynamodbClient.ErrConditionFailed, &types.ConditionalCheckFailedException{Message: aws.String("The conditional request failed")})
+}
+
+// Tables.
+
+func (c *Client) ListTables(ctx context.Context) ([]string, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	names := make([]string, 0, len(c.tables))
+	for name := range c.tables {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+func (c *Client) DescribeTable(ctx context.Context, tableName string) (*types.TableDescription, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	t, err := c.table(ctx, tableName)
+	if err != nil {
+		return nil, err
+	}
+	description := t.description
+	description.TableName = aws.String(tableName)
+	description.ItemCount = aws.Int64(int64(len(t.items)))
+	return &description, nil
+}
+
+func (c *Client) CreateTable(ctx context.Context, tableName string, input *dynamodb.CreateTableInput) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	tableName, err := dynamodbClient.TenantTableName(ctx, c.tenantTables, tableName)
+	if err != nil {
+		return err
+	}
+	if _, ok := c.tables[tableName]; ok {
+		return &types.ResourceInUseException{Message: aws.String("Table already exists: " + tableName)}
+	}
+	if len(input.KeySchema) == 0 {
+		return invalid("the table needs a key schema")
+	}
+
+	now := time.Now()
+	description := types.TableDescription{
+		TableName:            aws.String(tableName),
+		TableArn:             aws.String("arn:aws:dynamodb:memory:000000000000:table/" + tableName),
+		TableStatus:          types.TableStatusActive,
+		CreationDateTime:     &now,
+		KeySchema:            input.KeySchema,
+		AttributeDefinitions: input.AttributeDefinitions,
+		BillingModeSummary:   &types.BillingModeSummary{BillingMode: input.BillingMode},
+	}
+	for _, index := range input.GlobalSecondaryIndexes {
+		description.GlobalSecondaryIndexes = append(description.GlobalSecondaryIndexes, types.GlobalSecondaryIndexDescription{
+			IndexName:   index.IndexName,
+			KeySchema:   index.KeySchema,
+			Projection:  index.Projection,
+			IndexStatus: types.IndexStatusActive,
+		})
+	}
+	for _, index := range input.LocalSecondaryIndexes {
+		description.LocalSecondaryIndexes = append(description.LocalSecondaryIndexes, types.LocalSecondaryIndexDescription{
+			IndexName:  index.IndexName,
+			KeySchema:  index.KeySchema,
+			Projection: index.Projection,
+		})
+	}
+
+	c.tables[tableName] = &table{
+		description: description,
+		ttl:         types.TimeToLiveDescription{TimeToLiveStatus: types.TimeToLiveStatusDisabled},
+		items:       map[string]item{},
+	}
+	return nil
+}
+
+func (c *Client) DeleteTable(ctx context.Context, tableName string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if _, err := c.table(ctx, tableName); err != nil {
+		return err
+	}
+	tableName, _ = dynamodbClient.TenantTableName(ctx, c.tenantTables, tableName)
+	delete(c.tables, tableName)
+	return nil
+}
+
+func (c *Client) UpdateTimeToLive(ctx context.Context, tableName string, attributeName string, enabled bool) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	t, err := c.table(ctx, tableName)
+	if err != nil {
+		return err
+	}
+	t.ttl = types.TimeToLiveDescription{AttributeName: aws.String(attributeName), TimeToLiveStatus: types.TimeToLiveStatusEnabled}
+	if !enabled {
+		t.ttl = types.TimeToLiveDescription{TimeToLiveStatus: types.TimeToLiveStatusDisabled}
+	}
+	return nil
+}
+
+func (c *Client) DescribeTimeToLive(ctx context.Context, tableName string) (*types.TimeToLiveDescription, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	t, err := c.table(ctx, tableName)
+	if err != nil {
+		return nil, err
+	}
+	ttl := t.ttl
+	return &ttl, nil
+}
+
+func (c *Client) Ping(ctx context.Context) error {
+	return nil
+}
+
+func (c *Client) HealthCheck(ctx context.Context, tableNames ...string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, tableName := range tableNames {
+		if _, err := c.table(ctx, tableName); err != nil {
+			return fmt.Errorf("failed to describe table `%s`: %v", tableName, err)
+		}
+	}
+	return nil
+}
+
+// Reads.
+
+func (c *Client) GetItem(ctx context.Context, tableName string, key map[string]types.AttributeValue, result any) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	t, err := c.table(ctx, tableName)
+	if err != nil {
+		return err
+	}
+	k, err := t.key(key)
+	if err != nil {
+		return err
+	}
+	it, ok := t.items[k]
+	if !ok {
+		return dynamodbClient.ErrNotFound
+	}
+	return attributevalue.UnmarshalMap(cloneItem(it), result)
+}
+
+func (c *Client) TransactGetItem(ctx context.Context, tableName string, key map[string]types.AttributeValue, result any) (*dynamodb.TransactGetItemsOutput, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	t, err := c.table(ctx, tableName)
+	if err != nil {
+		return nil, err
+	}
+	k, err := t.key(key)
+	if err != nil {
+		return nil, err
+	}
+	it, ok := t.items[k]
+	if !ok {
+		return &dynamodb.TransactGetItemsOutput{Responses: []types.ItemResponse{{}}}, nil
+	}
+	it = cloneItem(it)
+	if err := attributevalue.UnmarshalMap(it, result); err != nil {
+		return nil, err
+	}
+	return &dynamodb.TransactGetItemsOutput{Responses: []types.ItemResponse{{Item: it}}}, nil
+}
+
+func (c *Client) BatchGetItem(ctx context.Context, tableName string, keys []map[string]types.AttributeValue, result any) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	t, err := c.table(ctx, tableName)
+	if err != nil {
+		return err
+	}
+	var items []map[string]types.AttributeValue
+	for _, key := range keys {
+		k, err := t.key(key)
+		if err != nil {
+			return err
+		}
+		if it, ok := t.items[k]; ok {
+			items = append(items, cloneItem(it))
+		}
+	}
+	return attributevalue.UnmarshalListOfMaps(items, result)
+}
+
+func (c *Client) Scan(ctx context.Context, tableName string, result any) (*dynamodb.ScanOutput, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	t, err := c.table(ctx, tableName)
+	if err != nil {
+		return nil, err
+	}
+	items, _, err := t.ordered("")
+	if err != nil {
+		return nil, err
+	}
+	if err := attributevalue.UnmarshalListOfMaps(items, result); err != nil {
+		return nil, err
+	}
+	return &dynamodb.ScanOutput{Items: items, Count: int32(len(items)), ScannedCount: int32(len(items))}, nil
+}
+
+func (c *Client) Query(ctx context.Context, tableName string, query dynamodbClient.QueryRequest, result any) (string, error) {
+	return c.page(ctx, tableName, query.IndexName, query.KeyCondition, query.Filter, query.Projection, query.Names, query.Values, query.Limit, query.Cursor, query.Descending, result)
+}
+
+func (c *Client) ScanPage(ctx context.Context, tableName string, scan dynamodbClient.ScanRequest, result any) (string, error) {
+	return c.page(ctx, tableName, scan.IndexName, "", scan.Filter, scan.Projection, scan.Names, scan.Values, scan.Limit, scan.Cursor, false, result)
+}
+
+// page reads up to limit items of a table or index in key order, starting after cursor. Like
+// DynamoDB, the limit counts items read before the filter is applied.
+func (c *Client) page(ctx context.Context, tableName, indexName, keyCondition, filter, projection string, names map[string]string, rawValues map[string]any,
+	limit int32, cursor string, descending bool, result any) (string, error) {
+	values := map[string]types.AttributeValue{}
+	for placeholder, value := range rawValues {
+		av, err := attributevalue.Marshal(value)
+		if err != nil {
+			return "", err
+		}
+		values[placeholder] = av
+	}
+	matchKey, err := parseCondition(keyCondition, names, values)
+	if err != nil {
+		return "", err
+	}
+	matchFilter, err := parseCondition(filter, names, values)
+	if err != nil {
+		return "", err
+	}
+	var paths []path
+	if projection != "" {
+		e, err := parseExpression(projection, names, values)
+		if err != nil {
+			return "", invalid("invalid ProjectionExpression: %v", err)
+		}
+		if paths, err = e.projection(); err != nil {
+			return "", invalid("invalid ProjectionExpression: %v", err)
+		}
+	}
+	startKey, err := dynamodbClient.DecodeCursor(cursor)
+	if err != nil {
+		return "", err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	t, err := c.table(ctx, tableName)
+	if err != nil {
+		return "", err
+	}
+	items, order, err := t.ordered(indexName)
+	if err != nil {
+		return "", err
+	}
+	if descending {
+		for i, j := 0, len(items)-1; i < j; i, j = i+1, j-1 {
+			items[i], items[j] = items[j], items[i]
+		}
+	}
+
+	var page []map[string]types.AttributeValue
+	var lastKey map[string]types.AttributeValue
+	read := int32(0)
+	for i, it := range items {
+		if startKey != nil {
+			c := compareKeys(it, startKey, order)
+			if (!descending && c <= 0) || (descending && c >= 0) {
+				continue
+			}
+		}
+		if matchKey != nil && !matchKey(it) {
+			continue
+		}
+		read++
+		if matchFilter == nil || matchFilter(it) {
+			if paths != nil {
+				page = append(page, cloneItem(project(it, paths)))
+			} else {
+				page = append(page, cloneItem(it))
+			}
+		}
+		if limit > 0 && read == limit && i < len(items)-1 {
+			lastKey = item{}
+			for _, name := range order {
+				lastKey[name] = it[name]
+			}
+			break
+		}
+	}
+
+	if err := attributevalue.UnmarshalListOfMaps(page, result); err != nil {
+		return "", err
+	}
+	return dynamodbClient.EncodeCursor(lastKey)
+}
+
+func parseCondition(text string, names map[string]string, values map[string]types.AttributeValue) (condition, error) {
+	if text == "" {
+		return nil, nil
+	}
+	e, err := parseExpression(text, names, values)
+	if err != nil {
+		return nil, invalid("invalid expression %q: %v", text, err)
+	}
+	match, err := e.condition()
+	if err == nil {
+		err = e.done()
+	}
+	if err != nil {
+		return nil, invalid("invalid expression %q: %v", text, err)
+	}
+	return match, nil
+}
+
+// Writes.
+
+func (c *Client) TransactWriteItems(ctx context.Context, tableName string, body any, opts ...dynamodbClient.WriteOption) error {
+	it, err := attributevalue.MarshalMap(body)
+	if err != nil {
+		return err
+	}
+	check, err := writeCondition(opts)
+	if err != nil {
+		return err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	t, err := c.table(ctx, tableName)
+	if err != nil {
+		return err
+	}
+	k, err := t.key(it)
+	if err != nil {
+		return err
+	}
+	if check != nil && !check(t.items[k]) {
+		return conditionFailed()
+	}
+	t.items[k] = cloneItem(it)
+	return nil
+}
+
+func (c *Client) BatchWriteItem(ctx context.Context, tableName string, items []any) error {
+	marshaled := make([]item, len(items))
+	for i, body := range items {
+		it, err := attributevalue.MarshalMap(body)
+		if err != nil {
+			return err
+		}
+		marshaled[i] = it
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	t, err := c.table(ctx, tableName)
+	if err != nil {
+		return err
+	}
+	keys := make([]string, len(marshaled))
+	seen := map[string]bool{}
+	for i, it := range marshaled {
+		if keys[i], err = t.key(it); err != nil {
+			return err
+		}
+		if seen[keys[i]] {
+			return invalid("provided list of item keys contains duplicates")
+		}
+		seen[keys[i]] = true
+	}
+	for i, it := range marshaled {
+		t.items[keys[i]] = cloneItem(it)
+	}
+	return nil
+}
+
+func (c *Client) DeleteItem(ctx context.Context, tableName string, key map[string]types.AttributeValue, opts ...dynamodbClient.WriteOption) error {
+	check, err := writeCondition(opts)
+	if err != nil {
+		return err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	t, err := c.table(ctx, tableName)
+	if err != nil {
+		return err
+	}
+	k, err := t.key(key)
+	if err != nil {
+		return err
+	}
+	if check != nil && !check(t.items[k]) {
+		return conditionFailed()
+	}
+	delete(t.items, k)
+	return nil
+}
+
+// UpdateItem creates the item when it does not exist, as DynamoDB does.
+func (c *Client) UpdateItem(ctx context.Context, tableName string, key map[string]types.AttributeValue, updateExpression string, requestBody any, opts ...dynamodbClient.WriteOption) error {
+	values, err := attributevalue.MarshalMap(requestBody)
+	if err != nil {
+		return err
+	}
+	condition, names, conditionValues, err := dynamodbClient.WriteCondition(opts...)
+	if err != nil {
+		return err
+	}
+	for placeholder, value := range conditionValues {
+		values[placeholder] = value
+	}
+	check, err := parseCondition(condition, names, values)
+	if err != nil {
+		return err
+	}
+	e, err := parseExpression(updateExpression, names, values)
+	if err != nil {
+		return invalid("invalid UpdateExpression: %v", err)
+	}
+	apply, err := e.update()
+	if err != nil {
+		return invalid("invalid UpdateExpression: %v", err)
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	t, err := c.table(ctx, tableName)
+	if err != nil {
+		return err
+	}
+	k, err := t.key(key)
+	if err != nil {
+		return err
+	}
+	current := t.items[k]
+	if check != nil && !check(current) {
+		return conditionFailed()
+	}
+
+	updated := cloneItem(key)
+	if current != nil {
+		updated = cloneItem(current)
+	}
+	if err := apply(updated); err != nil {
+		return invalid("%v", err)
+	}
+	for name, value := range key {
+		if !equal(updated[name], value) {
+			return invalid("cannot update attribute %s, which is part of the key", name)
+		}
+	}
+	t.items[k] = updated
+	return nil
+}
+
+// writeCondition parses the condition of a put or delete; it sees a missing item as an empty one.
+func writeCondition(opts []dynamodbClient.WriteOption) (func(it item) bool, error) {
+	condition, names, values, err := dynamodbClient.WriteCondition(opts...)
+	if err != nil || condition == "" {
+		return nil, err
+	}
+	match, err := parseCondition(condition, names, values)
+	if err != nil {
+		return nil, err
+	}
+	return func(it item) bool {
+		if it == nil {
+			it = item{}
+		}
+		return match(it)
+	}, nil
+}
+
+// PartiQL.
+
+func (c *Client) ExecuteStatement(ctx context.Context, statement string, params []any, result any) error {
+	return errPartiQL
+}
+
+func (c *Client) BatchExecuteStatement(ctx context.Context, statements []dynamodbClient.Statement) ([]dynamodbClient.StatementResult, error) {
+	return nil, errPartiQL
+}
+
+func (c *Client) ExecuteTransaction(ctx context.Context, statements []dynamodbClient.Statement, result any) error {
+	return errPartiQL
+}
+
+// Keys and order.
+
+func keyNames(schema []types.KeySchemaElement) []string {
+	names := make([]string, 0, len(schema))
+	for _, element := range schema {
+		if element.KeyType == types.KeyTypeHash {
+			names = append([]string{aws.ToString(element.AttributeName)}, names...)
+		} else {
+			names = append(names, aws.ToString(element.AttributeName))
+		}
+	}
+	return names
+}
+
+// key checks that it has the table's key attributes with their declared types and returns the
+// string the item is stored under.
+func (t *table) key(it item) (string, error) {
+	var parts []string
+	for _, name := range keyNames(t.description.KeySchema) {
+		v, ok := it[name]
+		if !ok {
+			return "", invalid("the key attribute %s is missing", name)
+		}
+		want := "S"
+		for _, definition := range t.description.AttributeDefinitions {
+			if aws.ToString(definition.AttributeName) == name {
+				want = string(definition.AttributeType)
+			}
+		}
+		if typeName(v) != want {
+			return "", invalid("the key attribute %s must be of type %s", name, want)
+		}
+		parts = append(parts, keyPart(v))
+	}
+	return strings.Join(parts, "\x00"), nil
+}
+
+func keyPart(v types.AttributeValue) string {
+	switch value := v.(type) {
+	case *types.AttributeValueMemberN:
+		// 1 and 1.0 are the same number.
+		if f, ok := new(big.Float).SetString(value.Value); ok {
+			return f.Text('g', -1)
+		}
+		return value.Value
+	case *types.AttributeValueMemberS:
+		return value.Value
+	case *types.AttributeValueMemberB:
+		return string(value.Value)
+	}
+	return ""
+}
+
+// ordered returns the items of the table or one of its indexes sorted by key, and the attributes
+// of that order: the index's key, then the table's. Items without the index key are left out.
+func (t *table) ordered(indexName string) ([]item, []string, error) {
+	order := keyNames(t.description.KeySchema)
+	if indexName != "" {
+		var indexKey []types.KeySchemaElement
+		for _, index := range t.description.GlobalSecondaryIndexes {
+			if aws.ToString(index.IndexName) == indexName {
+				indexKey = index.KeySchema
+			}
+		}
+		for _, index := range t.description.LocalSecondaryIndexes {
+			if aws.ToString(index.IndexName) == indexName {
+				indexKey = index.KeySchema
+			}
+		}
+		if indexKey == nil {
+			return nil, nil, invalid("the table does not have the specified index: %s", indexName)
+		}
+		names := keyNames(indexKey)
+		for _, name := range order {
+			if !containsString(names, name) {
+				names = append(names, name)
+			}
+		}
+		order = names
+	}
+
+	items := make([]item, 0, len(t.items))
+	for _, it := range t.items {
+		complete := true
+		for _, name := range order {
+			if it[name] == nil {
+				complete = false
+			}
+		}
+		if complete {
+			items = append(items, it)
+		}
+	}
+	sort.Slice(items, func(i, j int) bool { return compareKeys(items[i], items[j], order) < 0 })
+	return items, order, nil
+}
+
+func compareKeys(a, b item, order []string) int {
+	for _, name := range order {
+		if c, _ := compare(a[name], b[name]); c != 0 {
+			return c
+		}
+	}
+	return 0
+}
@@ -0,0 +1,615 @@
+package memory_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"dytest/config"
+	dynamodbClient "dytest/dynamodb"
+	"dytest/dynamodb/memory"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+type movie struct {
+	Year  int            `dynamodbav:"year"`
+	Title string         `dynamodbav:"title"`
+	Genre string         `dynamodbav:"genre,omitempty"`
+	Tags  []string       `dynamodbav:"tags,stringset,omitempty"`
+	Info  map[string]any `dynamodbav:"info,omitempty"`
+}
+
+var movies = []movie{
+	{Year: 1999, Title: "The Matrix", Genre: "Sci-Fi", Info: map[string]any{"rating": 8.7}},
+	{Year: 2010, Title: "Inception", Genre: "Sci-Fi", Tags: []string{"dreams", "heist"}, Info: map[string]any{
+		"rating": 8.8,
+		"plot":   "A thief steals secrets through dreams",
+		"actors": []string{"Leonardo DiCaprio", "Elliot Page"},
+	}},
+	{Year: 2010, Title: "Shutter Island", Genre: "Thriller", Info: map[string]any{
+		"rating": 8.2,
+		"actors": []string{"Leonardo DiCaprio"},
+	}},
+	// Without a genre, Toy Story 3 is not in the genre index.
+	{Year: 2010, Title: "Toy Story 3", Info: map[string]any{"rating": 8.3}},
+	{Year: 2014, Title: "Interstellar", Genre: "Sci-Fi", Tags: []string{"space"}, Info: map[string]any{
+		"rating": 8.7,
+		"plot":   "Explorers travel through a wormhole",
+	}},
+}
+
+// stringSet marshals as a string set rather than a list.
+type stringSet []string
+
+func (s stringSet) MarshalDynamoDBAttributeValue() (types.AttributeValue, error) {
+	return &types.AttributeValueMemberSS{Value: s}, nil
+}
+
+// names and values are the placeholders the expressions below draw on; year is a reserved word.
+var names = map[string]string{
+	"#year": "year", "#title": "title", "#genre": "genre", "#tags": "tags", "#count": "count",
+	"#info": "info", "#rating": "rating", "#plot": "plot", "#actors": "actors", "#rank": "rank", "#details": "details",
+}
+
+var values = map[string]any{
+	":y1999": 1999, ":y2010": 2010, ":y2014": 2014,
+	":n1": 1, ":n8_2": 8.2, ":n8_3": 8.3, ":n8_5": 8.5, ":n8_7": 8.7, ":n9": 9,
+	":a": "A", ":c": "C", ":m": "M", ":s": "S", ":st": "st", ":the": "The", ":inception": "Inception",
+	":scifi": "Sci-Fi", ":thriller": "Thriller", ":leo": "Leonardo DiCaprio", ":heist": "heist",
+	":typeS": "S", ":typeSS": "SS", ":plot": "A plot",
+	":more": []string{"C"}, ":tagA": stringSet{"a"}, ":tagB": stringSet{"b"},
+}
+
+var placeholderPattern = regexp.MustCompile(`[#:][A-Za-z0-9_]+`)
+
+// used picks the placeholders the expressions refer to: DynamoDB refuses unused ones.
+func used[V any](all map[string]V, expressions ...string) map[string]V {
+	var found map[string]V
+	for _, token := range placeholderPattern.FindAllString(strings.Join(expressions, " "), -1) {
+		if v, ok := all[token]; ok {
+			if found == nil {
+				found = map[string]V{}
+			}
+			found[token] = v
+		}
+	}
+	return found
+}
+
+// suite runs test against a new in-memory client and, when DYNAMODB_ENDPOINT is set (e.g. to
+// http://localhost:8000 from docker-compose.yml), against dynamodb-local, so that the in-memory
+// client is held to what DynamoDB does. Either way the Movies table starts out holding movies.
+func suite(t *testing.T, test func(t *testing.T, db dynamodbClient.DynamodbClient)) {
+	t.Run("memory", func(t *testing.T) {
+		db := memory.New()
+		createMovies(t, db)
+		test(t, db)
+	})
+	t.Run("dynamodb-local", func(t *testing.T) {
+		endpoint := os.Getenv("DYNAMODB_ENDPOINT")
+		if endpoint == "" {
+			t.Skip("DYNAMODB_ENDPOINT is not set")
+		}
+		db, err := dynamodbClient.NewDynamodbClient(context.Background(), config.DynamoDBConfig{
+			Endpoint:    endpoint,
+			Region:      "localhost",
+			TablePrefix: fmt.Sprintf("memory_test_%d_", time.Now().UnixNano()),
+		})
+		if err != nil {
+			t.Fatalf("NewDynamodbClient: %v", err)
+		}
+		createMovies(t, db)
+		t.Cleanup(func() {
+			if err := db.DeleteTable(context.Background(), "Movies"); err != nil {
+				t.Errorf("DeleteTable: %v", err)
+			}
+		})
+		test(t, db)
+	})
+}
+
+func createMovies(t *testing.T, db dynamodbClient.DynamodbClient) {
+	t.Helper()
+	ctx := context.Background()
+	err := db.CreateTable(ctx, "Movies", &dynamodb.CreateTableInput{
+		AttributeDefinitions: []types.AttributeDefinition{
+			{AttributeName: aws.String("year"), AttributeType: types.ScalarAttributeTypeN},
+			{AttributeName: aws.String("title"), AttributeType: types.ScalarAttributeTypeS},
+			{AttributeName: aws.String("genre"), AttributeType: types.ScalarAttributeTypeS},
+		},
+		KeySchema: []types.KeySchemaElement{
+			{AttributeName: aws.String("year"), KeyType: types.KeyTypeHash},
+			{AttributeName: aws.String("title"), KeyType: types.KeyTypeRange},
+		},
+		GlobalSecondaryIndexes: []types.GlobalSecondaryIndex{{
+			IndexName: aws.String("genre"),
+			KeySchema: []types.KeySchemaElement{
+				{AttributeName: aws.String("genre"), KeyType: types.KeyTypeHash},
+				{AttributeName: aws.String("year"), KeyType: types.KeyTypeRange},
+			},
+			Projection: &types.Projection{ProjectionType: types.ProjectionTypeAll},
+		}},
+		BillingMode: types.BillingModePayPerRequest,
+	})
+	if err != nil {
+		t.Fatalf("CreateTable: %v", err)
+	}
+	items := make([]any, len(movies))
+	for i, m := range movies {
+		items[i] = m
+	}
+	if err := db.BatchWriteItem(ctx, "Movies", items); err != nil {
+		t.Fatalf("BatchWriteItem: %v", err)
+	}
+}
+
+func key(year int, title string) map[string]types.AttributeValue {
+	return map[string]types.AttributeValue{
+		"year":  &types.AttributeValueMemberN{Value: fmt.Sprint(year)},
+		"title": &types.AttributeValueMemberS{Value: title},
+	}
+}
+
+func titles(items []map[string]any) []string {
+	list := []string{}
+	for _, it := range items {
+		list = append(list, it["title"].(string))
+	}
+	return list
+}
+
+func TestConditions(t *testing.T) {
+	tests := []struct {
+		condition string
+		titles    []string
+	}{
+		{"#info.#rating > :n8_5", []string{"Inception", "Interstellar", "The Matrix"}},
+		{"#info.#rating >= :n8_7", []string{"Inception", "Interstellar", "The Matrix"}},
+		{"#info.#rating < :n8_3", []string{"Shutter Island"}},
+		{"#info.#rating <= :n8_3", []string{"Shutter Island", "Toy Story 3"}},
+		{"#info.#rating = :n8_7", []string{"Interstellar", "The Matrix"}},
+		{"#info.#rating BETWEEN :n8_2 AND :n8_3", []string{"Shutter Island", "Toy Story 3"}},
+		{"#year IN (:y1999, :y2014)", []string{"Interstellar", "The Matrix"}},
+		{"#title < :inception", []string{}},
+		{"#title > :the", []string{"The Matrix", "Toy Story 3"}},
+		// A missing attribute only matches <>, as does a value of another type.
+		{"#genre <> :scifi", []string{"Shutter Island", "Toy Story 3"}},
+		{"#genre = :n1", []string{}},
+		{"#year <> :the", []string{"Inception", "Interstellar", "Shutter Island", "The Matrix", "Toy Story 3"}},
+		{"#year < :the", []string{}},
+		{"attribute_exists(#genre)", []string{"Inception", "Interstellar", "Shutter Island", "The Matrix"}},
+		{"attribute_not_exists(#info.#plot)", []string{"Shutter Island", "The Matrix", "Toy Story 3"}},
+		{"attribute_exists(#info.#actors[1])", []string{"Inception"}},
+		{"attribute_type(#tags, :typeSS)", []string{"Inception", "Interstellar"}},
+		{"attribute_type(#info.#rating, :typeS)", []string{}},
+		{"begins_with(#title, :the)", []string{"The Matrix"}},
+		{"begins_with(#info.#plot, :a)", []string{"Inception"}},
+		{"contains(#title, :st)", []string{"Interstellar"}},
+		{"contains(#info.#actors, :leo)", []string{"Inception", "Shutter Island"}},
+		{"contains(#tags, :heist)", []string{"Inception"}},
+		{"#info.#actors[0] = :leo", []string{"Inception", "Shutter Island"}},
+		{"size(#info.#actors) > :n1", []string{"Inception"}},
+		{"size(#tags) = :n1", []string{"Interstellar"}},
+		{"size(#info.#rating) >= :n1", []string{}},
+		{"size(#info) = :n1", []string{"The Matrix", "Toy Story 3"}},
+		{"NOT attribute_exists(#tags)", []string{"Shutter Island", "The Matrix", "Toy Story 3"}},
+		{"#info.#rating >= :n8_7 AND NOT #year = :y2014", []string{"Inception", "The Matrix"}},
+		// AND binds tighter than OR.
+		{"#year = :y1999 OR #year = :y2014 AND #genre = :thriller", []string{"The Matrix"}},
+		{"(#year = :y1999 OR #year = :y2014) AND #genre = :scifi", []string{"Interstellar", "The Matrix"}},
+		{"NOT (#genre = :scifi OR #genre = :thriller)", []string{"Toy Story 3"}},
+	}
+	suite(t, func(t *testing.T, db dynamodbClient.DynamodbClient) {
+		for _, tt := range tests {
+			t.Run(tt.condition, func(t *testing.T) {
+				var items []map[string]any
+				_, err := db.ScanPage(context.Background(), "Movies", dynamodbClient.ScanRequest{
+					Filter: tt.condition,
+					Names:  used(names, tt.condition),
+					Values: used(values, tt.condition),
+				}, &items)
+				if err != nil {
+					t.Fatalf("ScanPage: %v", err)
+				}
+				got := titles(items)
+				sort.Strings(got)
+				if !reflect.DeepEqual(got, tt.titles) {
+					t.Errorf("titles = %v, want %v", got, tt.titles)
+				}
+			})
+		}
+	})
+}
+
+func TestInvalidExpressions(t *testing.T) {
+	tests := []struct {
+		name   string
+		filter string
+		names  map[string]string
+		values map[string]any
+	}{
+		{"undefined value", "#year = :missing", map[string]string{"#year": "year"}, nil},
+		{"undefined name", "#missing = :n1", nil, map[string]any{":n1": 1}},
+		{"no comparison", "#year", map[string]string{"#year": "year"}, nil},
+		{"unbalanced", "(#year = :n1", map[string]string{"#year": "year"}, map[string]any{":n1": 1}},
+	}
+	suite(t, func(t *testing.T, db dynamodbClient.DynamodbClient) {
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				var items []map[string]any
+				_, err := db.ScanPage(context.Background(), "Movies", dynamodbClient.ScanRequest{Filter: tt.filter, Names: tt.names, Values: tt.values}, &items)
+				if !errors.Is(err, dynamodbClient.ErrValidation) {
+					t.Errorf("ScanPage = %v, want ErrValidation", err)
+				}
+			})
+		}
+	})
+}
+
+func TestWriteConditions(t *testing.T) {
+	condition := func(expression string) dynamodbClient.WriteOption {
+		return dynamodbClient.Condition(expression, used(names, expression), used(values, expression))
+	}
+	suite(t, func(t *testing.T, db dynamodbClient.DynamodbClient) {
+		ctx := context.Background()
+
+		replaced := movie{Year: 2010, Title: "Inception", Info: map[string]any{"rating": 1}}
+		if err := db.TransactWriteItems(ctx, "Movies", replaced, dynamodbClient.IfNotExists("year")); !errors.Is(err, dynamodbClient.ErrConditionFailed) {
+			t.Errorf("put over an existing item with IfNotExists = %v, want ErrConditionFailed", err)
+		}
+		if err := db.TransactWriteItems(ctx, "Movies", replaced, condition("#info.#rating < :n8_5")); !errors.Is(err, dynamodbClient.ErrConditionFailed) {
+			t.Errorf("put with a failing condition = %v, want ErrConditionFailed", err)
+		}
+		var got movie
+		if err := db.GetItem(ctx, "Movies", key(2010, "Inception"), &got); err != nil || got.Genre != "Sci-Fi" {
+			t.Errorf("GetItem after failed puts = %+v, %v; want the item unchanged", got, err)
+		}
+
+		added := movie{Year: 2017, Title: "Dunkirk"}
+		if err := db.TransactWriteItems(ctx, "Movies", added, dynamodbClient.IfNotExists("year")); err != nil {
+			t.Errorf("put of a new item with IfNotExists = %v", err)
+		}
+		// A condition on an item that does not exist sees no attributes.
+		if err := db.TransactWriteItems(ctx, "Movies", movie{Year: 2017, Title: "Tenet"}, condition("attribute_not_exists(#info)")); err != nil {
+			t.Errorf("put of a new item with attribute_not_exists = %v", err)
+		}
+		if err := db.TransactWriteItems(ctx, "Movies", movie{Year: 2017, Title: "Memento"}, condition("#info.#rating > :n1")); !errors.Is(err, dynamodbClient.ErrConditionFailed) {
+			t.Errorf("put of a new item with a comparison = %v, want ErrConditionFailed", err)
+		}
+		if err := db.TransactWriteItems(ctx, "Movies", movie{Year: 2010, Title: "Shutter Island"}, condition("#info.#rating < :n8_5")); err != nil {
+			t.Errorf("put with a passing condition = %v", err)
+		}
+		got = movie{}
+		if err := db.GetItem(ctx, "Movies", key(2010, "Shutter Island"), &got); err != nil || got.Info != nil || got.Genre != "" {
+			t.Errorf("GetItem after a conditional put = %+v, %v; want the item replaced", got, err)
+		}
+
+		if err := db.DeleteItem(ctx, "Movies", key(2000, "Missing"), dynamodbClient.IfExists("year")); !errors.Is(err, dynamodbClient.ErrConditionFailed) {
+			t.Errorf("delete of a missing item with IfExists = %v, want ErrConditionFailed", err)
+		}
+		if err := db.DeleteItem(ctx, "Movies", key(2010, "Inception"), condition("contains(#tags, :leo)")); !errors.Is(err, dynamodbClient.ErrConditionFailed) {
+			t.Errorf("delete with a failing condition = %v, want ErrConditionFailed", err)
+		}
+		if err := db.DeleteItem(ctx, "Movies", key(2010, "Inception"), condition("contains(#tags, :heist)")); err != nil {
+			t.Errorf("delete with a passing condition = %v", err)
+		}
+		if err := db.GetItem(ctx, "Movies", key(2010, "Inception"), &got); !errors.Is(err, dynamodbClient.ErrNotFound) {
+			t.Errorf("GetItem after delete = %v, want ErrNotFound", err)
+		}
+
+		// UpdateItem creates a missing item unless its condition says otherwise.
+		if err := db.UpdateItem(ctx, "Movies", key(2000, "Missing"), "SET #genre = :scifi", used(values, ":scifi"),
+			dynamodbClient.IfExists("year"), dynamodbClient.ExpressionNames(used(names, "#genre"))); !errors.Is(err, dynamodbClient.ErrConditionFailed) {
+			t.Errorf("update of a missing item with IfExists = %v, want ErrConditionFailed", err)
+		}
+		if err := db.GetItem(ctx, "Movies", key(2000, "Missing"), &got); !errors.Is(err, dynamodbClient.ErrNotFound) {
+			t.Errorf("GetItem after a failed update = %v, want ErrNotFound", err)
+		}
+		if err := db.UpdateItem(ctx, "Movies", key(2000, "Created"), "SET #genre = :scifi", used(values, ":scifi"),
+			dynamodbClient.ExpressionNames(used(names, "#genre"))); err != nil {
+			t.Errorf("update of a missing item = %v", err)
+		}
+		got = movie{}
+		if err := db.GetItem(ctx, "Movies", key(2000, "Created"), &got); err != nil || !reflect.DeepEqual(got, movie{Year: 2000, Title: "Created", Genre: "Sci-Fi"}) {
+			t.Errorf("GetItem after an update created the item = %+v, %v", got, err)
+		}
+	})
+}
+
+func TestUpdateExpressions(t *testing.T) {
+	tests := []struct {
+		name       string
+		expression string
+		// want is the item after the update, without its key, or nil when the update fails validation.
+		want map[string]any
+	}{
+		{"set", "SET #info.#rating = :n9", map[string]any{
+			"tags": []string{"a"}, "info": map[string]any{"rating": 9.0, "actors": []any{"A", "B"}},
+		}},
+		{"set new attribute", "SET #info.#plot = :plot, #genre = :scifi", map[string]any{
+			"genre": "Sci-Fi", "tags": []string{"a"}, "info": map[string]any{"rating": 8.0, "actors": []any{"A", "B"}, "plot": "A plot"},
+		}},
+		{"set under a missing parent", "SET #info.#details.#plot = :plot", nil},
+		{"add", "SET #info.#rating = #info.#rating + :n1", map[string]any{
+			"tags": []string{"a"}, "info": map[string]any{"rating": 9.0, "actors": []any{"A", "B"}},
+		}},
+		{"subtract", "SET #info.#rating = #info.#rating - :n8_5", map[string]any{
+			"tags": []string{"a"}, "info": map[string]any{"rating": -0.5, "actors": []any{"A", "B"}},
+		}},
+		{"add to a missing attribute", "SET #info.#rank = #info.#rank + :n1", nil},
+		{"if_not_exists of an attribute", "SET #info.#rating = if_not_exists(#info.#rating, :n1)", map[string]any{
+			"tags": []string{"a"}, "info": map[string]any{"rating": 8.0, "actors": []any{"A", "B"}},
+		}},
+		{"if_not_exists of a missing attribute", "SET #info.#rank = if_not_exists(#info.#rank, :n1)", map[string]any{
+			"tags": []string{"a"}, "info": map[string]any{"rating": 8.0, "rank": 1.0, "actors": []any{"A", "B"}},
+		}},
+		{"list_append", "SET #info.#actors = list_append(#info.#actors, :more)", map[string]any{
+			"tags": []string{"a"}, "info": map[string]any{"rating": 8.0, "actors": []any{"A", "B", "C"}},
+		}},
+		{"list_append in front", "SET #info.#actors = list_append(:more, #info.#actors)", map[string]any{
+			"tags": []string{"a"}, "info": map[string]any{"rating": 8.0, "actors": []any{"C", "A", "B"}},
+		}},
+		{"list_append to a missing list", "SET #info.#plot = list_append(#info.#plot, :more)", nil},
+		{"list_append with if_not_exists", "SET #info.#plot = list_append(if_not_exists(#info.#plot, :more), :more)", map[string]any{
+			"tags": []string{"a"}, "info": map[string]any{"rating": 8.0, "actors": []any{"A", "B"}, "plot": []any{"C", "C"}},
+		}},
+		{"set a list element", "SET #info.#actors[1] = :c", map[string]any{
+			"tags": []string{"a"}, "info": map[string]any{"rating": 8.0, "actors": []any{"A", "C"}},
+		}},
+		{"set past the end of a list", "SET #info.#actors[5] = :c", map[string]any{
+			"tags": []string{"a"}, "info": map[string]any{"rating": 8.0, "actors": []any{"A", "B", "C"}},
+		}},
+		{"remove", "REMOVE #info.#rating, #tags", map[string]any{
+			"info": map[string]any{"actors": []any{"A", "B"}},
+		}},
+		{"remove a list element", "REMOVE #info.#actors[0]", map[string]any{
+			"tags": []string{"a"}, "info": map[string]any{"rating": 8.0, "actors": []any{"B"}},
+		}},
+		{"remove a missing attribute", "REMOVE #info.#plot", map[string]any{
+			"tags": []string{"a"}, "info": map[string]any{"rating": 8.0, "actors": []any{"A", "B"}},
+		}},
+		{"set and remove", "SET #info.#plot = :plot REMOVE #info.#rating", map[string]any{
+			"tags": []string{"a"}, "info": map[string]any{"plot": "A plot", "actors": []any{"A", "B"}},
+		}},
+		{"add a number", "ADD #count :n1", map[string]any{
+			"count": 1.0, "tags": []string{"a"}, "info": map[string]any{"rating": 8.0, "actors": []any{"A", "B"}},
+		}},
+		{"add to a set", "ADD #tags :tagB", map[string]any{
+			"tags": []string{"a", "b"}, "info": map[string]any{"rating": 8.0, "actors": []any{"A", "B"}},
+		}},
+		{"delete from a set", "DELETE #tags :tagA", map[string]any{
+			"info": map[string]any{"rating": 8.0, "actors": []any{"A", "B"}},
+		}},
+		{"set a key attribute", "SET #title = :plot", nil},
+	}
+	suite(t, func(t *testing.T, db dynamodbClient.DynamodbClient) {
+		ctx := context.Background()
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				base := movie{Year: 2000, Title: tt.name, Tags: []string{"a"}, Info: map[string]any{"rating": 8, "actors": []string{"A", "B"}}}
+				if err := db.TransactWriteItems(ctx, "Movies", base); err != nil {
+					t.Fatalf("TransactWriteItems: %v", err)
+				}
+
+				err := db.UpdateItem(ctx, "Movies", key(2000, tt.name), tt.expression, used(values, tt.expression),
+					dynamodbClient.ExpressionNames(used(names, tt.expression)))
+				if tt.want == nil {
+					if !errors.Is(err, dynamodbClient.ErrValidation) {
+						t.Errorf("UpdateItem = %v, want ErrValidation", err)
+					}
+					return
+				}
+				if err != nil {
+					t.Fatalf("UpdateItem: %v", err)
+				}
+
+				var got map[string]any
+				if err := db.GetItem(ctx, "Movies", key(2000, tt.name), &got); err != nil {
+					t.Fatalf("GetItem: %v", err)
+				}
+				delete(got, "year")
+				delete(got, "title")
+				if tags, ok := got["tags"].([]string); ok {
+					sort.Strings(tags)
+				}
+				if !reflect.DeepEqual(got, tt.want) {
+					t.Errorf("item = %v, want %v", got, tt.want)
+				}
+			})
+		}
+	})
+}
+
+func TestQuery(t *testing.T) {
+	tests := []struct {
+		name   string
+		query  dynamodbClient.QueryRequest
+		titles []string
+	}{
+		{"partition", dynamodbClient.QueryRequest{KeyCondition: "#year = :y2010"},
+			[]string{"Inception", "Shutter Island", "Toy Story 3"}},
+		{"descending", dynamodbClient.QueryRequest{KeyCondition: "#year = :y2010", Descending: true},
+			[]string{"Toy Story 3", "Shutter Island", "Inception"}},
+		{"begins_with", dynamodbClient.QueryRequest{KeyCondition: "#year = :y2010 AND begins_with(#title, :s)"},
+			[]string{"Shutter Island"}},
+		{"between", dynamodbClient.QueryRequest{KeyCondition: "#year = :y2010 AND #title BETWEEN :a AND :m"},
+			[]string{"Inception"}},
+		{"comparison", dynamodbClient.QueryRequest{KeyCondition: "#year = :y2010 AND #title > :inception"},
+			[]string{"Shutter Island", "Toy Story 3"}},
+		{"filter", dynamodbClient.QueryRequest{KeyCondition: "#year = :y2010", Filter: "contains(#info.#actors, :leo)"},
+			[]string{"Inception", "Shutter Island"}},
+		{"empty partition", dynamodbClient.QueryRequest{KeyCondition: "#year = :n1"},
+			[]string{}},
+		// The genre index orders by year and leaves out Toy Story 3, which has no genre.
+		{"index", dynamodbClient.QueryRequest{IndexName: "genre", KeyCondition: "#genre = :scifi"},
+			[]string{"The Matrix", "Inception", "Interstellar"}},
+		{"index range", dynamodbClient.QueryRequest{IndexName: "genre", KeyCondition: "#genre = :scifi AND #year >= :y2010", Descending: true},
+			[]string{"Interstellar", "Inception"}},
+	}
+	suite(t, func(t *testing.T, db dynamodbClient.DynamodbClient) {
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				query := tt.query
+				query.Names = used(names, query.KeyCondition, query.Filter)
+				query.Values = used(values, query.KeyCondition, query.Filter)
+				var items []map[string]any
+				if _, err := db.Query(context.Background(), "Movies", query, &items); err != nil {
+					t.Fatalf("Query: %v", err)
+				}
+				if got := titles(items); !reflect.DeepEqual(got, tt.titles) {
+					t.Errorf("titles = %v, want %v", got, tt.titles)
+				}
+			})
+		}
+	})
+}
+
+func TestQueryPages(t *testing.T) {
+	suite(t, func(t *testing.T, db dynamodbClient.DynamodbClient) {
+		ctx := context.Background()
+		query := dynamodbClient.QueryRequest{
+			KeyCondition: "#year = :y2010",
+			Names:        used(names, "#year"),
+			Values:       used(values, ":y2010"),
+			Limit:        1,
+		}
+		var all []string
+		for pages := 0; ; pages++ {
+			if pages > 5 {
+				t.Fatalf("still paging after %v", all)
+			}
+			var items []map[string]any
+			cursor, err := db.Query(ctx, "Movies", query, &items)
+			if err != nil {
+				t.Fatalf("Query: %v", err)
+			}
+			all = append(all, titles(items)...)
+			if cursor == "" {
+				break
+			}
+			query.Cursor = cursor
+		}
+		if want := []string{"Inception", "Shutter Island", "Toy Story 3"}; !reflect.DeepEqual(all, want) {
+			t.Errorf("pages = %v, want %v", all, want)
+		}
+
+		// The limit counts the items read before the filter, so a page can come back short.
+		filter := "#info.#rating > :n8_5"
+		query = dynamodbClient.QueryRequest{
+			KeyCondition: "#year = :y2010",
+			Filter:       filter,
+			Names:        used(names, "#year", filter),
+			Values:       used(values, ":y2010", filter),
+			Limit:        2,
+		}
+		var items []map[string]any
+		cursor, err := db.Query(ctx, "Movies", query, &items)
+		if err != nil {
+			t.Fatalf("Query: %v", err)
+		}
+		if got := titles(items); !reflect.DeepEqual(got, []string{"Inception"}) || cursor == "" {
+			t.Errorf("first filtered page = %v, cursor %q; want [Inception] and a cursor", got, cursor)
+		}
+		query.Cursor = cursor
+		items = nil
+		if cursor, err = db.Query(ctx, "Movies", query, &items); err != nil {
+			t.Fatalf("Query: %v", err)
+		}
+		if len(items) != 0 || cursor != "" {
+			t.Errorf("second filtered page = %v, cursor %q; want an empty last page", titles(items), cursor)
+		}
+
+		query.Cursor = "not a cursor"
+		if _, err := db.Query(ctx, "Movies", query, &items); !errors.Is(err, dynamodbClient.ErrInvalidCursor) {
+			t.Errorf("Query with a bad cursor = %v, want ErrInvalidCursor", err)
+		}
+	})
+}
+
+func TestProjection(t *testing.T) {
+	suite(t, func(t *testing.T, db dynamodbClient.DynamodbClient) {
+		projection := "#title, #info.#rating"
+		var items []map[string]any
+		_, err := db.Query(context.Background(), "Movies", dynamodbClient.QueryRequest{
+			KeyCondition: "#year = :y2010 AND #title = :inception",
+			Projection:   projection,
+			Names:        used(names, "#year", projection),
+			Values:       used(values, ":y2010", ":inception"),
+		}, &items)
+		if err != nil {
+			t.Fatalf("Query: %v", err)
+		}
+		want := []map[string]any{{
+			"title": "Inception",
+			"info":  map[string]any{"rating": 8.8},
+		}}
+		if !reflect.DeepEqual(items, want) {
+			t.Errorf("items = %v, want %v", items, want)
+		}
+	})
+}
+
+func TestSparseIndex(t *testing.T) {
+	suite(t, func(t *testing.T, db dynamodbClient.DynamodbClient) {
+		ctx := context.Background()
+		var items []map[string]any
+		if _, err := db.ScanPage(ctx, "Movies", dynamodbClient.ScanRequest{IndexName: "genre"}, &items); err != nil {
+			t.Fatalf("ScanPage: %v", err)
+		}
+		got := titles(items)
+		sort.Strings(got)
+		if want := []string{"Inception", "Interstellar", "Shutter Island", "The Matrix"}; !reflect.DeepEqual(got, want) {
+			t.Errorf("index titles = %v, want %v", got, want)
+		}
+
+		// Removing the index key takes an item out of the index; setting it puts it back.
+		update := func(expression string) {
+			t.Helper()
+			err := db.UpdateItem(ctx, "Movies", key(2010, "Inception"), expression, used(values, expression),
+				dynamodbClient.ExpressionNames(used(names, expression)))
+			if err != nil {
+				t.Fatalf("UpdateItem: %v", err)
+			}
+		}
+		query := dynamodbClient.QueryRequest{
+			IndexName:    "genre",
+			KeyCondition: "#genre = :thriller",
+			Names:        used(names, "#genre"),
+			Values:       used(values, ":thriller"),
+		}
+		update("SET #genre = :thriller")
+		items = nil
+		if _, err := db.Query(ctx, "Movies", query, &items); err != nil {
+			t.Fatalf("Query: %v", err)
+		}
+		// Items with the same index key come back in no particular order.
+		got = titles(items)
+		sort.Strings(got)
+		if !reflect.DeepEqual(got, []string{"Inception", "Shutter Island"}) {
+			t.Errorf("thrillers = %v, want [Inception Shutter Island]", got)
+		}
+		update("REMOVE #genre")
+		items = nil
+		if _, err := db.Query(ctx, "Movies", query, &items); err != nil {
+			t.Fatalf("Query: %v", err)
+		}
+		if got := titles(items); !reflect.DeepEqual(got, []string{"Shutter Island"}) {
+			t.Errorf("thrillers after REMOVE = %v, want [Shutter Island]", got)
+		}
+
+		if _, err := db.Query(ctx, "Movies", dynamodbClient.QueryRequest{IndexName: "missing", KeyCondition: query.KeyCondition,
+			Names: query.Names, Values: query.Values}, &items); !errors.Is(err, dynamodbClient.ErrValidation) {
+			t.Errorf("Query of a missing index = %v, want ErrValidation", err)
+		}
+	})
+}
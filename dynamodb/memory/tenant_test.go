@@ -0,0 +1,57 @@
+package memory_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"dytest/config"
+	dynamodbClient "dytest/dynamodb"
+	"dytest/dynamodb/memory"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+func TestTenantTables(t *testing.T) {
+	db := memory.New(memory.WithTenantTables(map[string]config.TenantTableConfig{
+		"Movies": {Mode: dynamodbClient.TenantModeTable},
+	}))
+	acme := dynamodbClient.WithTenant(context.Background(), "acme")
+	globex := dynamodbClient.WithTenant(context.Background(), "globex")
+
+	err := db.CreateTable(acme, "Movies", &dynamodb.CreateTableInput{
+		AttributeDefinitions: []types.AttributeDefinition{{AttributeName: aws.String("title"), AttributeType: types.ScalarAttributeTypeS}},
+		KeySchema:            []types.KeySchemaElement{{AttributeName: aws.String("title"), KeyType: types.KeyTypeHash}},
+	})
+	if err != nil {
+		t.Fatalf("CreateTable: %v", err)
+	}
+
+	description, err := db.DescribeTable(acme, "Movies")
+	if err != nil {
+		t.Fatalf("DescribeTable: %v", err)
+	}
+	if aws.ToString(description.TableName) != "Movies" {
+		t.Errorf("DescribeTable named the table %q, want the logical name Movies", aws.ToString(description.TableName))
+	}
+	if tables, _ := db.ListTables(acme); len(tables) != 1 || tables[0] != "Movies_acme" {
+		t.Errorf("ListTables = %v, want [Movies_acme]", tables)
+	}
+
+	var notFound *types.ResourceNotFoundException
+	if _, err := db.DescribeTable(globex, "Movies"); !errors.As(err, &notFound) {
+		t.Errorf("DescribeTable for another tenant = %v, want ResourceNotFoundException", err)
+	}
+	if _, err := db.DescribeTable(context.Background(), "Movies"); !errors.Is(err, dynamodbClient.ErrTenantRequired) {
+		t.Errorf("DescribeTable without a tenant = %v, want ErrTenantRequired", err)
+	}
+
+	if err := db.DeleteTable(acme, "Movies"); err != nil {
+		t.Fatalf("DeleteTable: %v", err)
+	}
+	if tables, _ := db.ListTables(acme); len(tables) != 0 {
+		t.Errorf("ListTables after DeleteTable = %v", tables)
+	}
+}
@@ -0,0 +1,25 @@
+package dynamodbClient
+
+import "strings"
+
+// TableNameResolver maps the logical table names used by models and controllers
+// to the physical names stored in DynamoDB.
+type TableNameResolver struct {
+	Prefix string
+	Suffix string
+}
+
+func (r TableNameResolver) Physical(tableName string) string {
+	return r.Prefix + tableName + r.Suffix
+}
+
+// Logical reverses Physical. It reports false for tables outside the namespace.
+func (r TableNameResolver) Logical(physical string) (string, bool) {
+	if !strings.HasPrefix(physical, r.Prefix) || !strings.HasSuffix(physical, r.Suffix) {
+		return "", false
+	}
+	if len(physical) <= len(r.Prefix)+len(r.Suffix) {
+		return "", false
+	}
+	return physical[len(r.Prefix) : len(physical)-len(r.Suffix)], true
+}
@@ -0,0 +1,104 @@
+package dynamodbClient
+
+import (
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+// WriteOption adds a condition or expression names to a put, update or delete.
+type WriteOption func(*writeOptions) error
+
+type writeOptions struct {
+	condition string
+	names     map[string]string
+	values    map[string]types.AttributeValue
+}
+
+// IfNotExists only writes when no item with the same key exists. attributeName is any key attribute.
+func IfNotExists(attributeName string) WriteOption {
+	return Condition("attribute_not_exists(#condKey)", map[string]string{"#condKey": attributeName}, nil)
+}
+
+// IfExists only writes when the item exists. attributeName is any key attribute.
+func IfExists(attributeName string) WriteOption {
+	return Condition("attribute_exists(#condKey)", map[string]string{"#condKey": attributeName}, nil)
+}
+
+// Condition adds a condition expression; multiple conditions are ANDed.
+func Condition(expression string, names map[string]string, values map[string]any) WriteOption {
+	return func(o *writeOptions) error {
+		if o.condition == "" {
+			o.condition = expression
+		} else {
+			o.condition = "(" + o.condition + ") AND (" + expression + ")"
+		}
+		if err := ExpressionNames(names)(o); err != nil {
+			return err
+		}
+		for placeholder, value := range values {
+			av, err := attributevalue.Marshal(value)
+			if err != nil {
+				return err
+			}
+			if o.values == nil {
+				o.values = map[string]types.AttributeValue{}
+			}
+			o.values[placeholder] = av
+		}
+		return nil
+	}
+}
+
+// ExpressionNames provides #placeholders for the update expression.
+func ExpressionNames(names map[string]string) WriteOption {
+	return func(o *writeOptions) error {
+		for placeholder, name := range names {
+			if o.names == nil {
+				o.names = map[string]string{}
+			}
+			o.names[placeholder] = name
+		}
+		return nil
+	}
+}
+
+// WriteCondition returns the condition expression opts add up to, with its names and values,
+// for clients that evaluate writes themselves such as the in-memory one.
+func WriteCondition(opts ...WriteOption) (string, map[string]string, map[string]types.AttributeValue, error) {
+	o, err := applyWriteOptions(opts)
+	if err != nil {
+		return "", nil, nil, err
+	}
+	return o.condition, o.names, o.values, nil
+}
+
+func applyWriteOptions(opts []WriteOption) (*writeOptions, error) {
+	o := &writeOptions{}
+	for _, opt := range opts {
+		if err := opt(o); err != nil {
+			return nil, err
+		}
+	}
+	return o, nil
+}
+
+func (o *writeOptions) conditionExpression() *string {
+	if o.condition == "" {
+		return nil
+	}
+	return &o.condition
+}
+
+// mergeValues adds the condition's values to the update expression's values.
+func (o *writeOptions) mergeValues(values map[string]types.AttributeValue) map[string]types.AttributeValue {
+	if len(o.values) == 0 {
+		return values
+	}
+	if values == nil {
+		values = map[string]types.AttributeValue{}
+	}
+	for placeholder, av := range o.values {
+		values[placeholder] = av
+	}
+	return values
+}
@@ -0,0 +1,42 @@
+package dynamodbClient
+
+import (
+	"context"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+// OutboxFunc builds the outbox record for a pending write. It returns a nil record for writes
+// that need no notification, e.g. writes to tables other than the ones it publishes.
+type OutboxFunc func(ctx context.Context, event WriteEvent) (tableName string, record any, err error)
+
+// WithOutbox writes an outbox record in the same transaction as every put, update and delete.
+// Batch writes that need records are sent as one transaction per chunk instead.
+func WithOutbox(outbox OutboxFunc) Option {
+	return func(c *DynamodbClientImpl) {
+		c.outbox = outbox
+	}
+}
+
+// outboxItem returns the Put of the outbox record for event, or nil when none is needed.
+func (c *DynamodbClientImpl) outboxItem(ctx context.Context, event WriteEvent) (*types.TransactWriteItem, error) {
+	if c.outbox == nil {
+		return nil, nil
+	}
+	tableName, record, err := c.outbox(ctx, event)
+	if err != nil || record == nil {
+		return nil, err
+	}
+	av, err := attributevalue.MarshalMap(record)
+	if err != nil {
+		return nil, err
+	}
+	return &types.TransactWriteItem{
+		Put: &types.Put{
+			TableName: aws.String(c.tableNames.Physical(tableName)),
+			Item:      av,
+		},
+	}, nil
+}
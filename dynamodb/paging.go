@@ -0,0 +1,274 @@
+package dynamodbClient
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"regexp"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+// QueryRequest describes one page of a query. Names and Values hold the #name and :value
+// placeholders of all expressions; Cursor is the value returned for the previous page.
+type QueryRequest struct {
+	IndexName    string
+	KeyCondition string
+	Filter       string
+	Projection   string
+	Names        map[string]string
+	Values       map[string]any
+	Limit        int32
+	Cursor       string
+	Descending   bool
+}
+
+// ScanRequest describes one page of a scan, see QueryRequest.
+type ScanRequest struct {
+	IndexName  string
+	Filter     string
+	Projection string
+	Names      map[string]string
+	Values     map[string]any
+	Limit      int32
+	Cursor     string
+}
+
+var equalityPattern = regexp.MustCompile(`(#?[A-Za-z0-9_]+)\s*=\s*(:[A-Za-z0-9_]+)`)
+
+// use case When a single item is read by its full key. Returns ErrNotFound when there is no such item.
+func (c *DynamodbClientImpl) GetItem(ctx context.Context, tableName string, key map[string]types.AttributeValue, result any) error {
+	physical, err := c.physicalTable(ctx, tableName)
+	if err != nil {
+		return err
+	}
+	scopedKey, err := c.scopeItem(ctx, tableName, key)
+	if err != nil {
+		return err
+	}
+
+	output, err := c.serviceClient.GetItem(ctx, &dynamodb.GetItemInput{
+		TableName: aws.String(physical),
+		Key:       scopedKey,
+	})
+	if err != nil {
+		return translateError(err)
+	}
+	if output.Item == nil || c.expired(tableName, output.Item) {
+		return ErrNotFound
+	}
+	if err := c.unscopeItem(ctx, tableName, output.Item); err != nil {
+		return err
+	}
+	return attributevalue.UnmarshalMap(output.Item, result)
+}
+
+// use case When reading the items of one partition, optionally narrowed by the sort key.
+// Returns the cursor of the next page, empty on the last page.
+func (c *DynamodbClientImpl) Query(ctx context.Context, tableName string, query QueryRequest, result any) (string, error) {
+	physical, err := c.physicalTable(ctx, tableName)
+	if err != nil {
+		return "", err
+	}
+	values, err := marshalValues(query.Values)
+	if err != nil {
+		return "", err
+	}
+	startKey, err := DecodeCursor(query.Cursor)
+	if err != nil {
+		return "", err
+	}
+	if err := c.scopeKeyCondition(ctx, tableName, query.KeyCondition, query.Names, values); err != nil {
+		return "", err
+	}
+
+	input := &dynamodb.QueryInput{
+		TableName:                 aws.String(physical),
+		KeyConditionExpression:    aws.String(query.KeyCondition),
+		ExpressionAttributeNames:  query.Names,
+		ExpressionAttributeValues: values,
+		ExclusiveStartKey:         startKey,
+		ScanIndexForward:          aws.Bool(!query.Descending),
+	}
+	if query.IndexName != "" {
+		input.IndexName = aws.String(query.IndexName)
+	}
+	if query.Filter != "" {
+		input.FilterExpression = aws.String(query.Filter)
+	}
+	if query.Projection != "" {
+		input.ProjectionExpression = aws.String(query.Projection)
+	}
+	if query.Limit > 0 {
+		input.Limit = aws.Int32(query.Limit)
+	}
+
+	output, err := c.serviceClient.Query(ctx, input)
+	if err != nil {
+		return "", translateError(err)
+	}
+	return c.page(ctx, tableName, output.Items, output.LastEvaluatedKey, result)
+}
+
+// use case When paging through a whole table, e.g. for listings or exports.
+func (c *DynamodbClientImpl) ScanPage(ctx context.Context, tableName string, scan ScanRequest, result any) (string, error) {
+	physical, err := c.physicalTable(ctx, tableName)
+	if err != nil {
+		return "", err
+	}
+	values, err := marshalValues(scan.Values)
+	if err != nil {
+		return "", err
+	}
+	startKey, err := DecodeCursor(scan.Cursor)
+	if err != nil {
+		return "", err
+	}
+
+	input := &dynamodb.ScanInput{
+		TableName:                 aws.String(physical),
+		ExpressionAttributeNames:  scan.Names,
+		ExpressionAttributeValues: values,
+		ExclusiveStartKey:         startKey,
+	}
+	if scan.IndexName != "" {
+		input.IndexName = aws.String(scan.IndexName)
+	}
+	if scan.Filter != "" {
+		input.FilterExpression = aws.String(scan.Filter)
+	}
+	if scan.Projection != "" {
+		input.ProjectionExpression = aws.String(scan.Projection)
+	}
+	if scan.Limit > 0 {
+		input.Limit = aws.Int32(scan.Limit)
+	}
+	if err := c.scopeScan(ctx, tableName, input); err != nil {
+		return "", err
+	}
+	c.filterExpiredScan(tableName, input)
+
+	output, err := c.serviceClient.Scan(ctx, input)
+	if err != nil {
+		return "", translateError(err)
+	}
+	return c.page(ctx, tableName, output.Items, output.LastEvaluatedKey, result)
+}
+
+func (c *DynamodbClientImpl) page(ctx context.Context, tableName string, items []map[string]types.AttributeValue, lastKey map[string]types.AttributeValue, result any) (string, error) {
+	items = c.withoutExpired(tableName, items)
+	for _, item := range items {
+		if err := c.unscopeItem(ctx, tableName, item); err != nil {
+			return "", err
+		}
+	}
+	if err := attributevalue.UnmarshalListOfMaps(items, result); err != nil {
+		return "", err
+	}
+	return EncodeCursor(lastKey)
+}
+
+// scopeKeyCondition prefixes the partition key value of a query in tenant prefix mode.
+func (c *DynamodbClientImpl) scopeKeyCondition(ctx context.Context, tableName, keyCondition string, names map[string]string, values map[string]types.AttributeValue) error {
+	table, tenantID, scoped, err := c.tenantTable(ctx, tableName)
+	if err != nil || !scoped || table.Mode != TenantModePrefix {
+		return err
+	}
+
+	for _, match := range equalityPattern.FindAllStringSubmatch(keyCondition, -1) {
+		name := match[1]
+		if placeholder, ok := names[name]; ok {
+			name = placeholder
+		}
+		if name != table.PartitionKey {
+			continue
+		}
+		value, ok := values[match[2]].(*types.AttributeValueMemberS)
+		if !ok {
+			return fmt.Errorf("tenant partition key `%s` of table `%s` must be a string", table.PartitionKey, tableName)
+		}
+		values[match[2]] = &types.AttributeValueMemberS{Value: tenantID + tenantSeparator + value.Value}
+		return nil
+	}
+	return fmt.Errorf("query on tenant table `%s` needs an equality condition on `%s`", tableName, table.PartitionKey)
+}
+
+func marshalValues(values map[string]any) (map[string]types.AttributeValue, error) {
+	if len(values) == 0 {
+		return nil, nil
+	}
+	avs := make(map[string]types.AttributeValue, len(values))
+	for placeholder, value := range values {
+		av, err := attributevalue.Marshal(value)
+		if err != nil {
+			return nil, err
+		}
+		avs[placeholder] = av
+	}
+	return avs, nil
+}
+
+// cursorValue keeps the type of a key attribute; keys are always strings, numbers or binary.
+type cursorValue struct {
+	S *string `json:"s,omitempty"`
+	N *string `json:"n,omitempty"`
+	B []byte  `json:"b,omitempty"`
+}
+
+// EncodeCursor turns a LastEvaluatedKey into the cursor Query and ScanPage return; DecodeCursor turns it back.
+func EncodeCursor(key map[string]types.AttributeValue) (string, error) {
+	if len(key) == 0 {
+		return "", nil
+	}
+	values := map[string]cursorValue{}
+	for name, av := range key {
+		switch v := av.(type) {
+		case *types.AttributeValueMemberS:
+			values[name] = cursorValue{S: &v.Value}
+		case *types.AttributeValueMemberN:
+			values[name] = cursorValue{N: &v.Value}
+		case *types.AttributeValueMemberB:
+			values[name] = cursorValue{B: v.Value}
+		default:
+			return "", fmt.Errorf("unsupported key attribute type %T", av)
+		}
+	}
+	data, err := json.Marshal(values)
+	if err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(data), nil
+}
+
+func DecodeCursor(cursor string) (map[string]types.AttributeValue, error) {
+	if cursor == "" {
+		return nil, nil
+	}
+	data, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+	var values map[string]cursorValue
+	if err := json.Unmarshal(data, &values); err != nil {
+		return nil, ErrInvalidCursor
+	}
+
+	key := map[string]types.AttributeValue{}
+	for name, v := range values {
+		switch {
+		case v.S != nil:
+			key[name] = &types.AttributeValueMemberS{Value: *v.S}
+		case v.N != nil:
+			key[name] = &types.AttributeValueMemberN{Value: *v.N}
+		case v.B != nil:
+			key[name] = &types.AttributeValueMemberB{Value: v.B}
+		default:
+			return nil, ErrInvalidCursor
+		}
+	}
+	return key, nil
+}
@@ -0,0 +1,205 @@
+package dynamodbClient
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+// Statement is a PartiQL statement with its ? parameters as plain Go values.
+type Statement struct {
+	Statement string
+	Params    []any
+}
+
+// StatementResult is the outcome of one statement of a batch.
+type StatementResult struct {
+	Item map[string]types.AttributeValue
+	Err  error
+}
+
+func (r StatementResult) Unmarshal(out any) error {
+	return attributevalue.UnmarshalMap(r.Item, out)
+}
+
+// tableRefPattern finds the table after FROM, INTO and UPDATE, quoted or not.
+var tableRefPattern = regexp.MustCompile(`(?i)\b(FROM|INTO|UPDATE)(\s+)"?([A-Za-z0-9_.-]+?)"?(\s|\.|;|$)`)
+
+// TablesInStatement returns the logical table names a statement refers to.
+func TablesInStatement(statement string) []string {
+	var tableNames []string
+	outsideStrings(statement, func(code string) string {
+		for _, match := range tableRefPattern.FindAllStringSubmatch(code, -1) {
+			tableNames = append(tableNames, match[3])
+		}
+		return code
+	})
+	return tableNames
+}
+
+// outsideStrings applies fn to the parts of a statement that are not in '...' string literals.
+func outsideStrings(statement string, fn func(code string) string) string {
+	var out strings.Builder
+	start, inString := 0, false
+	for i := 0; i < len(statement); i++ {
+		if statement[i] != '\'' {
+			continue
+		}
+		if inString {
+			if i+1 < len(statement) && statement[i+1] == '\'' {
+				i++ // '' is an escaped quote
+				continue
+			}
+			out.WriteString(statement[start : i+1])
+			start = i + 1
+		} else {
+			out.WriteString(fn(statement[start:i]))
+			start = i
+		}
+		inString = !inString
+	}
+	if inString {
+		out.WriteString(statement[start:])
+	} else {
+		out.WriteString(fn(statement[start:]))
+	}
+	return out.String()
+}
+
+// rewriteStatement swaps logical table names for physical ones. Tenant-scoped tables are
+// refused because PartiQL statements can't be scoped reliably.
+func (c *DynamodbClientImpl) rewriteStatement(statement string) (string, error) {
+	for _, tableName := range TablesInStatement(statement) {
+		if _, ok := c.tenantTables[tableName]; ok {
+			return "", fmt.Errorf("table `%s` is tenant-scoped and cannot be used with PartiQL", tableName)
+		}
+	}
+
+	return outsideStrings(statement, func(code string) string {
+		return tableRefPattern.ReplaceAllStringFunc(code, func(ref string) string {
+			match := tableRefPattern.FindStringSubmatch(ref)
+			return match[1] + match[2] + `"` + c.tableNames.Physical(match[3]) + `"` + match[4]
+		})
+	}), nil
+}
+
+func (c *DynamodbClientImpl) statementInput(statement Statement) (string, []types.AttributeValue, error) {
+	rewritten, err := c.rewriteStatement(statement.Statement)
+	if err != nil {
+		return "", nil, err
+	}
+
+	var params []types.AttributeValue
+	for _, param := range statement.Params {
+		av, err := attributevalue.Marshal(param)
+		if err != nil {
+			return "", nil, err
+		}
+		params = append(params, av)
+	}
+	return rewritten, params, nil
+}
+
+// filterStatementItems drops expired items of the statement's table.
+func (c *DynamodbClientImpl) filterStatementItems(statement string, items []map[string]types.AttributeValue) []map[string]types.AttributeValue {
+	for _, tableName := range TablesInStatement(statement) {
+		items = c.withoutExpired(tableName, items)
+	}
+	return items
+}
+
+// use case When a query is easier to express in SQL; all result pages are unmarshalled into result, a pointer to a slice.
+// Writes made through PartiQL do not run write hooks or the outbox.
+func (c *DynamodbClientImpl) ExecuteStatement(ctx context.Context, statement string, params []any, result any) error {
+	rewritten, avParams, err := c.statementInput(Statement{Statement: statement, Params: params})
+	if err != nil {
+		return err
+	}
+
+	input := &dynamodb.ExecuteStatementInput{
+		Statement:  aws.String(rewritten),
+		Parameters: avParams,
+	}
+
+	var items []map[string]types.AttributeValue
+	for {
+		output, err := c.serviceClient.ExecuteStatement(ctx, input)
+		if err != nil {
+			return err
+		}
+		items = append(items, output.Items...)
+		if output.NextToken == nil {
+			break
+		}
+		input.NextToken = output.NextToken
+	}
+
+	return attributevalue.UnmarshalListOfMaps(c.filterStatementItems(statement, items), result)
+}
+
+func (c *DynamodbClientImpl) BatchExecuteStatement(ctx context.Context, statements []Statement) ([]StatementResult, error) {
+	input := &dynamodb.BatchExecuteStatementInput{}
+	for _, statement := range statements {
+		rewritten, params, err := c.statementInput(statement)
+		if err != nil {
+			return nil, err
+		}
+		input.Statements = append(input.Statements, types.BatchStatementRequest{
+			Statement:  aws.String(rewritten),
+			Parameters: params,
+		})
+	}
+
+	output, err := c.serviceClient.BatchExecuteStatement(ctx, input)
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]StatementResult, len(output.Responses))
+	for i, response := range output.Responses {
+		if items := c.filterStatementItems(statements[i].Statement, []map[string]types.AttributeValue{response.Item}); len(items) > 0 {
+			results[i].Item = items[0]
+		}
+		if response.Error != nil {
+			results[i].Err = fmt.Errorf("%s: %s", response.Error.Code, aws.ToString(response.Error.Message))
+		}
+	}
+	return results, nil
+}
+
+// ExecuteTransaction runs all statements atomically. Items returned by reads are unmarshalled into result.
+func (c *DynamodbClientImpl) ExecuteTransaction(ctx context.Context, statements []Statement, result any) error {
+	input := &dynamodb.ExecuteTransactionInput{}
+	for _, statement := range statements {
+		rewritten, params, err := c.statementInput(statement)
+		if err != nil {
+			return err
+		}
+		input.TransactStatements = append(input.TransactStatements, types.ParameterizedStatement{
+			Statement:  aws.String(rewritten),
+			Parameters: params,
+		})
+	}
+
+	output, err := c.serviceClient.ExecuteTransaction(ctx, input)
+	if err != nil {
+		return err
+	}
+	if result == nil {
+		return nil
+	}
+
+	var items []map[string]types.AttributeValue
+	for _, response := range output.Responses {
+		if response.Item != nil {
+			items = append(items, response.Item)
+		}
+	}
+	return attributevalue.UnmarshalListOfMaps(items, result)
+}
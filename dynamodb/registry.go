@@ -0,0 +1,331 @@
+package dynamodbClient
+
+import (
+	"context"
+	appConfig "dytest/config"
+	"dytest/model"
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+const DefaultClientName = "default"
+
+// Registry holds named clients and routes every call to the client its table is mapped to,
+// so callers can use it as a plain DynamodbClient without knowing where a table lives.
+type Registry struct {
+	mu      sync.RWMutex
+	clients map[string]DynamodbClient
+	tables  map[string]string
+}
+
+// NewRegistry creates the default and named clients from cfg. opts are applied to every client.
+func NewRegistry(ctx context.Context, cfg *appConfig.Config, opts ...Option) (*Registry, error) {
+	r := &Registry{
+		clients: map[string]DynamodbClient{},
+		tables:  map[string]string{},
+	}
+	opts = append([]Option{WithTenantTables(cfg.Tenant.Tables)}, opts...)
+
+	client, err := NewDynamodbClient(ctx, cfg.DynamoDB, opts...)
+	if err != nil {
+		return nil, err
+	}
+	r.Register(DefaultClientName, client)
+
+	for name, clientCfg := range cfg.Clients {
+		client, err := NewDynamodbClient(ctx, clientCfg, opts...)
+		if err != nil {
+			return nil, fmt.Errorf("client `%s`: %v", name, err)
+		}
+		r.Register(name, client)
+	}
+
+	for tableName, clientName := range cfg.Tables {
+		if err := r.MapTable(tableName, clientName); err != nil {
+			return nil, err
+		}
+	}
+	return r, nil
+}
+
+func (r *Registry) Register(name string, client DynamodbClient) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.clients[name] = client
+}
+
+func (r *Registry) MapTable(tableName, clientName string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.clients[clientName]; !ok {
+		return fmt.Errorf("table `%s` is mapped to unknown client `%s`", tableName, clientName)
+	}
+	r.tables[tableName] = clientName
+	return nil
+}
+
+func (r *Registry) Client(name string) (DynamodbClient, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	client, ok := r.clients[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown client `%s`", name)
+	}
+	return client, nil
+}
+
+func (r *Registry) ForTable(tableName string) (DynamodbClient, error) {
+	r.mu.RLock()
+	clientName, ok := r.tables[tableName]
+	r.mu.RUnlock()
+	if !ok {
+		clientName = DefaultClientName
+	}
+	return r.Client(clientName)
+}
+
+func (r *Registry) ForModel(m model.Table) (DynamodbClient, error) {
+	return r.ForTable(m.TableName())
+}
+
+func (r *Registry) names() []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	names := make([]string, 0, len(r.clients))
+	for name := range r.clients {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func (r *Registry) TransactGetItem(ctx context.Context, tableName string, key map[string]types.AttributeValue, result any) (*dynamodb.TransactGetItemsOutput, error) {
+	client, err := r.ForTable(tableName)
+	if err != nil {
+		return nil, err
+	}
+	return client.TransactGetItem(ctx, tableName, key, result)
+}
+
+func (r *Registry) Scan(ctx context.Context, tableName string, result any) (*dynamodb.ScanOutput, error) {
+	client, err := r.ForTable(tableName)
+	if err != nil {
+		return nil, err
+	}
+	return client.Scan(ctx, tableName, result)
+}
+
+func (r *Registry) GetItem(ctx context.Context, tableName string, key map[string]types.AttributeValue, result any) error {
+	client, err := r.ForTable(tableName)
+	if err != nil {
+		return err
+	}
+	return client.GetItem(ctx, tableName, key, result)
+}
+
+func (r *Registry) Query(ctx context.Context, tableName string, query QueryRequest, result any) (string, error) {
+	client, err := r.ForTable(tableName)
+	if err != nil {
+		return "", err
+	}
+	return client.Query(ctx, tableName, query, result)
+}
+
+func (r *Registry) ScanPage(ctx context.Context, tableName string, scan ScanRequest, result any) (string, error) {
+	client, err := r.ForTable(tableName)
+	if err != nil {
+		return "", err
+	}
+	return client.ScanPage(ctx, tableName, scan, result)
+}
+
+func (r *Registry) TransactWriteItems(ctx context.Context, tableName string, body any, opts ...WriteOption) error {
+	client, err := r.ForTable(tableName)
+	if err != nil {
+		return err
+	}
+	return client.TransactWriteItems(ctx, tableName, body, opts...)
+}
+
+func (r *Registry) DeleteItem(ctx context.Context, tableName string, key map[string]types.AttributeValue, opts ...WriteOption) error {
+	client, err := r.ForTable(tableName)
+	if err != nil {
+		return err
+	}
+	return client.DeleteItem(ctx, tableName, key, opts...)
+}
+
+func (r *Registry) UpdateItem(ctx context.Context, tableName string, key map[string]types.AttributeValue, updateExpression string, requestBody any, opts ...WriteOption) error {
+	client, err := r.ForTable(tableName)
+	if err != nil {
+		return err
+	}
+	return client.UpdateItem(ctx, tableName, key, updateExpression, requestBody, opts...)
+}
+
+// Ping checks every registered client.
+func (r *Registry) Ping(ctx context.Context) error {
+	for _, name := range r.names() {
+		client, err := r.Client(name)
+		if err != nil {
+			return err
+		}
+		if err := client.Ping(ctx); err != nil {
+			return fmt.Errorf("client `%s`: %v", name, err)
+		}
+	}
+	return nil
+}
+
+// HealthCheck pings every registered client and checks each table on the client it is mapped to.
+func (r *Registry) HealthCheck(ctx context.Context, tableNames ...string) error {
+	if err := r.Ping(ctx); err != nil {
+		return err
+	}
+	for _, tableName := range tableNames {
+		client, err := r.ForTable(tableName)
+		if err != nil {
+			return err
+		}
+		if err := client.HealthCheck(ctx, tableName); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *Registry) BatchGetItem(ctx context.Context, tableName string, keys []map[string]types.AttributeValue, result any) error {
+	client, err := r.ForTable(tableName)
+	if err != nil {
+		return err
+	}
+	return client.BatchGetItem(ctx, tableName, keys, result)
+}
+
+func (r *Registry) BatchWriteItem(ctx context.Context, tableName string, items []any) error {
+	client, err := r.ForTable(tableName)
+	if err != nil {
+		return err
+	}
+	return client.BatchWriteItem(ctx, tableName, items)
+}
+
+// ListTables returns the tables of every client. A table is only listed when it lives
+// on the client it is mapped to.
+func (r *Registry) ListTables(ctx context.Context) ([]string, error) {
+	var tableNames []string
+	for _, name := range r.names() {
+		client, err := r.Client(name)
+		if err != nil {
+			return nil, err
+		}
+		names, err := client.ListTables(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("client `%s`: %v", name, err)
+		}
+		for _, tableName := range names {
+			if owner, _ := r.ForTable(tableName); owner == client {
+				tableNames = append(tableNames, tableName)
+			}
+		}
+	}
+	sort.Strings(tableNames)
+	return tableNames, nil
+}
+
+func (r *Registry) DescribeTable(ctx context.Context, tableName string) (*types.TableDescription, error) {
+	client, err := r.ForTable(tableName)
+	if err != nil {
+		return nil, err
+	}
+	return client.DescribeTable(ctx, tableName)
+}
+
+func (r *Registry) CreateTable(ctx context.Context, tableName string, input *dynamodb.CreateTableInput) error {
+	client, err := r.ForTable(tableName)
+	if err != nil {
+		return err
+	}
+	return client.CreateTable(ctx, tableName, input)
+}
+
+func (r *Registry) DeleteTable(ctx context.Context, tableName string) error {
+	client, err := r.ForTable(tableName)
+	if err != nil {
+		return err
+	}
+	return client.DeleteTable(ctx, tableName)
+}
+
+func (r *Registry) UpdateTimeToLive(ctx context.Context, tableName string, attributeName string, enabled bool) error {
+	client, err := r.ForTable(tableName)
+	if err != nil {
+		return err
+	}
+	return client.UpdateTimeToLive(ctx, tableName, attributeName, enabled)
+}
+
+func (r *Registry) DescribeTimeToLive(ctx context.Context, tableName string) (*types.TimeToLiveDescription, error) {
+	client, err := r.ForTable(tableName)
+	if err != nil {
+		return nil, err
+	}
+	return client.DescribeTimeToLive(ctx, tableName)
+}
+
+// forStatements picks the client of the tables the statements refer to. All of them must live on the same client.
+func (r *Registry) forStatements(statements ...string) (DynamodbClient, error) {
+	var client DynamodbClient
+	for _, statement := range statements {
+		for _, tableName := range TablesInStatement(statement) {
+			owner, err := r.ForTable(tableName)
+			if err != nil {
+				return nil, err
+			}
+			if client != nil && owner != client {
+				return nil, fmt.Errorf("statements refer to tables on different clients")
+			}
+			client = owner
+		}
+	}
+	if client == nil {
+		return r.Client(DefaultClientName)
+	}
+	return client, nil
+}
+
+func (r *Registry) ExecuteStatement(ctx context.Context, statement string, params []any, result any) error {
+	client, err := r.forStatements(statement)
+	if err != nil {
+		return err
+	}
+	return client.ExecuteStatement(ctx, statement, params, result)
+}
+
+func (r *Registry) BatchExecuteStatement(ctx context.Context, statements []Statement) ([]StatementResult, error) {
+	client, err := r.forStatements(statementTexts(statements)...)
+	if err != nil {
+		return nil, err
+	}
+	return client.BatchExecuteStatement(ctx, statements)
+}
+
+func (r *Registry) ExecuteTransaction(ctx context.Context, statements []Statement, result any) error {
+	client, err := r.forStatements(statementTexts(statements)...)
+	if err != nil {
+		return err
+	}
+	return client.ExecuteTransaction(ctx, statements, result)
+}
+
+func statementTexts(statements []Statement) []string {
+	texts := make([]string, len(statements))
+	for i, statement := range statements {
+		texts[i] = statement.Statement
+	}
+	return texts
+}
@@ -0,0 +1,90 @@
+package dynamodbClient
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+// MaxItemSize is DynamoDB's limit on the size of a single item, attribute names included.
+const MaxItemSize = 400 * 1024
+
+var ErrItemTooLarge = errors.New("item too large")
+
+type ItemTooLargeError struct {
+	TableName string
+	Size      int
+}
+
+func (e *ItemTooLargeError) Error() string {
+	return fmt.Sprintf("item for `%s` is %d bytes, over DynamoDB's limit of %d bytes", e.TableName, e.Size, MaxItemSize)
+}
+
+func (e *ItemTooLargeError) Is(target error) bool {
+	return target == ErrItemTooLarge
+}
+
+// ItemSize estimates the stored size of an item the way DynamoDB bills it:
+// attribute names plus values, with a few bytes of overhead for lists and maps.
+func ItemSize(item map[string]types.AttributeValue) int {
+	size := 0
+	for name, value := range item {
+		size += len(name) + attributeSize(value)
+	}
+	return size
+}
+
+func attributeSize(value types.AttributeValue) int {
+	switch v := value.(type) {
+	case *types.AttributeValueMemberS:
+		return len(v.Value)
+	case *types.AttributeValueMemberN:
+		return numberSize(v.Value)
+	case *types.AttributeValueMemberB:
+		return len(v.Value)
+	case *types.AttributeValueMemberBOOL, *types.AttributeValueMemberNULL:
+		return 1
+	case *types.AttributeValueMemberSS:
+		size := 0
+		for _, s := range v.Value {
+			size += len(s)
+		}
+		return size
+	case *types.AttributeValueMemberNS:
+		size := 0
+		for _, n := range v.Value {
+			size += numberSize(n)
+		}
+		return size
+	case *types.AttributeValueMemberBS:
+		size := 0
+		for _, b := range v.Value {
+			size += len(b)
+		}
+		return size
+	case *types.AttributeValueMemberL:
+		size := 3
+		for _, element := range v.Value {
+			size += 1 + attributeSize(element)
+		}
+		return size
+	case *types.AttributeValueMemberM:
+		return 3 + len(v.Value) + ItemSize(v.Value)
+	}
+	return 0
+}
+
+// numberSize is roughly one byte per two significant digits, plus one.
+func numberSize(n string) int {
+	digits := strings.TrimLeft(strings.TrimLeft(n, "-+"), "0.")
+	return (len(digits)+1)/2 + 1
+}
+
+func checkItemSize(tableName string, item map[string]types.AttributeValue) error {
+	if size := ItemSize(item); size > MaxItemSize {
+		return &ItemTooLargeError{TableName: tableName, Size: size}
+	}
+	return nil
+}
@@ -0,0 +1,81 @@
+package dynamodbClient_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+
+	"dytest/config"
+	dynamodbClient "dytest/dynamodb"
+	"dytest/model"
+	"dytest/problem"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+func TestItemSize(t *testing.T) {
+	tests := []struct {
+		name string
+		item map[string]types.AttributeValue
+		size int
+	}{
+		{"string", map[string]types.AttributeValue{"title": &types.AttributeValueMemberS{Value: "Inception"}}, 5 + 9},
+		{"number", map[string]types.AttributeValue{"year": &types.AttributeValueMemberN{Value: "2010"}}, 4 + 3},
+		{"leading zeros", map[string]types.AttributeValue{"n": &types.AttributeValueMemberN{Value: "-0.005"}}, 1 + 2},
+		{"bool", map[string]types.AttributeValue{"seen": &types.AttributeValueMemberBOOL{Value: true}}, 4 + 1},
+		{"null", map[string]types.AttributeValue{"gone": &types.AttributeValueMemberNULL{Value: true}}, 4 + 1},
+		{"binary", map[string]types.AttributeValue{"b": &types.AttributeValueMemberB{Value: []byte{1, 2, 3}}}, 1 + 3},
+		{"string set", map[string]types.AttributeValue{"ss": &types.AttributeValueMemberSS{Value: []string{"a", "bc"}}}, 2 + 3},
+		{"list", map[string]types.AttributeValue{"l": &types.AttributeValueMemberL{Value: []types.AttributeValue{
+			&types.AttributeValueMemberS{Value: "a"},
+			&types.AttributeValueMemberS{Value: "bc"},
+		}}}, 1 + 3 + (1 + 1) + (1 + 2)},
+		{"map", map[string]types.AttributeValue{"info": &types.AttributeValueMemberM{Value: map[string]types.AttributeValue{
+			"plot": &types.AttributeValueMemberS{Value: "xyz"},
+		}}}, 4 + 3 + 1 + (4 + 3)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := dynamodbClient.ItemSize(tt.item); got != tt.size {
+				t.Errorf("ItemSize = %d, want %d", got, tt.size)
+			}
+		})
+	}
+}
+
+// TestItemTooLarge checks that a write over the limit is refused before it is sent: the endpoint
+// does not exist.
+func TestItemTooLarge(t *testing.T) {
+	client, err := dynamodbClient.NewDynamodbClient(context.Background(), config.DynamoDBConfig{
+		Endpoint: "http://127.0.0.1:1",
+		Region:   "localhost",
+	})
+	if err != nil {
+		t.Fatalf("NewDynamodbClient: %v", err)
+	}
+
+	// The title, year and info attributes add a few bytes, which tip the plot over the limit.
+	movie := model.MovieItem{
+		Title: "Inception",
+		Year:  2010,
+		Info:  map[string]interface{}{"plot": strings.Repeat("x", dynamodbClient.MaxItemSize-32)},
+	}
+	err = client.TransactWriteItems(context.Background(), "Movies", movie)
+	var tooLarge *dynamodbClient.ItemTooLargeError
+	if !errors.As(err, &tooLarge) || !errors.Is(err, dynamodbClient.ErrItemTooLarge) {
+		t.Fatalf("TransactWriteItems = %v, want ItemTooLargeError", err)
+	}
+	if tooLarge.TableName != "Movies" || tooLarge.Size <= dynamodbClient.MaxItemSize {
+		t.Errorf("ItemTooLargeError = %+v", tooLarge)
+	}
+	if p := problem.From(err); p.Status != http.StatusRequestEntityTooLarge || p.Code != problem.CodeItemTooLarge {
+		t.Errorf("problem = %v, want 413 %s", p, problem.CodeItemTooLarge)
+	}
+
+	err = client.BatchWriteItem(context.Background(), "Movies", []any{movie})
+	if !errors.Is(err, dynamodbClient.ErrItemTooLarge) {
+		t.Errorf("BatchWriteItem = %v, want ErrItemTooLarge", err)
+	}
+}
@@ -0,0 +1,73 @@
+package dynamodbClient
+
+import (
+	"context"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+// ListTables returns the logical names of the tables in this client's namespace.
+func (c *DynamodbClientImpl) ListTables(ctx context.Context) ([]string, error) {
+	var tableNames []string
+	paginator := dynamodb.NewListTablesPaginator(c.serviceClient, &dynamodb.ListTablesInput{})
+	for paginator.HasMorePages() {
+		output, err := paginator.NextPage(ctx)
+		if err != nil {
+			return nil, err
+		}
+		for _, physical := range output.TableNames {
+			if tableName, ok := c.tableNames.Logical(physical); ok {
+				tableNames = append(tableNames, tableName)
+			}
+		}
+	}
+	return tableNames, nil
+}
+
+// DescribeTable, CreateTable and DeleteTable act on the tenant's own table in table mode.
+func (c *DynamodbClientImpl) DescribeTable(ctx context.Context, tableName string) (*types.TableDescription, error) {
+	physical, err := c.adminTable(ctx, tableName)
+	if err != nil {
+		return nil, err
+	}
+	output, err := c.serviceClient.DescribeTable(ctx, &dynamodb.DescribeTableInput{
+		TableName: aws.String(physical),
+	})
+	if err != nil {
+		return nil, err
+	}
+	output.Table.TableName = aws.String(tableName)
+	return output.Table, nil
+}
+
+// CreateTable creates the table and waits until it is ACTIVE. input.TableName is replaced by the physical name.
+func (c *DynamodbClientImpl) CreateTable(ctx context.Context, tableName string, input *dynamodb.CreateTableInput) error {
+	physical, err := c.adminTable(ctx, tableName)
+	if err != nil {
+		return err
+	}
+	input.TableName = aws.String(physical)
+
+	if _, err := c.serviceClient.CreateTable(ctx, input); err != nil {
+		return err
+	}
+
+	waiter := dynamodb.NewTableExistsWaiter(c.serviceClient)
+	return waiter.Wait(ctx, &dynamodb.DescribeTableInput{
+		TableName: aws.String(physical),
+	}, 5*time.Minute)
+}
+
+func (c *DynamodbClientImpl) DeleteTable(ctx context.Context, tableName string) error {
+	physical, err := c.adminTable(ctx, tableName)
+	if err != nil {
+		return err
+	}
+	_, err = c.serviceClient.DeleteTable(ctx, &dynamodb.DeleteTableInput{
+		TableName: aws.String(physical),
+	})
+	return err
+}
@@ -0,0 +1,131 @@
+package dynamodbClient
+
+import (
+	"context"
+	appConfig "dytest/config"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+const (
+	TenantModePrefix = "prefix"
+	TenantModeTable  = "table"
+
+	tenantSeparator = "#"
+)
+
+var (
+	ErrTenantRequired = errors.New("tenant is required for this table")
+	ErrCrossTenant    = errors.New("item belongs to another tenant")
+)
+
+type tenantKey struct{}
+
+func WithTenant(ctx context.Context, tenantID string) context.Context {
+	return context.WithValue(ctx, tenantKey{}, tenantID)
+}
+
+func TenantFromContext(ctx context.Context) (string, bool) {
+	tenantID, ok := ctx.Value(tenantKey{}).(string)
+	return tenantID, ok && tenantID != ""
+}
+
+func (c *DynamodbClientImpl) tenantTable(ctx context.Context, tableName string) (appConfig.TenantTableConfig, string, bool, error) {
+	table, ok := c.tenantTables[tableName]
+	if !ok {
+		return table, "", false, nil
+	}
+	tenantID, ok := TenantFromContext(ctx)
+	if !ok {
+		return table, "", false, fmt.Errorf("table `%s`: %w", tableName, ErrTenantRequired)
+	}
+	return table, tenantID, true, nil
+}
+
+// physicalTable resolves the table name for data operations, including the per-tenant table in table mode.
+func (c *DynamodbClientImpl) physicalTable(ctx context.Context, tableName string) (string, error) {
+	table, tenantID, scoped, err := c.tenantTable(ctx, tableName)
+	if err != nil {
+		return "", err
+	}
+	if scoped && table.Mode == TenantModeTable {
+		return c.tableNames.Physical(tableName + "_" + tenantID), nil
+	}
+	return c.tableNames.Physical(tableName), nil
+}
+
+// adminTable resolves the table name for table-level calls such as creating a table or its TTL
+// settings.
+func (c *DynamodbClientImpl) adminTable(ctx context.Context, tableName string) (string, error) {
+	tenantTableName, err := TenantTableName(ctx, c.tenantTables, tableName)
+	if err != nil {
+		return "", err
+	}
+	return c.tableNames.Physical(tenantTableName), nil
+}
+
+// TenantTableName returns the table that holds a table's items for the tenant in ctx: the
+// <table>_<tenant> table in table mode, which needs a tenant, and the table itself otherwise, as
+// tenants share it in prefix mode.
+func TenantTableName(ctx context.Context, tables map[string]appConfig.TenantTableConfig, tableName string) (string, error) {
+	if table, ok := tables[tableName]; !ok || table.Mode != TenantModeTable {
+		return tableName, nil
+	}
+	tenantID, ok := TenantFromContext(ctx)
+	if !ok {
+		return "", fmt.Errorf("table `%s`: %w", tableName, ErrTenantRequired)
+	}
+	return tableName + "_" + tenantID, nil
+}
+
+// scopeItem returns a copy of a key or item with the tenant prepended to its partition key.
+func (c *DynamodbClientImpl) scopeItem(ctx context.Context, tableName string, item map[string]types.AttributeValue) (map[string]types.AttributeValue, error) {
+	table, tenantID, scoped, err := c.tenantTable(ctx, tableName)
+	if err != nil || !scoped || table.Mode != TenantModePrefix {
+		return item, err
+	}
+
+	value, ok := item[table.PartitionKey].(*types.AttributeValueMemberS)
+	if !ok {
+		return nil, fmt.Errorf("tenant partition key `%s` of table `%s` must be a string", table.PartitionKey, tableName)
+	}
+
+	scopedItem := make(map[string]types.AttributeValue, len(item))
+	for name, av := range item {
+		scopedItem[name] = av
+	}
+	scopedItem[table.PartitionKey] = &types.AttributeValueMemberS{Value: tenantID + tenantSeparator + value.Value}
+	return scopedItem, nil
+}
+
+// unscopeItem strips the tenant from an item read back from DynamoDB and rejects items of other tenants.
+func (c *DynamodbClientImpl) unscopeItem(ctx context.Context, tableName string, item map[string]types.AttributeValue) error {
+	table, tenantID, scoped, err := c.tenantTable(ctx, tableName)
+	if err != nil || !scoped || table.Mode != TenantModePrefix {
+		return err
+	}
+
+	value, ok := item[table.PartitionKey].(*types.AttributeValueMemberS)
+	if !ok || !strings.HasPrefix(value.Value, tenantID+tenantSeparator) {
+		return ErrCrossTenant
+	}
+	item[table.PartitionKey] = &types.AttributeValueMemberS{Value: strings.TrimPrefix(value.Value, tenantID+tenantSeparator)}
+	return nil
+}
+
+// scopeScan restricts a scan to the tenant's partition key range.
+func (c *DynamodbClientImpl) scopeScan(ctx context.Context, tableName string, input *dynamodb.ScanInput) error {
+	table, tenantID, scoped, err := c.tenantTable(ctx, tableName)
+	if err != nil || !scoped || table.Mode != TenantModePrefix {
+		return err
+	}
+
+	addScanFilter(input, "begins_with(#tenantPk, :tenantPrefix)",
+		map[string]string{"#tenantPk": table.PartitionKey},
+		map[string]types.AttributeValue{":tenantPrefix": &types.AttributeValueMemberS{Value: tenantID + tenantSeparator}})
+	return nil
+}
@@ -0,0 +1,168 @@
+package dynamodbClient
+
+import (
+	"context"
+	"dytest/model"
+	"fmt"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+// TTLAttribute returns the attribute name of the time.Time or *time.Time field tagged `ttl:"true"`.
+// The field should also carry the `unixtime` dynamodbav option so it is stored as epoch seconds;
+// TTLAttributes refuses models where it does not.
+func TTLAttribute(v any) (string, bool) {
+	field, ok := ttlField(v)
+	if !ok {
+		return "", false
+	}
+	name, _, _ := strings.Cut(field.Tag.Get("dynamodbav"), ",")
+	if name == "" {
+		name = field.Name
+	}
+	return name, true
+}
+
+func ttlField(v any) (reflect.StructField, bool) {
+	t := reflect.TypeOf(v)
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct {
+		return reflect.StructField{}, false
+	}
+
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		fieldType := field.Type
+		if fieldType.Kind() == reflect.Pointer {
+			fieldType = fieldType.Elem()
+		}
+		if field.Tag.Get("ttl") == "true" && fieldType == reflect.TypeOf(time.Time{}) {
+			return field, true
+		}
+	}
+	return reflect.StructField{}, false
+}
+
+// TTLAttributes maps each model's table to its TTL attribute, skipping models without one.
+// A TTL field without the unixtime option would be stored as a string, which DynamoDB never
+// expires, and is an error.
+func TTLAttributes(models ...model.Table) (map[string]string, error) {
+	attributes := map[string]string{}
+	for _, m := range models {
+		field, ok := ttlField(m)
+		if !ok {
+			continue
+		}
+		_, options, _ := strings.Cut(field.Tag.Get("dynamodbav"), ",")
+		if !slices.Contains(strings.Split(options, ","), "unixtime") {
+			return nil, fmt.Errorf("TTL field %s of table `%s` needs the unixtime dynamodbav option", field.Name, m.TableName())
+		}
+		attributes[m.TableName()], _ = TTLAttribute(m)
+	}
+	return attributes, nil
+}
+
+// WithTTLAttributes tells the client which attribute holds the expiry of each table.
+// With FilterExpired set in the config, reads hide items whose TTL is in the past: DynamoDB
+// deletes expired items lazily, so without it reads may still return them for a while.
+func WithTTLAttributes(ttlAttributes map[string]string) Option {
+	return func(c *DynamodbClientImpl) {
+		c.ttlAttributes = ttlAttributes
+	}
+}
+
+func (c *DynamodbClientImpl) UpdateTimeToLive(ctx context.Context, tableName string, attributeName string, enabled bool) error {
+	physical, err := c.adminTable(ctx, tableName)
+	if err != nil {
+		return err
+	}
+	_, err = c.serviceClient.UpdateTimeToLive(ctx, &dynamodb.UpdateTimeToLiveInput{
+		TableName: aws.String(physical),
+		TimeToLiveSpecification: &types.TimeToLiveSpecification{
+			AttributeName: aws.String(attributeName),
+			Enabled:       aws.Bool(enabled),
+		},
+	})
+	return err
+}
+
+func (c *DynamodbClientImpl) DescribeTimeToLive(ctx context.Context, tableName string) (*types.TimeToLiveDescription, error) {
+	physical, err := c.adminTable(ctx, tableName)
+	if err != nil {
+		return nil, err
+	}
+	output, err := c.serviceClient.DescribeTimeToLive(ctx, &dynamodb.DescribeTimeToLiveInput{
+		TableName: aws.String(physical),
+	})
+	if err != nil {
+		return nil, err
+	}
+	return output.TimeToLiveDescription, nil
+}
+
+func (c *DynamodbClientImpl) expired(tableName string, item map[string]types.AttributeValue) bool {
+	attributeName, ok := c.ttlAttributes[tableName]
+	if !c.filterExpired || !ok {
+		return false
+	}
+	value, ok := item[attributeName].(*types.AttributeValueMemberN)
+	if !ok {
+		return false
+	}
+	expiresAt, err := strconv.ParseInt(value.Value, 10, 64)
+	return err == nil && expiresAt <= time.Now().Unix()
+}
+
+func (c *DynamodbClientImpl) withoutExpired(tableName string, items []map[string]types.AttributeValue) []map[string]types.AttributeValue {
+	if _, ok := c.ttlAttributes[tableName]; !c.filterExpired || !ok {
+		return items
+	}
+	live := items[:0]
+	for _, item := range items {
+		if !c.expired(tableName, item) {
+			live = append(live, item)
+		}
+	}
+	return live
+}
+
+func (c *DynamodbClientImpl) filterExpiredScan(tableName string, input *dynamodb.ScanInput) {
+	attributeName, ok := c.ttlAttributes[tableName]
+	if !c.filterExpired || !ok {
+		return
+	}
+	addScanFilter(input, "(attribute_not_exists(#ttl) OR #ttl > :now)",
+		map[string]string{"#ttl": attributeName},
+		map[string]types.AttributeValue{":now": &types.AttributeValueMemberN{Value: strconv.FormatInt(time.Now().Unix(), 10)}})
+}
+
+// addScanFilter ANDs condition onto the scan's filter expression.
+func addScanFilter(input *dynamodb.ScanInput, condition string, names map[string]string, values map[string]types.AttributeValue) {
+	if input.FilterExpression != nil {
+		condition = "(" + *input.FilterExpression + ") AND " + condition
+	}
+	input.FilterExpression = aws.String(condition)
+
+	if input.ExpressionAttributeNames == nil {
+		input.ExpressionAttributeNames = map[string]string{}
+	}
+	for k, v := range names {
+		input.ExpressionAttributeNames[k] = v
+	}
+
+	if input.ExpressionAttributeValues == nil {
+		input.ExpressionAttributeValues = map[string]types.AttributeValue{}
+	}
+	for k, v := range values {
+		input.ExpressionAttributeValues[k] = v
+	}
+}
@@ -0,0 +1,108 @@
+package events
+
+import (
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	TypeInsert = "insert"
+	TypeModify = "modify"
+	TypeRemove = "remove"
+)
+
+type Event struct {
+	// ID is "<epoch>-<n>": n counts the broker's events and epoch tells its runs apart.
+	ID     string         `json:"-"`
+	Type   string         `json:"type"`
+	Table  string         `json:"table"`
+	Tenant string         `json:"-"`
+	Keys   map[string]any `json:"keys,omitempty"`
+	Item   map[string]any `json:"item,omitempty"`
+	Time   time.Time      `json:"time"`
+
+	seq uint64
+}
+
+// Broker fans events out to subscribers and keeps the most recent ones so that
+// reconnecting clients can resume from the last event ID they saw.
+type Broker struct {
+	mu          sync.Mutex
+	history     []Event
+	size        int
+	epoch       string
+	seq         uint64
+	subscribers map[chan Event]struct{}
+}
+
+func NewBroker(historySize int) *Broker {
+	return &Broker{
+		size:        historySize,
+		epoch:       strconv.FormatInt(time.Now().UnixMilli(), 36),
+		subscribers: map[chan Event]struct{}{},
+	}
+}
+
+// Publish assigns the next ID to e and delivers it. Subscribers that fall behind are
+// disconnected rather than blocking the writer; they resume with their last event ID.
+func (b *Broker) Publish(e Event) Event {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.seq++
+	e.seq = b.seq
+	e.ID = b.epoch + "-" + strconv.FormatUint(b.seq, 10)
+	if e.Time.IsZero() {
+		e.Time = time.Now().UTC()
+	}
+
+	b.history = append(b.history, e)
+	if len(b.history) > b.size {
+		b.history = b.history[len(b.history)-b.size:]
+	}
+
+	for ch := range b.subscribers {
+		select {
+		case ch <- e:
+		default:
+			delete(b.subscribers, ch)
+			close(ch)
+		}
+	}
+	return e
+}
+
+// Subscribe returns the buffered events after lastID and a channel for new ones.
+// An ID of another epoch, e.g. from before a restart, replays every buffered event, as they
+// all came after it. The channel is closed by cancel or when the subscriber falls behind.
+func (b *Broker) Subscribe(lastID string) ([]Event, <-chan Event, func()) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	epoch, n, _ := strings.Cut(lastID, "-")
+	after, err := strconv.ParseUint(n, 10, 64)
+	if epoch != b.epoch || err != nil {
+		after = 0
+	}
+	var replay []Event
+	for _, e := range b.history {
+		if e.seq > after {
+			replay = append(replay, e)
+		}
+	}
+
+	ch := make(chan Event, 64)
+	b.subscribers[ch] = struct{}{}
+
+	cancel := func() {
+		b.mu.Lock()
+		defer b.mu.Unlock()
+		if _, ok := b.subscribers[ch]; ok {
+			delete(b.subscribers, ch)
+			close(ch)
+		}
+	}
+	return replay, ch, cancel
+}
@@ -0,0 +1,103 @@
+package events
+
+import (
+	"context"
+	dynamodbClient "dytest/dynamodb"
+	"dytest/stream"
+	"log"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	streamTypes "github.com/aws/aws-sdk-go-v2/service/dynamodbstreams/types"
+)
+
+// WriteHook publishes the client's own writes. It cannot tell an overwrite from a new
+// item, so puts are reported as inserts; use StreamHandler for exact event types.
+func WriteHook(broker *Broker) dynamodbClient.WriteHook {
+	eventTypes := map[string]string{
+		dynamodbClient.WriteOpPut:    TypeInsert,
+		dynamodbClient.WriteOpUpdate: TypeModify,
+		dynamodbClient.WriteOpDelete: TypeRemove,
+	}
+	return func(ctx context.Context, event dynamodbClient.WriteEvent) {
+		tenantID, _ := dynamodbClient.TenantFromContext(ctx)
+		e := Event{Type: eventTypes[event.Op], Table: event.TableName, Tenant: tenantID}
+
+		var err error
+		if e.Keys, err = toMap(event.Key); err == nil {
+			e.Item, err = toMap(event.Item)
+		}
+		if err != nil {
+			log.Printf("Failed to convert %s event on `%s`: %v\n", event.Op, event.TableName, err)
+			return
+		}
+		broker.Publish(e)
+	}
+}
+
+// StreamHandler publishes the records of a table's stream. For tables in tenant prefix mode
+// pass their partition key, so that the tenant is split off again.
+func StreamHandler(broker *Broker, tableName, tenantPartitionKey string) stream.RecordHandler {
+	return func(ctx context.Context, record streamTypes.Record) error {
+		e := Event{Table: tableName}
+		switch string(record.EventName) {
+		case stream.EventInsert:
+			e.Type = TypeInsert
+		case stream.EventModify:
+			e.Type = TypeModify
+		case stream.EventRemove:
+			e.Type = TypeRemove
+		}
+		if record.Dynamodb == nil {
+			broker.Publish(e)
+			return nil
+		}
+
+		keys, err := attributevalue.FromDynamoDBStreamsMap(record.Dynamodb.Keys)
+		if err != nil {
+			return err
+		}
+		var item map[string]types.AttributeValue
+		if record.Dynamodb.NewImage != nil {
+			if item, err = attributevalue.FromDynamoDBStreamsMap(record.Dynamodb.NewImage); err != nil {
+				return err
+			}
+		}
+		if tenantPartitionKey != "" {
+			e.Tenant = splitTenant(keys, tenantPartitionKey)
+			splitTenant(item, tenantPartitionKey)
+		}
+
+		if e.Keys, err = toMap(keys); err != nil {
+			return err
+		}
+		if e.Item, err = toMap(item); err != nil {
+			return err
+		}
+		broker.Publish(e)
+		return nil
+	}
+}
+
+func splitTenant(item map[string]types.AttributeValue, partitionKey string) string {
+	value, ok := item[partitionKey].(*types.AttributeValueMemberS)
+	if !ok {
+		return ""
+	}
+	tenantID, rest, ok := strings.Cut(value.Value, "#")
+	if !ok {
+		return ""
+	}
+	item[partitionKey] = &types.AttributeValueMemberS{Value: rest}
+	return tenantID
+}
+
+func toMap(av map[string]types.AttributeValue) (map[string]any, error) {
+	if av == nil {
+		return nil, nil
+	}
+	var m map[string]any
+	err := attributevalue.UnmarshalMap(av, &m)
+	return m, err
+}
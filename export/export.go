@@ -0,0 +1,279 @@
+package export
+
+import (
+	"bufio"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	dynamodbClient "dytest/dynamodb"
+)
+
+type Format string
+
+const (
+	FormatNDJSON Format = "ndjson"
+	FormatCSV    Format = "csv"
+	FormatJSON   Format = "json"
+)
+
+// ContentType is the media type an export in the format is served as.
+func (f Format) ContentType() string {
+	switch f {
+	case FormatNDJSON:
+		return "application/x-ndjson"
+	case FormatCSV:
+		return "text/csv; charset=utf-8"
+	}
+	return "application/json"
+}
+
+// Pages reads the page at cursor into items and returns the cursor of the next page, "" after the last.
+type Pages func(ctx context.Context, cursor string, items *[]map[string]any) (string, error)
+
+func ScanPages(client dynamodbClient.DynamodbClient, tableName string, scan dynamodbClient.ScanRequest) Pages {
+	return func(ctx context.Context, cursor string, items *[]map[string]any) (string, error) {
+		scan.Cursor = cursor
+		return client.ScanPage(ctx, tableName, scan, items)
+	}
+}
+
+func QueryPages(client dynamodbClient.DynamodbClient, tableName string, query dynamodbClient.QueryRequest) Pages {
+	return func(ctx context.Context, cursor string, items *[]map[string]any) (string, error) {
+		query.Cursor = cursor
+		return client.Query(ctx, tableName, query, items)
+	}
+}
+
+// Column is one CSV column: the header and the dotted attribute path it is read from.
+type Column struct {
+	Header string
+	Path   string
+}
+
+// ParseColumns reads columns written as "path" or "header=path", e.g. "rating=info.rating".
+func ParseColumns(specs []string) ([]Column, error) {
+	columns := make([]Column, 0, len(specs))
+	for _, spec := range specs {
+		header, path, ok := strings.Cut(strings.TrimSpace(spec), "=")
+		if !ok {
+			path = header
+		}
+		if !pathPattern.MatchString(path) || header == "" {
+			return nil, fmt.Errorf("invalid column %q", spec)
+		}
+		columns = append(columns, Column{Header: header, Path: path})
+	}
+	return columns, nil
+}
+
+// Encoder writes items in one format. Begin and End frame the items, Flush pushes a page out.
+type Encoder interface {
+	Begin() error
+	Encode(item map[string]any) error
+	End() error
+	Flush() error
+}
+
+// NewEncoder writes to w; columns are only used, and required, for CSV.
+func NewEncoder(format Format, w io.Writer, columns []Column) (Encoder, error) {
+	buffered, ok := w.(*bufio.Writer)
+	if !ok {
+		buffered = bufio.NewWriter(w)
+	}
+
+	switch format {
+	case FormatNDJSON:
+		return &ndjsonEncoder{w: buffered, enc: json.NewEncoder(buffered)}, nil
+	case FormatJSON:
+		return &jsonEncoder{ndjsonEncoder: ndjsonEncoder{w: buffered, enc: json.NewEncoder(buffered)}}, nil
+	case FormatCSV:
+		if len(columns) == 0 {
+			return nil, fmt.Errorf("csv export needs columns")
+		}
+		return &csvEncoder{w: buffered, csv: csv.NewWriter(buffered), columns: columns}, nil
+	}
+	return nil, fmt.Errorf("unsupported format %q", format)
+}
+
+// Prefetch reads the first page right away, so that errors surface before a response is started,
+// and returns pages that hand it out first.
+func Prefetch(ctx context.Context, pages Pages) (Pages, error) {
+	var first []map[string]any
+	next, err := pages(ctx, "", &first)
+	if err != nil {
+		return nil, err
+	}
+
+	used := false
+	return func(ctx context.Context, cursor string, items *[]map[string]any) (string, error) {
+		if cursor == "" && !used {
+			used = true
+			*items, first = first, nil
+			return next, nil
+		}
+		return pages(ctx, cursor, items)
+	}, nil
+}
+
+// Copy writes every page to enc and flushes after each, so memory stays at one page whatever the table size.
+func Copy(ctx context.Context, pages Pages, enc Encoder) (int, error) {
+	if err := enc.Begin(); err != nil {
+		return 0, err
+	}
+
+	written := 0
+	cursor := ""
+	for {
+		var items []map[string]any
+		var err error
+		if cursor, err = pages(ctx, cursor, &items); err != nil {
+			return written, err
+		}
+		for _, item := range items {
+			if err := enc.Encode(item); err != nil {
+				return written, err
+			}
+			written++
+		}
+		if err := enc.Flush(); err != nil {
+			return written, err
+		}
+		if cursor == "" {
+			break
+		}
+	}
+
+	if err := enc.End(); err != nil {
+		return written, err
+	}
+	return written, enc.Flush()
+}
+
+type ndjsonEncoder struct {
+	w   *bufio.Writer
+	enc *json.Encoder
+}
+
+func (e *ndjsonEncoder) Begin() error { return nil }
+
+func (e *ndjsonEncoder) Encode(item map[string]any) error { return e.enc.Encode(item) }
+
+func (e *ndjsonEncoder) End() error { return nil }
+
+func (e *ndjsonEncoder) Flush() error { return e.w.Flush() }
+
+// jsonEncoder writes one JSON array, item by item.
+type jsonEncoder struct {
+	ndjsonEncoder
+	count int
+}
+
+func (e *jsonEncoder) Begin() error {
+	_, err := e.w.WriteString("[")
+	return err
+}
+
+func (e *jsonEncoder) Encode(item map[string]any) error {
+	if e.count > 0 {
+		if _, err := e.w.WriteString(","); err != nil {
+			return err
+		}
+	}
+	e.count++
+	return e.enc.Encode(item)
+}
+
+func (e *jsonEncoder) End() error {
+	_, err := e.w.WriteString("]\n")
+	return err
+}
+
+// csvEncoder flattens nested attributes into the configured columns.
+type csvEncoder struct {
+	w       *bufio.Writer
+	csv     *csv.Writer
+	columns []Column
+}
+
+func (e *csvEncoder) Begin() error {
+	headers := make([]string, len(e.columns))
+	for i, column := range e.columns {
+		headers[i] = column.Header
+	}
+	return e.csv.Write(headers)
+}
+
+func (e *csvEncoder) Encode(item map[string]any) error {
+	record := make([]string, len(e.columns))
+	for i, column := range e.columns {
+		value, err := cell(lookup(item, column.Path))
+		if err != nil {
+			return err
+		}
+		record[i] = value
+	}
+	return e.csv.Write(record)
+}
+
+func (e *csvEncoder) End() error { return nil }
+
+func (e *csvEncoder) Flush() error {
+	e.csv.Flush()
+	if err := e.csv.Error(); err != nil {
+		return err
+	}
+	return e.w.Flush()
+}
+
+func lookup(item map[string]any, path string) any {
+	var value any = item
+	for _, segment := range strings.Split(path, ".") {
+		m, ok := value.(map[string]any)
+		if !ok {
+			return nil
+		}
+		value = m[segment]
+	}
+	return value
+}
+
+// cell formats a value for CSV: lists of scalars are joined with "|", maps and other nested values become JSON.
+func cell(value any) (string, error) {
+	switch v := value.(type) {
+	case nil:
+		return "", nil
+	case string:
+		return v, nil
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64), nil
+	case bool:
+		return strconv.FormatBool(v), nil
+	case []any:
+		parts := make([]string, len(v))
+		for i, element := range v {
+			switch element.(type) {
+			case map[string]any, []any:
+				return jsonCell(value)
+			}
+			part, err := cell(element)
+			if err != nil {
+				return "", err
+			}
+			parts[i] = part
+		}
+		return strings.Join(parts, "|"), nil
+	case []string:
+		return strings.Join(v, "|"), nil
+	}
+	return jsonCell(value)
+}
+
+func jsonCell(value any) (string, error) {
+	data, err := json.Marshal(value)
+	return string(data), err
+}
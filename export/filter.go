@@ -0,0 +1,133 @@
+package export
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+var (
+	conditionPattern = regexp.MustCompile(`^\s*([A-Za-z0-9_.]+)\s*(>=|<=|!=|\^=|=|<|>)\s*(.*?)\s*$`)
+	pathPattern      = regexp.MustCompile(`^[A-Za-z0-9_]+(\.[A-Za-z0-9_]+)*$`)
+	numberPattern    = regexp.MustCompile(`^-?[0-9]+(\.[0-9]+)?([eE][-+]?[0-9]+)?$`)
+)
+
+// Expressions holds a filter and a projection with the placeholders they share,
+// ready for a ScanRequest or QueryRequest.
+type Expressions struct {
+	Filter     string
+	Projection string
+	Names      map[string]string
+	Values     map[string]any
+
+	// prefix tells the placeholders of a key condition apart from a filter's.
+	prefix string
+}
+
+// Parse reads a filter such as "year>=2000,info.genres^=Dr" and projected fields such as
+// "title", "info.rating". Filter conditions are ANDed; the operators are =, !=, <, <=, >, >=
+// and ^= for begins_with. Values that parse as numbers or booleans are compared as such,
+// anything else, or anything in double quotes, as a string.
+func Parse(filter string, fields []string) (Expressions, error) {
+	e := Expressions{Names: map[string]string{}, Values: map[string]any{}}
+
+	var err error
+	if e.Filter, err = e.conditions("filter", filter); err != nil {
+		return Expressions{}, err
+	}
+
+	paths := make([]string, 0, len(fields))
+	for _, field := range fields {
+		field = strings.TrimSpace(field)
+		if !pathPattern.MatchString(field) {
+			return Expressions{}, fmt.Errorf("invalid field %q", field)
+		}
+		paths = append(paths, e.path(field))
+	}
+	e.Projection = strings.Join(paths, ", ")
+
+	if len(e.Names) == 0 {
+		e.Names = nil
+	}
+	if len(e.Values) == 0 {
+		e.Values = nil
+	}
+	return e, nil
+}
+
+// ParseKeyCondition reads a query's key condition, such as "year=2013,title^=The", with the
+// syntax of a filter. Its placeholders differ from Parse's, so both can go into one QueryRequest.
+func ParseKeyCondition(condition string) (Expressions, error) {
+	e := Expressions{Names: map[string]string{}, Values: map[string]any{}, prefix: "k"}
+	var err error
+	if e.Filter, err = e.conditions("key", condition); err != nil {
+		return Expressions{}, err
+	}
+	return e, nil
+}
+
+func (e *Expressions) conditions(kind, filter string) (string, error) {
+	if strings.TrimSpace(filter) == "" {
+		return "", nil
+	}
+
+	var conditions []string
+	for i, condition := range strings.Split(filter, ",") {
+		match := conditionPattern.FindStringSubmatch(condition)
+		if match == nil || !pathPattern.MatchString(match[1]) {
+			return "", fmt.Errorf("invalid %s condition %q", kind, condition)
+		}
+		path := e.path(match[1])
+		placeholder := fmt.Sprintf(":%sf%d", e.prefix, i)
+		e.Values[placeholder] = literal(match[3])
+
+		switch op := match[2]; op {
+		case "^=":
+			conditions = append(conditions, fmt.Sprintf("begins_with(%s, %s)", path, placeholder))
+		case "!=":
+			conditions = append(conditions, fmt.Sprintf("%s <> %s", path, placeholder))
+		default:
+			conditions = append(conditions, fmt.Sprintf("%s %s %s", path, op, placeholder))
+		}
+	}
+	return strings.Join(conditions, " AND "), nil
+}
+
+// path replaces every segment of a dotted path with a #name placeholder, one per distinct name.
+func (e *Expressions) path(path string) string {
+	segments := strings.Split(path, ".")
+	for i, segment := range segments {
+		placeholder := ""
+		for p, name := range e.Names {
+			if name == segment {
+				placeholder = p
+				break
+			}
+		}
+		if placeholder == "" {
+			placeholder = fmt.Sprintf("#%sn%d", e.prefix, len(e.Names))
+			e.Names[placeholder] = segment
+		}
+		segments[i] = placeholder
+	}
+	return strings.Join(segments, ".")
+}
+
+func literal(value string) any {
+	if unquoted, err := strconv.Unquote(value); err == nil && strings.HasPrefix(value, `"`) {
+		return unquoted
+	}
+	if numberPattern.MatchString(value) {
+		if n, err := strconv.ParseFloat(value, 64); err == nil {
+			return n
+		}
+	}
+	switch value {
+	case "true":
+		return true
+	case "false":
+		return false
+	}
+	return value
+}
@@ -0,0 +1,253 @@
+// Package fixtures loads seed files: one YAML or JSON file per table with the items to put into it,
+// optionally the table's schema so a missing table is created first, and a number of synthetic
+// movies to add. Loading is idempotent, so it suits tests against the in-memory client as well as
+// a dynamodb-local that was seeded before:
+//
+//	client := memory.New()
+//	if _, err := fixtures.Load(ctx, client, "seed"); err != nil {
+//		...
+//	}
+package fixtures
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	dynamodbClient "dytest/dynamodb"
+	"dytest/importer"
+	"dytest/model"
+	"dytest/validation"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"gopkg.in/yaml.v3"
+)
+
+const batchSize = 25
+
+// File is a seed file.
+type File struct {
+	Table string `json:"table" yaml:"table"`
+	// Schema creates the table when it does not exist yet.
+	Schema *model.TableSchema `json:"schema,omitempty" yaml:"schema,omitempty"`
+	Items  []map[string]any   `json:"items" yaml:"items"`
+	// Generate adds that many synthetic movies, the same ones for the same RandomSeed.
+	Generate   int   `json:"generate,omitempty" yaml:"generate,omitempty"`
+	RandomSeed int64 `json:"randomSeed,omitempty" yaml:"randomSeed,omitempty"`
+}
+
+// Decode reads a YAML or JSON file into v, by its extension; - reads JSON from stdin.
+func Decode(path string, v any) error {
+	var data []byte
+	var err error
+	if path == "-" {
+		data, err = io.ReadAll(os.Stdin)
+	} else {
+		data, err = os.ReadFile(path)
+	}
+	if err != nil {
+		return err
+	}
+
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		err = yaml.Unmarshal(data, v)
+	default:
+		err = json.Unmarshal(data, v)
+	}
+	if err != nil {
+		return fmt.Errorf("unable to parse %s: %v", path, err)
+	}
+	return nil
+}
+
+func LoadFile(path string) (*File, error) {
+	var f File
+	if err := Decode(path, &f); err != nil {
+		return nil, err
+	}
+	if f.Table == "" {
+		return nil, fmt.Errorf("%s names no table", path)
+	}
+	return &f, nil
+}
+
+// Result tells what loading a seed file did.
+type Result struct {
+	Path    string
+	Table   string
+	Created bool
+	Items   int
+}
+
+// Load applies seed files in order. A directory stands for its .yaml, .yml and .json files, sorted
+// by name.
+func Load(ctx context.Context, client dynamodbClient.DynamodbClient, paths ...string) ([]Result, error) {
+	var files []string
+	for _, path := range paths {
+		info, err := os.Stat(path)
+		if err != nil {
+			return nil, err
+		}
+		if !info.IsDir() {
+			files = append(files, path)
+			continue
+		}
+		entries, err := os.ReadDir(path)
+		if err != nil {
+			return nil, err
+		}
+		for _, entry := range entries {
+			switch strings.ToLower(filepath.Ext(entry.Name())) {
+			case ".yaml", ".yml", ".json":
+				if !entry.IsDir() {
+					files = append(files, filepath.Join(path, entry.Name()))
+				}
+			}
+		}
+	}
+
+	results := make([]Result, 0, len(files))
+	for _, path := range files {
+		f, err := LoadFile(path)
+		if err != nil {
+			return results, err
+		}
+		result, err := Apply(ctx, client, f)
+		result.Path = path
+		if err != nil {
+			return results, fmt.Errorf("%s: %w", path, err)
+		}
+		results = append(results, result)
+	}
+	return results, nil
+}
+
+// Apply creates the file's table if it has a schema and the table is missing, then upserts its
+// items and generated movies.
+func Apply(ctx context.Context, client dynamodbClient.DynamodbClient, f *File) (Result, error) {
+	result := Result{Table: f.Table}
+	if f.Schema != nil {
+		if f.Schema.TableName == "" {
+			f.Schema.TableName = f.Table
+		}
+		if f.Schema.TableName != f.Table {
+			return result, fmt.Errorf("the schema is for %s, not %s", f.Schema.TableName, f.Table)
+		}
+		created, err := EnsureTable(ctx, client, f.Schema)
+		if err != nil {
+			return result, err
+		}
+		result.Created = created
+	}
+
+	var err error
+	result.Items, err = Upsert(ctx, client, f)
+	return result, err
+}
+
+// EnsureTable creates the table of schema unless it exists, and reports whether it did.
+func EnsureTable(ctx context.Context, client dynamodbClient.DynamodbClient, schema *model.TableSchema) (bool, error) {
+	if _, err := client.DescribeTable(ctx, schema.TableName); err == nil {
+		return false, nil
+	}
+	err := CreateTable(ctx, client, schema)
+	var inUse *types.ResourceInUseException
+	if errors.As(err, &inUse) {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+// CreateTable validates schema, creates its table and turns on its time to live.
+func CreateTable(ctx context.Context, client dynamodbClient.DynamodbClient, schema *model.TableSchema) error {
+	if err := validation.Struct(schema); err != nil {
+		return err
+	}
+	input, err := schema.CreateTableInput()
+	if err != nil {
+		return err
+	}
+	if err := client.CreateTable(ctx, schema.TableName, input); err != nil {
+		return fmt.Errorf("failed to create %s: %w", schema.TableName, err)
+	}
+	if schema.TTLAttribute != "" {
+		return client.UpdateTimeToLive(ctx, schema.TableName, schema.TTLAttribute, true)
+	}
+	return nil
+}
+
+// Upsert validates every item against the table's model and puts them all, generated movies
+// included. Items are put whole, so loading a file again leaves the table as it was. Of items with
+// the same key the last one wins.
+func Upsert(ctx context.Context, client dynamodbClient.DynamodbClient, f *File) (int, error) {
+	data := f.Items
+	if f.Generate > 0 {
+		data = append(Movies(f.Generate, f.RandomSeed), data...)
+	}
+	description, err := client.DescribeTable(ctx, f.Table)
+	if err != nil {
+		return 0, fmt.Errorf("failed to describe %s: %w", f.Table, err)
+	}
+
+	items := make([]any, 0, len(data))
+	positions := map[string]int{}
+	var rejections []error
+	for i, row := range data {
+		item, rejection := importer.Decode(f.Table, importer.Row{Number: i + 1, Data: row})
+		if rejection != nil {
+			rejections = append(rejections, rejection)
+			continue
+		}
+		key, err := itemKey(description.KeySchema, item)
+		if err != nil {
+			return 0, err
+		}
+		if position, ok := positions[key]; ok {
+			items[position] = item
+			continue
+		}
+		positions[key] = len(items)
+		items = append(items, item)
+	}
+	if len(rejections) > 0 {
+		return 0, fmt.Errorf("invalid %s items: %w", f.Table, errors.Join(rejections...))
+	}
+
+	for start := 0; start < len(items); start += batchSize {
+		end := min(start+batchSize, len(items))
+		if err := client.BatchWriteItem(ctx, f.Table, items[start:end]); err != nil {
+			return start, fmt.Errorf("failed to put %s items: %w", f.Table, err)
+		}
+	}
+	return len(items), nil
+}
+
+// itemKey returns the key attributes of item as a string to compare items by.
+func itemKey(schema []types.KeySchemaElement, item any) (string, error) {
+	av, err := attributevalue.MarshalMap(item)
+	if err != nil {
+		return "", err
+	}
+	key := map[string]any{}
+	for _, element := range schema {
+		name := aws.ToString(element.AttributeName)
+		if av[name] == nil {
+			continue
+		}
+		var value any
+		if err := attributevalue.Unmarshal(av[name], &value); err != nil {
+			return "", err
+		}
+		key[name] = value
+	}
+	data, err := json.Marshal(key)
+	return string(data), err
+}
@@ -0,0 +1,155 @@
+package fixtures
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"strings"
+	"time"
+)
+
+// The distributions follow the AWS sample movie data: most movies are recent, ratings cluster
+// around 6.5, Drama and Comedy are by far the most common genres and a feature runs about 110
+// minutes.
+var (
+	genreWeights = []struct {
+		genre  string
+		weight int
+	}{
+		{"Drama", 30}, {"Comedy", 20}, {"Thriller", 10}, {"Action", 10}, {"Romance", 8}, {"Crime", 8},
+		{"Adventure", 6}, {"Horror", 5}, {"Mystery", 4}, {"Sci-Fi", 4}, {"Fantasy", 4}, {"Biography", 3},
+		{"Family", 3}, {"Animation", 2}, {"History", 2}, {"Music", 2}, {"War", 2}, {"Sport", 2},
+		{"Documentary", 1}, {"Musical", 1}, {"Western", 1}, {"Film-Noir", 1},
+	}
+
+	titleAdjectives = []string{"Silent", "Last", "Broken", "Golden", "Hidden", "Midnight", "Crimson", "Lost",
+		"Distant", "Wild", "Frozen", "Burning", "Hollow", "Secret", "Electric", "Savage", "Quiet", "Endless"}
+	titleNouns = []string{"River", "Kingdom", "Summer", "Empire", "Promise", "Garden", "Witness", "Harbor",
+		"Storm", "Stranger", "Horizon", "Heart", "City", "Road", "Game", "Letter", "Mountain", "Voyage"}
+	firstNames = []string{"James", "Mary", "John", "Patricia", "Robert", "Jennifer", "Michael", "Linda",
+		"David", "Elizabeth", "Sofia", "Kenji", "Amara", "Lucas", "Ingrid", "Rahul", "Chen", "Olga"}
+	lastNames = []string{"Smith", "Johnson", "Williams", "Brown", "Jones", "Garcia", "Miller", "Davis",
+		"Martinez", "Tanaka", "Okafor", "Novak", "Larsen", "Kapoor", "Moreau", "Rossi", "Kim", "Silva"}
+	plotHeroes = []string{"a retired detective", "two estranged sisters", "a young pilot", "a small-town teacher",
+		"a disgraced scientist", "an aging boxer", "a runaway heir", "a border guard"}
+	plotGoals = []string{"uncover a conspiracy", "find a missing child", "win one last race", "rebuild a family",
+		"escape a collapsing city", "clear a friend's name", "survive a brutal winter", "stop a heist"}
+)
+
+// Movies returns n synthetic movies, the same ones for the same seed, so loading them again
+// overwrites rather than adds.
+func Movies(n int, seed int64) []map[string]any {
+	if seed == 0 {
+		seed = 1
+	}
+	r := rand.New(rand.NewSource(seed))
+
+	ranks := r.Perm(n)
+	seen := map[string]bool{}
+	movies := make([]map[string]any, 0, n)
+	for i := 0; i < n; i++ {
+		year := movieYear(r)
+		title := movieTitle(r)
+		for attempt := 2; seen[fmt.Sprint(year, title)]; attempt++ {
+			title = fmt.Sprintf("%s %d", strings.TrimRight(title, "0123456789 "), attempt)
+		}
+		seen[fmt.Sprint(year, title)] = true
+
+		release := time.Date(year, time.January, 1, 0, 0, 0, 0, time.UTC).AddDate(0, 0, r.Intn(365))
+		minutes := clamp(math.Round(r.NormFloat64()*18+110), 70, 210)
+		movies = append(movies, map[string]any{
+			"year":  year,
+			"title": title,
+			"info": map[string]any{
+				"rating":            clamp(math.Round((r.NormFloat64()*1.1+6.5)*10)/10, 1, 9.8),
+				"rank":              ranks[i] + 1,
+				"release_date":      release.Format(time.RFC3339),
+				"running_time_secs": int(minutes) * 60,
+				"genres":            movieGenres(r),
+				"directors":         people(r, 1+boolInt(r.Intn(10) == 0)),
+				"actors":            people(r, 3),
+				"plot":              fmt.Sprintf("%s must %s.", capitalize(pick(r, plotHeroes)), pick(r, plotGoals)),
+			},
+		})
+	}
+	return movies
+}
+
+// newestYear is fixed rather than the current year so that a seed always gives the same movies.
+const newestYear = 2024
+
+// movieYear leans towards recent years: releases have grown roughly exponentially since 1920.
+func movieYear(r *rand.Rand) int {
+	age := int(r.ExpFloat64() * 18)
+	return max(newestYear-age, 1920)
+}
+
+func movieTitle(r *rand.Rand) string {
+	switch r.Intn(4) {
+	case 0:
+		return pick(r, titleNouns)
+	case 1:
+		return "The " + pick(r, titleNouns)
+	case 2:
+		return "The " + pick(r, titleAdjectives) + " " + pick(r, titleNouns)
+	}
+	return pick(r, titleAdjectives) + " " + pick(r, titleNouns)
+}
+
+// movieGenres picks one to three distinct genres by weight.
+func movieGenres(r *rand.Rand) []string {
+	total := 0
+	for _, g := range genreWeights {
+		total += g.weight
+	}
+	count := 1 + r.Intn(3)
+	genres := make([]string, 0, count)
+	for len(genres) < count {
+		n := r.Intn(total)
+		for _, g := range genreWeights {
+			if n -= g.weight; n < 0 {
+				if !containsString(genres, g.genre) {
+					genres = append(genres, g.genre)
+				}
+				break
+			}
+		}
+	}
+	return genres
+}
+
+func people(r *rand.Rand, n int) []string {
+	names := make([]string, n)
+	for i := range names {
+		names[i] = pick(r, firstNames) + " " + pick(r, lastNames)
+	}
+	return names
+}
+
+func pick(r *rand.Rand, words []string) string {
+	return words[r.Intn(len(words))]
+}
+
+func clamp(v, low, high float64) float64 {
+	return math.Min(math.Max(v, low), high)
+}
+
+func boolInt(b bool) int {
+	if b {
+		return 1
+	}
+	return 0
+}
+
+func capitalize(s string) string {
+	return strings.ToUpper(s[:1]) + s[1:]
+}
+
+func containsString(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,108 @@
+package graphqlapi
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"net/http"
+
+	dynamodbClient "dytest/dynamodb"
+	"dytest/problem"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/graphql-go/graphql"
+	"github.com/graphql-go/graphql/gqlerrors"
+)
+
+// Request is a GraphQL request as POSTed to /graphql; GET takes the same fields as query parameters.
+type Request struct {
+	Query         string         `json:"query" validate:"required"`
+	OperationName string         `json:"operationName,omitempty"`
+	Variables     map[string]any `json:"variables,omitempty"`
+}
+
+// Response is the GraphQL result. Errors carry the HTTP API's problem code and status in extensions.
+type Response struct {
+	Data   any                        `json:"data,omitempty"`
+	Errors []gqlerrors.FormattedError `json:"errors,omitempty"`
+}
+
+type Handler struct {
+	Client dynamodbClient.DynamodbClient
+	Schema graphql.Schema
+}
+
+// Serve runs a query or mutation with a fresh movie loader, so lookups are batched per request.
+func (h *Handler) Serve(c *fiber.Ctx) error {
+	var req Request
+	if c.Method() == fiber.MethodGet {
+		req.Query = c.Query("query")
+		req.OperationName = c.Query("operationName")
+		if variables := c.Query("variables"); variables != "" {
+			if err := json.Unmarshal([]byte(variables), &req.Variables); err != nil {
+				return problem.BadRequest("variables must be a JSON object")
+			}
+		}
+	} else if err := json.Unmarshal(c.Body(), &req); err != nil {
+		return problem.BadRequest("Invalid request body")
+	}
+	if req.Query == "" {
+		return problem.BadRequest("query is required")
+	}
+
+	ctx := context.WithValue(c.UserContext(), loaderKey, newMovieLoader(c.UserContext(), h.Client))
+	result := graphql.Do(graphql.Params{
+		Schema:         h.Schema,
+		RequestString:  req.Query,
+		OperationName:  req.OperationName,
+		VariableValues: req.Variables,
+		Context:        ctx,
+	})
+
+	requestID, _ := c.Locals("requestid").(string)
+	for i := range result.Errors {
+		result.Errors[i] = withProblem(result.Errors[i], requestID)
+	}
+	return c.Status(http.StatusOK).JSON(Response{Data: result.Data, Errors: result.Errors})
+}
+
+// withProblem replaces a resolver error's text with the problem the HTTP API would return,
+// so DynamoDB messages do not reach clients. Parse and validation errors are left as they are.
+func withProblem(formatted gqlerrors.FormattedError, requestID string) gqlerrors.FormattedError {
+	err := resolverError(formatted.OriginalError())
+	if err == nil {
+		return formatted
+	}
+
+	p := problem.From(err)
+	if p.Status >= http.StatusInternalServerError {
+		log.Printf("graphql %v: %v\n", formatted.Path, err)
+	}
+	formatted.Message = p.Detail
+	if formatted.Message == "" {
+		formatted.Message = p.Title
+	}
+	formatted.Extensions = map[string]any{"code": p.Code, "status": p.Status}
+	if len(p.Errors) > 0 {
+		formatted.Extensions["errors"] = p.Errors
+	}
+	if requestID != "" {
+		formatted.Extensions["requestId"] = requestID
+	}
+	return formatted
+}
+
+// resolverError digs the resolver's own error out of graphql-go's wrappers.
+func resolverError(err error) error {
+	for err != nil {
+		switch e := err.(type) {
+		case gqlerrors.FormattedError:
+			err = e.OriginalError()
+		case *gqlerrors.Error:
+			err = e.OriginalError
+		default:
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,104 @@
+package graphqlapi
+
+import (
+	"context"
+	"fmt"
+
+	dynamodbClient "dytest/dynamodb"
+	"dytest/model"
+
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+type movieKey struct {
+	Title string `dynamodbav:"title"`
+	Year  int    `dynamodbav:"year"`
+}
+
+type loadResult struct {
+	movie *model.MovieItem
+	err   error
+}
+
+// movieLoader coalesces the movie lookups of one request into BatchGetItem calls.
+// load hands graphql-go a thunk; the executor resolves every field of a level before it
+// calls the thunks, so the first thunk fetches all keys queued by that level at once.
+// graphql-go executes a request on one goroutine, so the loader is not locked.
+type movieLoader struct {
+	ctx     context.Context
+	client  dynamodbClient.DynamodbClient
+	pending []movieKey
+	results map[movieKey]*loadResult
+}
+
+func newMovieLoader(ctx context.Context, client dynamodbClient.DynamodbClient) *movieLoader {
+	return &movieLoader{ctx: ctx, client: client, results: map[movieKey]*loadResult{}}
+}
+
+// load returns a thunk for the movie, which is nil when it does not exist.
+func (l *movieLoader) load(key movieKey) func() (any, error) {
+	if _, ok := l.results[key]; !ok {
+		l.results[key] = nil
+		l.pending = append(l.pending, key)
+	}
+	return func() (any, error) {
+		if l.results[key] == nil {
+			l.dispatch()
+		}
+		result := l.results[key]
+		if result.err != nil || result.movie == nil {
+			return nil, result.err
+		}
+		return result.movie, nil
+	}
+}
+
+// prime caches a movie read some other way, such as by a query, so later lookups skip DynamoDB.
+func (l *movieLoader) prime(movie model.MovieItem) {
+	key := movieKey{Title: movie.Title, Year: movie.Year}
+	if l.results[key] == nil {
+		l.results[key] = &loadResult{movie: &movie}
+	}
+}
+
+// forget drops a cached movie after a mutation changed it.
+func (l *movieLoader) forget(key movieKey) {
+	delete(l.results, key)
+}
+
+func (l *movieLoader) dispatch() {
+	keys := l.pending
+	l.pending = nil
+
+	var movies []model.MovieItem
+	err := l.fetch(keys, &movies)
+	for _, key := range keys {
+		if l.results[key] == nil {
+			l.results[key] = &loadResult{err: err}
+		}
+	}
+	if err != nil {
+		return
+	}
+	for i := range movies {
+		if result := l.results[movieKey{Title: movies[i].Title, Year: movies[i].Year}]; result != nil {
+			result.movie = &movies[i]
+		}
+	}
+}
+
+func (l *movieLoader) fetch(keys []movieKey, movies *[]model.MovieItem) error {
+	avs := make([]map[string]types.AttributeValue, 0, len(keys))
+	for _, key := range keys {
+		av, err := attributevalue.MarshalMap(key)
+		if err != nil {
+			return err
+		}
+		avs = append(avs, av)
+	}
+	if err := l.client.BatchGetItem(l.ctx, moviesTable, avs, movies); err != nil {
+		return fmt.Errorf("failed to get movie items: %w", err)
+	}
+	return nil
+}
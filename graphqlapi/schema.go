@@ -0,0 +1,306 @@
+package graphqlapi
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	dynamodbClient "dytest/dynamodb"
+	"dytest/model"
+	"dytest/patch"
+	"dytest/problem"
+	"dytest/validation"
+	"dytest/webhook"
+
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/graphql-go/graphql"
+)
+
+const (
+	moviesTable     = "Movies"
+	defaultPageSize = 20
+	maxPageSize     = 100
+)
+
+type requestKey int
+
+const loaderKey requestKey = 0
+
+// resolvers holds what the resolvers share; the per-request loader travels in the context.
+type resolvers struct {
+	client   dynamodbClient.DynamodbClient
+	webhooks *webhook.Dispatcher
+}
+
+// NewSchema builds the movie schema. The Movie type and MovieInput are generated from model.MovieItem.
+func NewSchema(client dynamodbClient.DynamodbClient, webhooks *webhook.Dispatcher) (graphql.Schema, error) {
+	r := &resolvers{client: client, webhooks: webhooks}
+
+	fields, inputFields := objectFields(model.MovieItem{})
+	movie := graphql.NewObject(graphql.ObjectConfig{Name: "Movie", Fields: fields})
+	movieInput := graphql.NewInputObject(graphql.InputObjectConfig{Name: "MovieInput", Fields: inputFields})
+	movieKeyInput := graphql.NewInputObject(graphql.InputObjectConfig{
+		Name: "MovieKeyInput",
+		Fields: graphql.InputObjectConfigFieldMap{
+			"title": {Type: graphql.NewNonNull(graphql.String)},
+			"year":  {Type: graphql.NewNonNull(graphql.Int)},
+		},
+	})
+	moviePage := graphql.NewObject(graphql.ObjectConfig{
+		Name: "MoviePage",
+		Fields: graphql.Fields{
+			"items":      {Type: graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(movie)))},
+			"nextCursor": {Type: graphql.String, Description: "Pass as after for the next page; null on the last page."},
+		},
+	})
+
+	keyArgs := graphql.FieldConfigArgument{
+		"title": {Type: graphql.NewNonNull(graphql.String)},
+		"year":  {Type: graphql.NewNonNull(graphql.Int)},
+	}
+	pageArgs := graphql.FieldConfigArgument{
+		"titlePrefix": {Type: graphql.String},
+		"first":       {Type: graphql.Int, DefaultValue: defaultPageSize},
+		"after":       {Type: graphql.String},
+	}
+	yearPageArgs := graphql.FieldConfigArgument{"year": {Type: graphql.NewNonNull(graphql.Int)}}
+	for name, arg := range pageArgs {
+		yearPageArgs[name] = arg
+	}
+
+	query := graphql.NewObject(graphql.ObjectConfig{
+		Name: "Query",
+		Fields: graphql.Fields{
+			"movie": {
+				Type: movie, Args: keyArgs, Resolve: r.movie,
+				Description: "A movie by key, or null.",
+			},
+			"movies": {
+				Type: graphql.NewNonNull(graphql.NewList(movie)), Resolve: r.movies,
+				Args:        graphql.FieldConfigArgument{"keys": {Type: graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(movieKeyInput)))}},
+				Description: "Movies by key, in the order of keys; null where none exists.",
+			},
+			"moviesByYear": {
+				Type: graphql.NewNonNull(moviePage), Args: yearPageArgs, Resolve: r.moviesByYear,
+				Description: "One page of a year's movies.",
+			},
+			"scanMovies": {
+				Type: graphql.NewNonNull(moviePage), Args: pageArgs, Resolve: r.scanMovies,
+				Description: "One page of all movies.",
+			},
+		},
+	})
+
+	mutation := graphql.NewObject(graphql.ObjectConfig{
+		Name: "Mutation",
+		Fields: graphql.Fields{
+			"createMovie": {
+				Type: graphql.NewNonNull(movie), Resolve: r.createMovie,
+				Args: graphql.FieldConfigArgument{"input": {Type: graphql.NewNonNull(movieInput)}},
+			},
+			"updateMovie": {
+				Type: graphql.NewNonNull(movie), Resolve: r.updateMovie,
+				Args: graphql.FieldConfigArgument{
+					"title": keyArgs["title"],
+					"year":  keyArgs["year"],
+					"patch": {Type: graphql.NewNonNull(JSON), Description: "A JSON Merge Patch; only info can be changed."},
+				},
+			},
+			"deleteMovie": {
+				Type: graphql.NewNonNull(graphql.Boolean), Args: keyArgs, Resolve: r.deleteMovie,
+				Description: "Deletes a movie; false when there was none.",
+			},
+		},
+	})
+
+	return graphql.NewSchema(graphql.SchemaConfig{Query: query, Mutation: mutation})
+}
+
+func loader(ctx context.Context) *movieLoader {
+	return ctx.Value(loaderKey).(*movieLoader)
+}
+
+func keyOf(args map[string]any) movieKey {
+	title, _ := args["title"].(string)
+	year, _ := args["year"].(int)
+	return movieKey{Title: title, Year: year}
+}
+
+func (r *resolvers) movie(p graphql.ResolveParams) (any, error) {
+	return loader(p.Context).load(keyOf(p.Args)), nil
+}
+
+func (r *resolvers) movies(p graphql.ResolveParams) (any, error) {
+	keys, _ := p.Args["keys"].([]any)
+	l := loader(p.Context)
+	thunks := make([]func() (any, error), 0, len(keys))
+	for _, key := range keys {
+		args, _ := key.(map[string]any)
+		thunks = append(thunks, l.load(keyOf(args)))
+	}
+	return func() (any, error) {
+		movies := make([]any, 0, len(thunks))
+		for _, thunk := range thunks {
+			movie, err := thunk()
+			if err != nil {
+				return nil, err
+			}
+			movies = append(movies, movie)
+		}
+		return movies, nil
+	}, nil
+}
+
+func (r *resolvers) moviesByYear(p graphql.ResolveParams) (any, error) {
+	limit, err := pageSize(p.Args)
+	if err != nil {
+		return nil, err
+	}
+	query := dynamodbClient.QueryRequest{
+		KeyCondition: "#year = :year",
+		Names:        map[string]string{"#year": "year"},
+		Values:       map[string]any{":year": p.Args["year"]},
+		Limit:        limit,
+	}
+	query.Cursor, _ = p.Args["after"].(string)
+	if titlePrefix, _ := p.Args["titlePrefix"].(string); titlePrefix != "" {
+		query.KeyCondition += " AND begins_with(#title, :titlePrefix)"
+		query.Names["#title"] = "title"
+		query.Values[":titlePrefix"] = titlePrefix
+	}
+
+	list := model.MovieList{Items: []model.MovieItem{}}
+	if list.NextCursor, err = r.client.Query(p.Context, moviesTable, query, &list.Items); err != nil {
+		return nil, fmt.Errorf("failed to list movies: %w", err)
+	}
+	return r.page(p.Context, list), nil
+}
+
+func (r *resolvers) scanMovies(p graphql.ResolveParams) (any, error) {
+	limit, err := pageSize(p.Args)
+	if err != nil {
+		return nil, err
+	}
+	scan := dynamodbClient.ScanRequest{Limit: limit}
+	scan.Cursor, _ = p.Args["after"].(string)
+	if titlePrefix, _ := p.Args["titlePrefix"].(string); titlePrefix != "" {
+		scan.Filter = "begins_with(#title, :titlePrefix)"
+		scan.Names = map[string]string{"#title": "title"}
+		scan.Values = map[string]any{":titlePrefix": titlePrefix}
+	}
+
+	list := model.MovieList{Items: []model.MovieItem{}}
+	if list.NextCursor, err = r.client.ScanPage(p.Context, moviesTable, scan, &list.Items); err != nil {
+		return nil, fmt.Errorf("failed to list movies: %w", err)
+	}
+	return r.page(p.Context, list), nil
+}
+
+// page primes the loader with the listed movies and shapes the MoviePage.
+func (r *resolvers) page(ctx context.Context, list model.MovieList) map[string]any {
+	l := loader(ctx)
+	for _, movie := range list.Items {
+		l.prime(movie)
+	}
+	page := map[string]any{"items": list.Items, "nextCursor": nil}
+	if list.NextCursor != "" {
+		page["nextCursor"] = list.NextCursor
+	}
+	return page
+}
+
+func pageSize(args map[string]any) (int32, error) {
+	first, _ := args["first"].(int)
+	if first < 1 || first > maxPageSize {
+		return 0, problem.BadRequest(fmt.Sprintf("first must be between 1 and %d", maxPageSize))
+	}
+	return int32(first), nil
+}
+
+func (r *resolvers) createMovie(p graphql.ResolveParams) (any, error) {
+	var movie model.MovieItem
+	data, err := json.Marshal(p.Args["input"])
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, &movie); err != nil {
+		return nil, problem.BadRequest("Invalid movie input")
+	}
+	if err := validation.Struct(&movie); err != nil {
+		return nil, err
+	}
+
+	err = r.client.TransactWriteItems(p.Context, moviesTable, movie, dynamodbClient.IfNotExists("title"))
+	if errors.Is(err, dynamodbClient.ErrConditionFailed) {
+		return nil, problem.Conflict("Movie already exists")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to save movie item: %w", err)
+	}
+
+	loader(p.Context).prime(movie)
+	r.notify(p.Context, webhook.EventMovieCreated, movie)
+	return movie, nil
+}
+
+func (r *resolvers) updateMovie(p graphql.ResolveParams) (any, error) {
+	key := keyOf(p.Args)
+	doc, ok := p.Args["patch"].(map[string]any)
+	if !ok {
+		return nil, problem.BadRequest("patch must be an object")
+	}
+
+	mergePatch := patch.FromMergePatch(doc)
+	var movie model.MovieItem
+	if err := validation.Patch(mergePatch, &movie); err != nil {
+		return nil, err
+	}
+
+	av, err := attributevalue.MarshalMap(key)
+	if err != nil {
+		return nil, err
+	}
+	err = mergePatch.Apply(p.Context, r.client, moviesTable, av, "title")
+	if errors.Is(err, dynamodbClient.ErrConditionFailed) {
+		return nil, problem.NotFound("Movie not found")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to update movie item: %w", err)
+	}
+	if err := r.client.GetItem(p.Context, moviesTable, av, &movie); err != nil {
+		return nil, fmt.Errorf("failed to get movie item: %w", err)
+	}
+
+	l := loader(p.Context)
+	l.forget(key)
+	l.prime(movie)
+	r.notify(p.Context, webhook.EventMovieUpdated, movie)
+	return movie, nil
+}
+
+func (r *resolvers) deleteMovie(p graphql.ResolveParams) (any, error) {
+	key := keyOf(p.Args)
+	av, err := attributevalue.MarshalMap(key)
+	if err != nil {
+		return nil, err
+	}
+
+	err = r.client.DeleteItem(p.Context, moviesTable, av, dynamodbClient.IfExists("title"))
+	if errors.Is(err, dynamodbClient.ErrConditionFailed) {
+		return false, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to delete movie item: %w", err)
+	}
+
+	loader(p.Context).forget(key)
+	r.notify(p.Context, webhook.EventMovieDeleted, map[string]any{"title": key.Title, "year": key.Year})
+	return true, nil
+}
+
+func (r *resolvers) notify(ctx context.Context, event string, data any) {
+	if r.webhooks != nil {
+		r.webhooks.Dispatch(ctx, event, data)
+	}
+}
@@ -0,0 +1,117 @@
+package graphqlapi
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/graphql-go/graphql"
+	"github.com/graphql-go/graphql/language/ast"
+)
+
+// JSON carries free-form values such as a movie's info map.
+var JSON = graphql.NewScalar(graphql.ScalarConfig{
+	Name:         "JSON",
+	Description:  "Any JSON value.",
+	Serialize:    func(value any) any { return value },
+	ParseValue:   func(value any) any { return value },
+	ParseLiteral: parseLiteral,
+})
+
+func parseLiteral(value ast.Value) any {
+	switch v := value.(type) {
+	case *ast.ObjectValue:
+		m := make(map[string]any, len(v.Fields))
+		for _, field := range v.Fields {
+			m[field.Name.Value] = parseLiteral(field.Value)
+		}
+		return m
+	case *ast.ListValue:
+		list := make([]any, 0, len(v.Values))
+		for _, item := range v.Values {
+			list = append(list, parseLiteral(item))
+		}
+		return list
+	case *ast.IntValue:
+		return graphql.Int.ParseLiteral(v)
+	case *ast.FloatValue:
+		return graphql.Float.ParseLiteral(v)
+	case *ast.BooleanValue:
+		return v.Value
+	case *ast.StringValue:
+		return v.Value
+	case *ast.EnumValue:
+		return v.Value
+	}
+	return nil
+}
+
+// objectFields builds GraphQL fields from a model's json tags; validate:"required" makes a field non-null.
+// It returns the object's fields and the matching input object fields.
+func objectFields(model any) (graphql.Fields, graphql.InputObjectConfigFieldMap) {
+	t := reflect.TypeOf(model)
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	fields := graphql.Fields{}
+	inputs := graphql.InputObjectConfigFieldMap{}
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		name, _, _ := strings.Cut(f.Tag.Get("json"), ",")
+		if !f.IsExported() || name == "-" {
+			continue
+		}
+		if name == "" {
+			name = f.Name
+		}
+
+		scalar := scalarFor(f.Type)
+		var output graphql.Output = scalar
+		var input graphql.Input = scalar
+		if required(f.Tag.Get("validate")) {
+			nonNull := graphql.NewNonNull(scalar)
+			output, input = nonNull, nonNull
+		}
+
+		index := f.Index
+		fields[name] = &graphql.Field{
+			Type: output,
+			Resolve: func(p graphql.ResolveParams) (any, error) {
+				v := reflect.Indirect(reflect.ValueOf(p.Source))
+				if v.Kind() != reflect.Struct {
+					return nil, fmt.Errorf("cannot resolve %s on %T", p.Info.FieldName, p.Source)
+				}
+				return v.FieldByIndex(index).Interface(), nil
+			},
+		}
+		inputs[name] = &graphql.InputObjectFieldConfig{Type: input}
+	}
+	return fields, inputs
+}
+
+func scalarFor(t reflect.Type) *graphql.Scalar {
+	switch t.Kind() {
+	case reflect.Pointer:
+		return scalarFor(t.Elem())
+	case reflect.String:
+		return graphql.String
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32:
+		return graphql.Int
+	case reflect.Float32, reflect.Float64:
+		return graphql.Float
+	case reflect.Bool:
+		return graphql.Boolean
+	}
+	return JSON
+}
+
+func required(validate string) bool {
+	for _, rule := range strings.Split(validate, ",") {
+		if rule == "required" {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,67 @@
+package grpcserver
+
+import (
+	"errors"
+	"net/http"
+
+	dynamodbClient "dytest/dynamodb"
+	"dytest/problem"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/protoadapt"
+)
+
+const errorDomain = "dytest"
+
+// toStatus maps an error the way the HTTP error handler does, then carries the problem code
+// as ErrorInfo.Reason and field errors as a BadRequest detail.
+func toStatus(err error, requestID string) *status.Status {
+	if s, ok := status.FromError(err); ok {
+		return s
+	}
+
+	p := problem.From(err)
+	s := status.New(grpcCode(p, err), p.Detail)
+
+	info := &errdetails.ErrorInfo{Reason: p.Code, Domain: errorDomain}
+	if requestID != "" {
+		info.Metadata = map[string]string{"requestId": requestID}
+	}
+	details := []protoadapt.MessageV1{info}
+	if len(p.Errors) > 0 {
+		violations := make([]*errdetails.BadRequest_FieldViolation, 0, len(p.Errors))
+		for _, field := range p.Errors {
+			violations = append(violations, &errdetails.BadRequest_FieldViolation{Field: field.Field, Description: field.Message})
+		}
+		details = append(details, &errdetails.BadRequest{FieldViolations: violations})
+	}
+	if withDetails, detailsErr := s.WithDetails(details...); detailsErr == nil {
+		s = withDetails
+	}
+	return s
+}
+
+func grpcCode(p *problem.Problem, err error) codes.Code {
+	switch p.Status {
+	case http.StatusBadRequest, http.StatusUnprocessableEntity, http.StatusRequestEntityTooLarge, http.StatusUnsupportedMediaType:
+		return codes.InvalidArgument
+	case http.StatusUnauthorized:
+		return codes.Unauthenticated
+	case http.StatusForbidden:
+		return codes.PermissionDenied
+	case http.StatusNotFound:
+		return codes.NotFound
+	case http.StatusConflict:
+		if errors.Is(err, dynamodbClient.ErrConditionFailed) {
+			return codes.FailedPrecondition
+		}
+		return codes.AlreadyExists
+	case http.StatusTooManyRequests:
+		return codes.ResourceExhausted
+	case http.StatusServiceUnavailable:
+		return codes.Unavailable
+	}
+	return codes.Internal
+}
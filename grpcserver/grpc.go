@@ -0,0 +1,124 @@
+package grpcserver
+
+import (
+	"context"
+	"log"
+	"net"
+
+	"dytest/config"
+	dynamodbClient "dytest/dynamodb"
+	moviesv1 "dytest/gen/dytest/movies/v1"
+	"dytest/middleware"
+
+	"github.com/google/uuid"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/reflection"
+)
+
+const requestIDHeader = "x-request-id"
+
+// NewServer registers the MovieService with health checking and reflection.
+// Requests are scoped to the tenant the HTTP middleware would pick, read from metadata.
+func NewServer(tenant config.TenantConfig, movies *MovieServer) *grpc.Server {
+	i := &interceptor{tenant: tenant}
+	server := grpc.NewServer(
+		grpc.UnaryInterceptor(i.unary),
+		grpc.StreamInterceptor(i.stream),
+	)
+
+	moviesv1.RegisterMovieServiceServer(server, movies)
+
+	healthServer := health.NewServer()
+	healthServer.SetServingStatus("", healthpb.HealthCheckResponse_SERVING)
+	healthServer.SetServingStatus(moviesv1.MovieService_ServiceDesc.ServiceName, healthpb.HealthCheckResponse_SERVING)
+	healthpb.RegisterHealthServer(server, healthServer)
+
+	reflection.Register(server)
+	return server
+}
+
+// Serve listens on addr and blocks until the server stops.
+func Serve(addr string, server *grpc.Server) error {
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return err
+	}
+	return server.Serve(listener)
+}
+
+type interceptor struct {
+	tenant config.TenantConfig
+}
+
+func (i *interceptor) unary(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+	ctx, requestID, err := i.prepare(ctx)
+	if err == nil {
+		var resp any
+		if resp, err = handler(ctx, req); err == nil {
+			return resp, nil
+		}
+	}
+	return nil, i.fail(info.FullMethod, requestID, err)
+}
+
+func (i *interceptor) stream(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	ctx, requestID, err := i.prepare(ss.Context())
+	if err == nil {
+		if err = handler(srv, &scopedStream{ServerStream: ss, ctx: ctx}); err == nil {
+			return nil
+		}
+	}
+	return i.fail(info.FullMethod, requestID, err)
+}
+
+// prepare assigns the request ID and puts the caller's tenant into the context.
+func (i *interceptor) prepare(ctx context.Context) (context.Context, string, error) {
+	md, _ := metadata.FromIncomingContext(ctx)
+
+	requestID := first(md.Get(requestIDHeader))
+	if requestID == "" {
+		requestID = uuid.NewString()
+	}
+	grpc.SetHeader(ctx, metadata.Pairs(requestIDHeader, requestID))
+
+	var header string
+	if i.tenant.Header != "" {
+		header = first(md.Get(i.tenant.Header))
+	}
+	tenantID, err := middleware.TenantID(i.tenant, first(md.Get("authorization")), header)
+	if err != nil {
+		return ctx, requestID, err
+	}
+	if tenantID != "" {
+		ctx = dynamodbClient.WithTenant(ctx, tenantID)
+	}
+	return ctx, requestID, nil
+}
+
+func (i *interceptor) fail(method, requestID string, err error) error {
+	s := toStatus(err, requestID)
+	if s.Code() == codes.Internal || s.Code() == codes.Unknown {
+		log.Printf("%s: %v\n", method, err)
+	}
+	return s.Err()
+}
+
+func first(values []string) string {
+	if len(values) == 0 {
+		return ""
+	}
+	return values[0]
+}
+
+type scopedStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *scopedStream) Context() context.Context {
+	return s.ctx
+}
@@ -0,0 +1,327 @@
+package grpcserver
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	dynamodbClient "dytest/dynamodb"
+	moviesv1 "dytest/gen/dytest/movies/v1"
+	"dytest/model"
+	"dytest/patch"
+	"dytest/problem"
+	"dytest/validation"
+	"dytest/webhook"
+
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+const (
+	moviesTable     = "Movies"
+	defaultPageSize = 20
+	maxPageSize     = 100
+	batchGetLimit   = 100
+	scanPageSize    = 100
+)
+
+// MovieServer implements moviesv1.MovieServiceServer over the same client and rules as the /movies routes.
+type MovieServer struct {
+	moviesv1.UnimplementedMovieServiceServer
+
+	Client   dynamodbClient.DynamodbClient
+	Webhooks *webhook.Dispatcher
+}
+
+func (s *MovieServer) GetMovie(ctx context.Context, req *moviesv1.GetMovieRequest) (*moviesv1.GetMovieResponse, error) {
+	key, err := movieKey(req.GetKey())
+	if err != nil {
+		return nil, err
+	}
+
+	var movie model.MovieItem
+	err = s.Client.GetItem(ctx, moviesTable, key, &movie)
+	if errors.Is(err, dynamodbClient.ErrNotFound) {
+		return nil, problem.NotFound("Movie not found")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get movie item: %w", err)
+	}
+
+	pb, err := toProto(movie)
+	if err != nil {
+		return nil, err
+	}
+	return &moviesv1.GetMovieResponse{Movie: pb}, nil
+}
+
+// PutMovie creates or replaces a movie; with if_not_exists an existing movie is a conflict.
+func (s *MovieServer) PutMovie(ctx context.Context, req *moviesv1.PutMovieRequest) (*moviesv1.PutMovieResponse, error) {
+	movie := fromProto(req.GetMovie())
+	if err := validation.Struct(&movie); err != nil {
+		return nil, err
+	}
+
+	created := true
+	err := s.Client.TransactWriteItems(ctx, moviesTable, movie, dynamodbClient.IfNotExists("title"))
+	if errors.Is(err, dynamodbClient.ErrConditionFailed) {
+		if req.GetIfNotExists() {
+			return nil, problem.Conflict("Movie already exists")
+		}
+		created = false
+		err = s.Client.TransactWriteItems(ctx, moviesTable, movie)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to save movie item: %w", err)
+	}
+
+	if created {
+		s.notify(ctx, webhook.EventMovieCreated, movie)
+	} else {
+		s.notify(ctx, webhook.EventMovieUpdated, movie)
+	}
+	return &moviesv1.PutMovieResponse{Movie: req.GetMovie(), Created: created}, nil
+}
+
+// UpdateMovie applies a JSON Merge Patch with the same allowlist and schema checks as PATCH /movies.
+func (s *MovieServer) UpdateMovie(ctx context.Context, req *moviesv1.UpdateMovieRequest) (*moviesv1.UpdateMovieResponse, error) {
+	key, err := movieKey(req.GetKey())
+	if err != nil {
+		return nil, err
+	}
+	if req.GetMergePatch() == nil {
+		return nil, problem.BadRequest("merge_patch is required")
+	}
+
+	p := patch.FromMergePatch(req.GetMergePatch().AsMap())
+	var movie model.MovieItem
+	if err := validation.Patch(p, &movie); err != nil {
+		return nil, err
+	}
+
+	err = p.Apply(ctx, s.Client, moviesTable, key, "title")
+	if errors.Is(err, dynamodbClient.ErrConditionFailed) {
+		return nil, problem.NotFound("Movie not found")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to update movie item: %w", err)
+	}
+
+	if err := s.Client.GetItem(ctx, moviesTable, key, &movie); err != nil {
+		return nil, fmt.Errorf("failed to get movie item: %w", err)
+	}
+	s.notify(ctx, webhook.EventMovieUpdated, movie)
+
+	pb, err := toProto(movie)
+	if err != nil {
+		return nil, err
+	}
+	return &moviesv1.UpdateMovieResponse{Movie: pb}, nil
+}
+
+func (s *MovieServer) DeleteMovie(ctx context.Context, req *moviesv1.DeleteMovieRequest) (*moviesv1.DeleteMovieResponse, error) {
+	key, err := movieKey(req.GetKey())
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.Client.DeleteItem(ctx, moviesTable, key, dynamodbClient.IfExists("title"))
+	if errors.Is(err, dynamodbClient.ErrConditionFailed) {
+		return nil, problem.NotFound("Movie not found")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to delete movie item: %w", err)
+	}
+
+	s.notify(ctx, webhook.EventMovieDeleted, map[string]any{"title": req.GetKey().GetTitle(), "year": req.GetKey().GetYear()})
+	return &moviesv1.DeleteMovieResponse{}, nil
+}
+
+// QueryMovies pages like GET /movies: a year queries that partition, otherwise the table is scanned.
+func (s *MovieServer) QueryMovies(ctx context.Context, req *moviesv1.QueryMoviesRequest) (*moviesv1.QueryMoviesResponse, error) {
+	limit := req.GetLimit()
+	if limit == 0 {
+		limit = defaultPageSize
+	}
+	if limit < 1 || limit > maxPageSize {
+		return nil, problem.BadRequest(fmt.Sprintf("limit must be between 1 and %d", maxPageSize))
+	}
+
+	var movies []model.MovieItem
+	var cursor string
+	var err error
+	if req.GetYear() != 0 {
+		query := dynamodbClient.QueryRequest{
+			KeyCondition: "#year = :year",
+			Names:        map[string]string{"#year": "year"},
+			Values:       map[string]any{":year": req.GetYear()},
+			Limit:        limit,
+			Cursor:       req.GetCursor(),
+		}
+		if req.GetTitlePrefix() != "" {
+			query.KeyCondition += " AND begins_with(#title, :titlePrefix)"
+			query.Names["#title"] = "title"
+			query.Values[":titlePrefix"] = req.GetTitlePrefix()
+		}
+		cursor, err = s.Client.Query(ctx, moviesTable, query, &movies)
+	} else {
+		scan := titleScan(req.GetTitlePrefix())
+		scan.Limit = limit
+		scan.Cursor = req.GetCursor()
+		cursor, err = s.Client.ScanPage(ctx, moviesTable, scan, &movies)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to list movies: %w", err)
+	}
+
+	pbs, err := toProtos(movies)
+	if err != nil {
+		return nil, err
+	}
+	return &moviesv1.QueryMoviesResponse{Movies: pbs, NextCursor: cursor}, nil
+}
+
+// ScanMovies reads the table a page at a time, so a slow client holds back the scan rather than memory.
+func (s *MovieServer) ScanMovies(req *moviesv1.ScanMoviesRequest, stream moviesv1.MovieService_ScanMoviesServer) error {
+	ctx := stream.Context()
+	scan := titleScan(req.GetTitlePrefix())
+	scan.Limit = scanPageSize
+
+	for {
+		var movies []model.MovieItem
+		cursor, err := s.Client.ScanPage(ctx, moviesTable, scan, &movies)
+		if err != nil {
+			return fmt.Errorf("failed to scan movies: %w", err)
+		}
+		for _, movie := range movies {
+			pb, err := toProto(movie)
+			if err != nil {
+				return err
+			}
+			if err := stream.Send(&moviesv1.ScanMoviesResponse{Movie: pb}); err != nil {
+				return err
+			}
+		}
+		if cursor == "" {
+			return nil
+		}
+		scan.Cursor = cursor
+	}
+}
+
+func (s *MovieServer) BatchGetMovies(ctx context.Context, req *moviesv1.BatchGetMoviesRequest) (*moviesv1.BatchGetMoviesResponse, error) {
+	if len(req.GetKeys()) > batchGetLimit {
+		return nil, problem.BadRequest(fmt.Sprintf("at most %d keys can be read at once", batchGetLimit))
+	}
+
+	keys := make([]map[string]types.AttributeValue, 0, len(req.GetKeys()))
+	for _, k := range req.GetKeys() {
+		key, err := movieKey(k)
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, key)
+	}
+
+	var movies []model.MovieItem
+	if err := s.Client.BatchGetItem(ctx, moviesTable, keys, &movies); err != nil {
+		return nil, fmt.Errorf("failed to get movie items: %w", err)
+	}
+
+	pbs, err := toProtos(movies)
+	if err != nil {
+		return nil, err
+	}
+	return &moviesv1.BatchGetMoviesResponse{Movies: pbs}, nil
+}
+
+// BatchPutMovies validates every movie before writing any of them.
+func (s *MovieServer) BatchPutMovies(ctx context.Context, req *moviesv1.BatchPutMoviesRequest) (*moviesv1.BatchPutMoviesResponse, error) {
+	items := make([]any, 0, len(req.GetMovies()))
+	var invalid []problem.FieldError
+	for i, pb := range req.GetMovies() {
+		movie := fromProto(pb)
+		var p *problem.Problem
+		if err := validation.Struct(&movie); errors.As(err, &p) {
+			for _, field := range p.Errors {
+				invalid = append(invalid, problem.FieldError{Field: fmt.Sprintf("movies[%d].%s", i, field.Field), Message: field.Message})
+			}
+			continue
+		} else if err != nil {
+			return nil, err
+		}
+		items = append(items, movie)
+	}
+	if len(invalid) > 0 {
+		return nil, problem.Validation("Some movies are invalid; none were written", invalid...)
+	}
+
+	if err := s.Client.BatchWriteItem(ctx, moviesTable, items); err != nil {
+		return nil, fmt.Errorf("failed to save movie items: %w", err)
+	}
+	return &moviesv1.BatchPutMoviesResponse{Written: int32(len(items))}, nil
+}
+
+func (s *MovieServer) notify(ctx context.Context, event string, data any) {
+	if s.Webhooks != nil {
+		s.Webhooks.Dispatch(ctx, event, data)
+	}
+}
+
+func titleScan(titlePrefix string) dynamodbClient.ScanRequest {
+	var scan dynamodbClient.ScanRequest
+	if titlePrefix != "" {
+		scan.Filter = "begins_with(#title, :titlePrefix)"
+		scan.Names = map[string]string{"#title": "title"}
+		scan.Values = map[string]any{":titlePrefix": titlePrefix}
+	}
+	return scan
+}
+
+func movieKey(key *moviesv1.MovieKey) (map[string]types.AttributeValue, error) {
+	if key.GetTitle() == "" || key.GetYear() == 0 {
+		return nil, problem.BadRequest("key needs a title and a year")
+	}
+	titleAttr, err := attributevalue.Marshal(key.GetTitle())
+	if err != nil {
+		return nil, problem.BadRequest(err.Error())
+	}
+	yearAttr, err := attributevalue.Marshal(key.GetYear())
+	if err != nil {
+		return nil, problem.BadRequest(err.Error())
+	}
+	return map[string]types.AttributeValue{"title": titleAttr, "year": yearAttr}, nil
+}
+
+func fromProto(pb *moviesv1.Movie) model.MovieItem {
+	movie := model.MovieItem{Title: pb.GetTitle(), Year: int(pb.GetYear())}
+	if pb.GetInfo() != nil {
+		movie.Info = pb.GetInfo().AsMap()
+	}
+	return movie
+}
+
+func toProto(movie model.MovieItem) (*moviesv1.Movie, error) {
+	pb := &moviesv1.Movie{Title: movie.Title, Year: int32(movie.Year)}
+	if movie.Info != nil {
+		info, err := structpb.NewStruct(movie.Info)
+		if err != nil {
+			return nil, fmt.Errorf("failed to convert info of %q: %v", movie.Title, err)
+		}
+		pb.Info = info
+	}
+	return pb, nil
+}
+
+func toProtos(movies []model.MovieItem) ([]*moviesv1.Movie, error) {
+	pbs := make([]*moviesv1.Movie, 0, len(movies))
+	for _, movie := range movies {
+		pb, err := toProto(movie)
+		if err != nil {
+			return nil, err
+		}
+		pbs = append(pbs, pb)
+	}
+	return pbs, nil
+}
@@ -0,0 +1,285 @@
+package importer
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+	"time"
+
+	dynamodbClient "dytest/dynamodb"
+	"dytest/model"
+	"dytest/problem"
+	"dytest/validation"
+)
+
+const (
+	batchSize = 25
+	// maxRejections is how many rejections an Import keeps; it still counts the rest.
+	maxRejections = 500
+	writeRetries  = 5
+)
+
+// Models are the tables rows can be imported into, with the model each row is validated against.
+var Models = map[string]func() any{
+	"Movies": func() any { return &model.MovieItem{} },
+}
+
+// Import is both the checkpoint and the report of one import. Rows up to and including Row have
+// been handled: written or rejected.
+type Import struct {
+	ID         string      `dynamodbav:"id" json:"id"`
+	TableName  string      `dynamodbav:"tableName" json:"tableName"`
+	Format     string      `dynamodbav:"format" json:"format"`
+	Row        int         `dynamodbav:"row" json:"row"`
+	Written    int         `dynamodbav:"written" json:"written"`
+	Rejected   int         `dynamodbav:"rejected" json:"rejected"`
+	Rejections []Rejection `dynamodbav:"rejections" json:"rejections"`
+	Done       bool        `dynamodbav:"done" json:"done"`
+	Tenant     string      `dynamodbav:"tenant,omitempty" json:"-"`
+	CreatedAt  time.Time   `dynamodbav:"createdAt" json:"createdAt"`
+	UpdatedAt  time.Time   `dynamodbav:"updatedAt" json:"updatedAt"`
+}
+
+// Rejection is a row that was not written, with the fields that failed validation or the reason.
+type Rejection struct {
+	Row    int                  `dynamodbav:"row" json:"row"`
+	Errors []problem.FieldError `dynamodbav:"errors,omitempty" json:"errors,omitempty"`
+	Reason string               `dynamodbav:"reason,omitempty" json:"reason,omitempty"`
+}
+
+func (r Rejection) Error() string {
+	if len(r.Errors) == 0 {
+		return fmt.Sprintf("row %d: %s", r.Row, r.Reason)
+	}
+	fields := make([]string, len(r.Errors))
+	for i, fieldErr := range r.Errors {
+		fields[i] = fieldErr.Field + " " + fieldErr.Message
+	}
+	return fmt.Sprintf("row %d: %s", r.Row, strings.Join(fields, ", "))
+}
+
+// Importer validates rows and writes them to one table in BatchWriteItem chunks.
+type Importer struct {
+	Client    dynamodbClient.DynamodbClient
+	TableName string
+	Store     Store
+	// Rate caps the items written per second; 0 does not limit.
+	Rate int
+	// Reject is called for every rejected row, including those past the report's limit.
+	Reject func(Rejection)
+	// Progress is called after each checkpoint.
+	Progress func(*Import)
+
+	nextWrite time.Time
+}
+
+// TextFields lists the string attributes of a table's model, which CSV cells are read into as they are.
+func TextFields(tableName string) map[string]bool {
+	newItem, ok := Models[tableName]
+	if !ok {
+		return nil
+	}
+	fields := map[string]bool{}
+	t := reflect.TypeOf(newItem()).Elem()
+	for i := 0; i < t.NumField(); i++ {
+		name, _, _ := strings.Cut(t.Field(i).Tag.Get("json"), ",")
+		if name != "" && name != "-" && t.Field(i).Type.Kind() == reflect.String {
+			fields[name] = true
+		}
+	}
+	return fields
+}
+
+// Run imports rows, skipping those imp has already handled. The checkpoint is saved after every
+// batch; puts are idempotent, so a batch repeated after a crash writes the same items again.
+func (im *Importer) Run(ctx context.Context, imp *Import, rows Reader) error {
+	if imp.Done {
+		return nil
+	}
+	if imp.CreatedAt.IsZero() {
+		imp.CreatedAt = time.Now().UTC()
+	}
+	if err := im.save(ctx, imp); err != nil {
+		return err
+	}
+
+	var batch []any
+	var batchRows []int
+	for {
+		row, err := rows.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+		if row.Number <= imp.Row {
+			continue
+		}
+
+		item, rejection := Decode(im.TableName, row)
+		if rejection != nil {
+			im.reject(imp, *rejection)
+		} else {
+			batch = append(batch, item)
+			batchRows = append(batchRows, row.Number)
+		}
+
+		if len(batch) == batchSize {
+			if err := im.flush(ctx, imp, batch, batchRows); err != nil {
+				return err
+			}
+			imp.Row = row.Number
+			if err := im.save(ctx, imp); err != nil {
+				return err
+			}
+			batch, batchRows = nil, nil
+		} else if rejection != nil && len(batch) == 0 {
+			// Nothing is pending, so the checkpoint can move past the rejected row right away.
+			imp.Row = row.Number
+		}
+	}
+
+	if len(batch) > 0 {
+		if err := im.flush(ctx, imp, batch, batchRows); err != nil {
+			return err
+		}
+	}
+	imp.Done = true
+	return im.save(ctx, imp)
+}
+
+// Decode turns a row into the table's model and validates it. Rows of tables without a model are
+// written as they are.
+func Decode(tableName string, row Row) (any, *Rejection) {
+	if row.Err != nil {
+		return nil, &Rejection{Row: row.Number, Reason: row.Err.Error()}
+	}
+	newItem, ok := Models[tableName]
+	if !ok {
+		return row.Data, nil
+	}
+
+	data, err := json.Marshal(row.Data)
+	if err != nil {
+		return nil, &Rejection{Row: row.Number, Reason: err.Error()}
+	}
+	item := newItem()
+	if err := json.Unmarshal(data, item); err != nil {
+		var typeErr *json.UnmarshalTypeError
+		if errors.As(err, &typeErr) {
+			return nil, &Rejection{Row: row.Number, Errors: []problem.FieldError{{Field: typeErr.Field, Message: "must be " + kindName(typeErr.Type.Kind())}}}
+		}
+		return nil, &Rejection{Row: row.Number, Reason: err.Error()}
+	}
+
+	var p *problem.Problem
+	if err := validation.Struct(item); errors.As(err, &p) {
+		return nil, &Rejection{Row: row.Number, Errors: p.Errors}
+	} else if err != nil {
+		return nil, &Rejection{Row: row.Number, Reason: err.Error()}
+	}
+	return item, nil
+}
+
+// flush writes one batch. When DynamoDB refuses the batch as a whole, e.g. for a duplicate key
+// or an oversized item, the rows are written one by one so only the bad ones are rejected.
+func (im *Importer) flush(ctx context.Context, imp *Import, batch []any, rows []int) error {
+	err := im.write(ctx, len(batch), func() error {
+		return im.Client.BatchWriteItem(ctx, im.TableName, batch)
+	})
+	if err == nil {
+		imp.Written += len(batch)
+		return nil
+	}
+
+	var tooLarge *dynamodbClient.ItemTooLargeError
+	if !errors.Is(err, dynamodbClient.ErrValidation) && !errors.As(err, &tooLarge) {
+		return fmt.Errorf("failed to write rows %d to %d: %w", rows[0], rows[len(rows)-1], err)
+	}
+
+	for i, item := range batch {
+		err := im.write(ctx, 1, func() error {
+			return im.Client.TransactWriteItems(ctx, im.TableName, item)
+		})
+		if err == nil {
+			imp.Written++
+			continue
+		}
+		if !errors.Is(err, dynamodbClient.ErrValidation) && !errors.As(err, &tooLarge) {
+			return fmt.Errorf("failed to write row %d: %w", rows[i], err)
+		}
+		im.reject(imp, Rejection{Row: rows[i], Reason: problem.From(err).Detail})
+	}
+	return nil
+}
+
+// write waits for the rate limit, then retries throttled writes with backoff.
+func (im *Importer) write(ctx context.Context, items int, fn func() error) error {
+	if im.Rate > 0 {
+		if wait := time.Until(im.nextWrite); wait > 0 {
+			if err := sleep(ctx, wait); err != nil {
+				return err
+			}
+		}
+		im.nextWrite = time.Now().Add(time.Duration(items) * time.Second / time.Duration(im.Rate))
+	}
+
+	for attempt := 0; ; attempt++ {
+		err := fn()
+		if !errors.Is(err, dynamodbClient.ErrThrottled) || attempt == writeRetries {
+			return err
+		}
+		if err := sleep(ctx, time.Duration(1<<attempt)*time.Second); err != nil {
+			return err
+		}
+	}
+}
+
+func (im *Importer) reject(imp *Import, rejection Rejection) {
+	imp.Rejected++
+	if len(imp.Rejections) < maxRejections {
+		imp.Rejections = append(imp.Rejections, rejection)
+	}
+	if im.Reject != nil {
+		im.Reject(rejection)
+	}
+}
+
+func (im *Importer) save(ctx context.Context, imp *Import) error {
+	imp.UpdatedAt = time.Now().UTC()
+	if err := im.Store.Save(ctx, imp); err != nil {
+		return fmt.Errorf("failed to save the import checkpoint: %w", err)
+	}
+	if im.Progress != nil {
+		im.Progress(imp)
+	}
+	return nil
+}
+
+func kindName(kind reflect.Kind) string {
+	switch kind {
+	case reflect.String:
+		return "a string"
+	case reflect.Bool:
+		return "a boolean"
+	case reflect.Map, reflect.Struct:
+		return "an object"
+	case reflect.Slice, reflect.Array:
+		return "a list"
+	}
+	return "a number"
+}
+
+func sleep(ctx context.Context, d time.Duration) error {
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-time.After(d):
+		return nil
+	}
+}
@@ -0,0 +1,206 @@
+package importer
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"dytest/export"
+)
+
+var numberPattern = regexp.MustCompile(`^-?[0-9]+(\.[0-9]+)?([eE][-+]?[0-9]+)?$`)
+
+// Row is one record of an import file. Number counts from 1 and is what checkpoints refer to.
+// Err is set for a record that could not be parsed; the import rejects it and moves on.
+type Row struct {
+	Number int
+	Data   map[string]any
+	Err    error
+}
+
+// Reader returns rows in file order and io.EOF after the last one.
+type Reader interface {
+	Next() (Row, error)
+}
+
+// NewReader reads NDJSON, a JSON array or CSV. For CSV the header row names the columns; a header
+// listed in columns is read into that column's path, any other header is used as the path itself.
+// text lists the paths whose CSV cells are always kept as strings.
+func NewReader(format export.Format, r io.Reader, columns []export.Column, text map[string]bool) (Reader, error) {
+	switch format {
+	case export.FormatNDJSON:
+		scanner := bufio.NewScanner(r)
+		scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+		return &ndjsonReader{scanner: scanner}, nil
+	case export.FormatJSON:
+		return &jsonReader{dec: json.NewDecoder(r)}, nil
+	case export.FormatCSV:
+		paths := map[string]string{}
+		for _, column := range columns {
+			paths[column.Header] = column.Path
+		}
+		reader := csv.NewReader(r)
+		reader.FieldsPerRecord = -1
+		return &csvReader{csv: reader, paths: paths, text: text}, nil
+	}
+	return nil, fmt.Errorf("unsupported format %q", format)
+}
+
+type ndjsonReader struct {
+	scanner *bufio.Scanner
+	line    int
+}
+
+func (r *ndjsonReader) Next() (Row, error) {
+	for r.scanner.Scan() {
+		r.line++
+		line := strings.TrimSpace(r.scanner.Text())
+		if line == "" {
+			continue
+		}
+		row := Row{Number: r.line}
+		if err := json.Unmarshal([]byte(line), &row.Data); err != nil {
+			row.Err = errors.New("line is not a JSON object")
+		}
+		return row, nil
+	}
+	if err := r.scanner.Err(); err != nil {
+		return Row{}, fmt.Errorf("line %d: %v", r.line+1, err)
+	}
+	return Row{}, io.EOF
+}
+
+// jsonReader decodes one array element at a time, so a large file is never held in memory.
+type jsonReader struct {
+	dec     *json.Decoder
+	started bool
+	index   int
+}
+
+func (r *jsonReader) Next() (Row, error) {
+	if !r.started {
+		r.started = true
+		if token, err := r.dec.Token(); err != nil || token != json.Delim('[') {
+			return Row{}, errors.New("a JSON import must be an array")
+		}
+	}
+	if !r.dec.More() {
+		return Row{}, io.EOF
+	}
+
+	r.index++
+	var raw json.RawMessage
+	if err := r.dec.Decode(&raw); err != nil {
+		return Row{}, fmt.Errorf("element %d: %v", r.index, err)
+	}
+	row := Row{Number: r.index}
+	if err := json.Unmarshal(raw, &row.Data); err != nil {
+		row.Err = errors.New("element is not a JSON object")
+	}
+	return row, nil
+}
+
+type csvReader struct {
+	csv     *csv.Reader
+	paths   map[string]string
+	text    map[string]bool
+	headers []string
+	record  int
+}
+
+func (r *csvReader) Next() (Row, error) {
+	if r.headers == nil {
+		headers, err := r.csv.Read()
+		if err != nil {
+			return Row{}, fmt.Errorf("failed to read the CSV header: %v", err)
+		}
+		r.headers = headers
+	}
+
+	record, err := r.csv.Read()
+	if err == io.EOF {
+		return Row{}, io.EOF
+	}
+	r.record++
+	row := Row{Number: r.record}
+	if err != nil {
+		var parseErr *csv.ParseError
+		if errors.As(err, &parseErr) {
+			row.Err = parseErr.Err
+			return row, nil
+		}
+		return Row{}, err
+	}
+	if len(record) != len(r.headers) {
+		row.Err = fmt.Errorf("has %d fields, the header has %d", len(record), len(r.headers))
+		return row, nil
+	}
+
+	row.Data = map[string]any{}
+	for i, value := range record {
+		if value == "" {
+			continue
+		}
+		path := r.headers[i]
+		if mapped, ok := r.paths[path]; ok {
+			path = mapped
+		}
+		if r.text[path] {
+			set(row.Data, path, value)
+		} else {
+			set(row.Data, path, cellValue(value))
+		}
+	}
+	return row, nil
+}
+
+// cellValue reverses the export's CSV formatting: numbers, booleans, JSON for nested values
+// and "|" between list elements.
+func cellValue(value string) any {
+	if numberPattern.MatchString(value) {
+		if n, err := strconv.ParseFloat(value, 64); err == nil {
+			return n
+		}
+	}
+	switch value {
+	case "true":
+		return true
+	case "false":
+		return false
+	}
+	if strings.HasPrefix(value, "{") || strings.HasPrefix(value, "[") {
+		var v any
+		if json.Unmarshal([]byte(value), &v) == nil {
+			return v
+		}
+	}
+	if strings.Contains(value, "|") {
+		parts := strings.Split(value, "|")
+		list := make([]any, len(parts))
+		for i, part := range parts {
+			list[i] = cellValue(part)
+		}
+		return list
+	}
+	return value
+}
+
+// set stores value at a dotted path, creating the maps on the way.
+func set(data map[string]any, path string, value any) {
+	segments := strings.Split(path, ".")
+	for _, segment := range segments[:len(segments)-1] {
+		next, ok := data[segment].(map[string]any)
+		if !ok {
+			next = map[string]any{}
+			data[segment] = next
+		}
+		data = next
+	}
+	data[segments[len(segments)-1]] = value
+}
@@ -0,0 +1,101 @@
+package importer
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+
+	dynamodbClient "dytest/dynamodb"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+var ErrImportNotFound = errors.New("import not found")
+
+// Store keeps an import's checkpoint between batches.
+type Store interface {
+	Load(ctx context.Context, id string) (*Import, error)
+	Save(ctx context.Context, imp *Import) error
+}
+
+// FileStore keeps one import in a JSON file, for the CLI.
+type FileStore struct {
+	Path string
+}
+
+func (s *FileStore) Load(ctx context.Context, id string) (*Import, error) {
+	data, err := os.ReadFile(s.Path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, ErrImportNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	var imp Import
+	if err := json.Unmarshal(data, &imp); err != nil {
+		return nil, fmt.Errorf("invalid checkpoint %s: %v", s.Path, err)
+	}
+	return &imp, nil
+}
+
+// Save writes a temporary file and renames it, so a crash never leaves half a checkpoint.
+func (s *FileStore) Save(ctx context.Context, imp *Import) error {
+	data, err := json.MarshalIndent(imp, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(s.Path+".tmp", data, 0o644); err != nil {
+		return err
+	}
+	return os.Rename(s.Path+".tmp", s.Path)
+}
+
+// TableStore keeps imports in a DynamoDB table, for the HTTP endpoint. Loads only return
+// imports of the tenant in ctx.
+type TableStore struct {
+	Client    dynamodbClient.DynamodbClient
+	TableName string
+}
+
+func (s *TableStore) EnsureTable(ctx context.Context) error {
+	if _, err := s.Client.DescribeTable(ctx, s.TableName); err == nil {
+		return nil
+	}
+	return s.Client.CreateTable(ctx, s.TableName, &dynamodb.CreateTableInput{
+		AttributeDefinitions: []types.AttributeDefinition{
+			{AttributeName: aws.String("id"), AttributeType: types.ScalarAttributeTypeS},
+		},
+		KeySchema: []types.KeySchemaElement{
+			{AttributeName: aws.String("id"), KeyType: types.KeyTypeHash},
+		},
+		BillingMode: types.BillingModePayPerRequest,
+	})
+}
+
+func (s *TableStore) Load(ctx context.Context, id string) (*Import, error) {
+	tenantID, _ := dynamodbClient.TenantFromContext(ctx)
+
+	key, err := attributevalue.Marshal(id)
+	if err != nil {
+		return nil, err
+	}
+	var imp Import
+	err = s.Client.GetItem(ctx, s.TableName, map[string]types.AttributeValue{"id": key}, &imp)
+	if errors.Is(err, dynamodbClient.ErrNotFound) || (err == nil && imp.Tenant != tenantID) {
+		return nil, ErrImportNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &imp, nil
+}
+
+func (s *TableStore) Save(ctx context.Context, imp *Import) error {
+	imp.Tenant, _ = dynamodbClient.TenantFromContext(ctx)
+	return s.Client.TransactWriteItems(ctx, s.TableName, imp)
+}
@@ -0,0 +1,69 @@
+// Package jobs runs long operations such as table creation, imports and exports in the
+// background. Jobs live in a DynamoDB table, so any server can pick up a job whose runner
+// stopped, and a restarted server resumes the jobs it was running.
+package jobs
+
+import (
+	"encoding/json"
+	"errors"
+	"time"
+)
+
+const (
+	StatusQueued    = "queued"
+	StatusRunning   = "running"
+	StatusSucceeded = "succeeded"
+	StatusFailed    = "failed"
+	StatusCanceled  = "canceled"
+)
+
+// Retention is how long a finished job is kept before DynamoDB expires it.
+const Retention = 7 * 24 * time.Hour
+
+var (
+	ErrJobNotFound = errors.New("job not found")
+	ErrJobFinished = errors.New("job already finished")
+	ErrUnknownKind = errors.New("unknown job kind")
+)
+
+type Job struct {
+	ID     string `dynamodbav:"id" json:"id"`
+	Kind   string `dynamodbav:"kind" json:"kind"`
+	Status string `dynamodbav:"status" json:"status"`
+	// Input is what the job was submitted with; Result is what a succeeded job produced. Both are JSON.
+	Input    json.RawMessage `dynamodbav:"input" json:"-"`
+	Result   json.RawMessage `dynamodbav:"result,omitempty" json:"result,omitempty"`
+	Error    string          `dynamodbav:"error,omitempty" json:"error,omitempty"`
+	Progress Progress        `dynamodbav:"progress" json:"progress"`
+	// CancelRequested asks the runner to stop the job.
+	CancelRequested bool `dynamodbav:"cancelRequested" json:"cancelRequested"`
+	Attempts        int  `dynamodbav:"attempts" json:"attempts"`
+
+	// Owner is the runner holding the job until LeaseUntil, in Unix milliseconds.
+	Owner      string `dynamodbav:"owner,omitempty" json:"-"`
+	LeaseUntil int64  `dynamodbav:"leaseUntil" json:"-"`
+	Tenant     string `dynamodbav:"tenant,omitempty" json:"-"`
+
+	CreatedAt  time.Time  `dynamodbav:"createdAt" json:"createdAt"`
+	UpdatedAt  time.Time  `dynamodbav:"updatedAt" json:"updatedAt"`
+	StartedAt  *time.Time `dynamodbav:"startedAt,omitempty" json:"startedAt,omitempty"`
+	FinishedAt *time.Time `dynamodbav:"finishedAt,omitempty" json:"finishedAt,omitempty"`
+	ExpiresAt  *time.Time `dynamodbav:"expiresAt,unixtime,omitempty" json:"-" ttl:"true"`
+}
+
+// Progress is reported by the job's handler. Total is 0 when it is not known up front.
+type Progress struct {
+	Done    int64  `dynamodbav:"done" json:"done"`
+	Total   int64  `dynamodbav:"total,omitempty" json:"total,omitempty"`
+	Message string `dynamodbav:"message,omitempty" json:"message,omitempty"`
+}
+
+// Finished reports whether the job has stopped for good.
+func (j *Job) Finished() bool {
+	return j.Status == StatusSucceeded || j.Status == StatusFailed || j.Status == StatusCanceled
+}
+
+// Decode unmarshals the job's input into v.
+func (j *Job) Decode(v any) error {
+	return json.Unmarshal(j.Input, v)
+}
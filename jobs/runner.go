@@ -0,0 +1,286 @@
+package jobs
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	dynamodbClient "dytest/dynamodb"
+
+	"github.com/google/uuid"
+)
+
+// maxAttempts is how often a job is started before the runner gives up on it, so a job that
+// crashes its server is not picked up forever.
+const maxAttempts = 3
+
+// Handler runs one job. It should stop when ctx is canceled and may call progress as often as it
+// likes; the runner saves the latest progress with each lease renewal. A handler must be safe to
+// run again for a job it already started: after a restart the job runs from the start.
+type Handler func(ctx context.Context, job *Job, progress func(Progress)) (result any, err error)
+
+// Runner runs jobs on a pool of workers. Submitted jobs start right away on this server; queued
+// jobs and jobs whose runner stopped renewing its lease are found by polling the table.
+type Runner struct {
+	Store   *Store
+	Workers int
+	// PollInterval is how often the table is checked for jobs to run.
+	PollInterval time.Duration
+	// Lease is how long a job stays with its runner without a renewal. It is renewed every third of it.
+	Lease time.Duration
+
+	owner    string
+	handlers map[string]Handler
+	queue    chan string
+	ctx      context.Context
+
+	mu      sync.Mutex
+	running map[string]context.CancelFunc
+}
+
+func (r *Runner) Handle(kind string, handler Handler) {
+	if r.handlers == nil {
+		r.handlers = map[string]Handler{}
+	}
+	r.handlers[kind] = handler
+}
+
+func (r *Runner) Start(ctx context.Context) {
+	r.ctx = ctx
+	r.owner = uuid.NewString()
+	r.queue = make(chan string, 100)
+	r.running = map[string]context.CancelFunc{}
+	for i := 0; i < r.Workers; i++ {
+		go r.work()
+	}
+	go r.poll()
+}
+
+// Submit saves a queued job for the tenant in ctx and hands it to a worker.
+func (r *Runner) Submit(ctx context.Context, kind string, input any) (*Job, error) {
+	if _, ok := r.handlers[kind]; !ok {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownKind, kind)
+	}
+	data, err := json.Marshal(input)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now().UTC()
+	job := &Job{ID: uuid.NewString(), Kind: kind, Status: StatusQueued, Input: data, CreatedAt: now, UpdatedAt: now}
+	job.Tenant, _ = dynamodbClient.TenantFromContext(ctx)
+	if err := r.Store.create(ctx, job); err != nil {
+		return nil, fmt.Errorf("failed to save job: %w", err)
+	}
+	r.enqueue(job.ID)
+	return job, nil
+}
+
+// Cancel stops a job of the tenant in ctx and returns its new state. A running job stops once its
+// runner notices, right away when that is this server.
+func (r *Runner) Cancel(ctx context.Context, id string) (*Job, error) {
+	job, err := r.Store.Load(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if job.Finished() {
+		return job, ErrJobFinished
+	}
+	if err := r.Store.requestCancel(ctx, id); errors.Is(err, dynamodbClient.ErrConditionFailed) {
+		// It finished in the meantime.
+		job, err = r.Store.Load(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+		return job, ErrJobFinished
+	} else if err != nil {
+		return nil, fmt.Errorf("failed to cancel job: %w", err)
+	}
+
+	r.mu.Lock()
+	if cancel, ok := r.running[id]; ok {
+		cancel()
+	}
+	r.mu.Unlock()
+	return r.Store.Load(ctx, id)
+}
+
+func (r *Runner) enqueue(id string) {
+	select {
+	case r.queue <- id:
+	default:
+		// The next poll finds it.
+	}
+}
+
+func (r *Runner) poll() {
+	ticker := time.NewTicker(r.PollInterval)
+	defer ticker.Stop()
+	for {
+		ids, err := r.Store.pending(r.ctx, cap(r.queue))
+		if err != nil {
+			log.Printf("Failed to poll jobs: %v\n", err)
+		}
+		for _, id := range ids {
+			if !r.isRunning(id) {
+				r.enqueue(id)
+			}
+		}
+
+		select {
+		case <-r.ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (r *Runner) work() {
+	for {
+		select {
+		case <-r.ctx.Done():
+			return
+		case id := <-r.queue:
+			r.run(id)
+		}
+	}
+}
+
+func (r *Runner) isRunning(id string) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	_, ok := r.running[id]
+	return ok
+}
+
+func (r *Runner) run(id string) {
+	if err := r.Store.claim(r.ctx, id, r.owner, r.Lease); err != nil {
+		if !errors.Is(err, dynamodbClient.ErrConditionFailed) {
+			log.Printf("Failed to claim job %s: %v\n", id, err)
+		}
+		return
+	}
+	job, err := r.Store.get(r.ctx, id)
+	if err != nil {
+		log.Printf("Failed to load job %s: %v\n", id, err)
+		return
+	}
+
+	ctx := r.ctx
+	if job.Tenant != "" {
+		ctx = dynamodbClient.WithTenant(ctx, job.Tenant)
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	r.mu.Lock()
+	r.running[id] = cancel
+	r.mu.Unlock()
+	defer func() {
+		r.mu.Lock()
+		delete(r.running, id)
+		r.mu.Unlock()
+	}()
+
+	var result any
+	switch handler, ok := r.handlers[job.Kind]; {
+	case !ok:
+		err = fmt.Errorf("%w: %s", ErrUnknownKind, job.Kind)
+	case job.Attempts > maxAttempts:
+		err = fmt.Errorf("gave up after %d attempts", maxAttempts)
+	case job.CancelRequested:
+		err = context.Canceled
+	default:
+		result, err = r.execute(ctx, cancel, job, handler)
+		if err != nil && ctx.Err() != nil {
+			err = context.Canceled
+		}
+	}
+	if r.ctx.Err() != nil {
+		// The server is shutting down; another runner takes over once the lease expires.
+		return
+	}
+	r.finish(job, result, err)
+}
+
+// execute runs the handler while renewing the lease, and cancels it when the job is canceled
+// or another runner has taken it over.
+func (r *Runner) execute(ctx context.Context, cancel context.CancelFunc, job *Job, handler Handler) (result any, err error) {
+	var mu sync.Mutex
+	progress := job.Progress
+	report := func(p Progress) {
+		mu.Lock()
+		progress = p
+		mu.Unlock()
+	}
+
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		ticker := time.NewTicker(r.Lease / 3)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+			}
+			mu.Lock()
+			p := progress
+			mu.Unlock()
+			if err := r.Store.renew(r.ctx, job.ID, r.owner, r.Lease, p); errors.Is(err, dynamodbClient.ErrConditionFailed) {
+				log.Printf("Lost the lease of job %s\n", job.ID)
+				cancel()
+				return
+			} else if err != nil {
+				log.Printf("Failed to renew the lease of job %s: %v\n", job.ID, err)
+			}
+			if current, err := r.Store.get(r.ctx, job.ID); err == nil && current.CancelRequested {
+				cancel()
+			}
+		}
+	}()
+
+	defer func() {
+		if v := recover(); v != nil {
+			err = fmt.Errorf("job panicked: %v", v)
+		}
+		mu.Lock()
+		job.Progress = progress
+		mu.Unlock()
+	}()
+	return handler(ctx, job, report)
+}
+
+func (r *Runner) finish(job *Job, result any, err error) {
+	now := time.Now().UTC()
+	expiresAt := now.Add(Retention)
+	job.FinishedAt, job.UpdatedAt, job.ExpiresAt = &now, now, &expiresAt
+	job.LeaseUntil = 0
+
+	switch {
+	case errors.Is(err, context.Canceled):
+		job.Status = StatusCanceled
+	case err != nil:
+		job.Status = StatusFailed
+		job.Error = err.Error()
+		log.Printf("Job %s (%s) failed: %v\n", job.ID, job.Kind, err)
+	default:
+		job.Status = StatusSucceeded
+		if result != nil {
+			if job.Result, err = json.Marshal(result); err != nil {
+				job.Status, job.Error = StatusFailed, fmt.Sprintf("invalid result: %v", err)
+			}
+		}
+	}
+
+	err = r.Store.finish(r.ctx, job, r.owner)
+	if errors.Is(err, dynamodbClient.ErrConditionFailed) {
+		log.Printf("Job %s was taken over before it finished\n", job.ID)
+	} else if err != nil {
+		log.Printf("Failed to save job %s: %v\n", job.ID, err)
+	}
+}
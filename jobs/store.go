@@ -0,0 +1,187 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	dynamodbClient "dytest/dynamodb"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+// Store keeps jobs in a DynamoDB table. The table is shared by all tenants; Load only returns
+// jobs of the tenant in ctx, the runner's methods see every job.
+type Store struct {
+	Client    dynamodbClient.DynamodbClient
+	TableName string
+}
+
+func (s *Store) EnsureTable(ctx context.Context) error {
+	if _, err := s.Client.DescribeTable(ctx, s.TableName); err == nil {
+		return nil
+	}
+	if err := s.Client.CreateTable(ctx, s.TableName, &dynamodb.CreateTableInput{
+		AttributeDefinitions: []types.AttributeDefinition{
+			{AttributeName: aws.String("id"), AttributeType: types.ScalarAttributeTypeS},
+		},
+		KeySchema: []types.KeySchemaElement{
+			{AttributeName: aws.String("id"), KeyType: types.KeyTypeHash},
+		},
+		BillingMode: types.BillingModePayPerRequest,
+	}); err != nil {
+		return err
+	}
+	if name, ok := dynamodbClient.TTLAttribute(&Job{}); ok {
+		return s.Client.UpdateTimeToLive(ctx, s.TableName, name, true)
+	}
+	return nil
+}
+
+func (s *Store) Load(ctx context.Context, id string) (*Job, error) {
+	tenantID, _ := dynamodbClient.TenantFromContext(ctx)
+	job, err := s.get(ctx, id)
+	if err == nil && job.Tenant != tenantID {
+		return nil, ErrJobNotFound
+	}
+	return job, err
+}
+
+func (s *Store) get(ctx context.Context, id string) (*Job, error) {
+	key, err := jobKey(id)
+	if err != nil {
+		return nil, err
+	}
+	var job Job
+	err = s.Client.GetItem(ctx, s.TableName, key, &job)
+	if errors.Is(err, dynamodbClient.ErrNotFound) {
+		return nil, ErrJobNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &job, nil
+}
+
+func (s *Store) create(ctx context.Context, job *Job) error {
+	return s.Client.TransactWriteItems(ctx, s.TableName, job, dynamodbClient.IfNotExists("id"))
+}
+
+// claim makes owner the runner of a queued job, or of a running job whose runner stopped
+// renewing its lease. It fails with ErrConditionFailed when the job is not up for grabs.
+func (s *Store) claim(ctx context.Context, id, owner string, lease time.Duration) error {
+	key, err := jobKey(id)
+	if err != nil {
+		return err
+	}
+	now := time.Now().UTC()
+	return s.Client.UpdateItem(ctx, s.TableName, key,
+		"SET #status = :running, #owner = :owner, #leaseUntil = :leaseUntil, #attempts = if_not_exists(#attempts, :zero) + :one, "+
+			"#startedAt = if_not_exists(#startedAt, :now), #updatedAt = :now",
+		map[string]any{
+			":running":    StatusRunning,
+			":owner":      owner,
+			":leaseUntil": now.Add(lease).UnixMilli(),
+			":zero":       0,
+			":one":        1,
+			":now":        now,
+		},
+		dynamodbClient.ExpressionNames(map[string]string{
+			"#owner": "owner", "#attempts": "attempts", "#startedAt": "startedAt", "#updatedAt": "updatedAt",
+		}),
+		dynamodbClient.Condition(
+			"#status = :queued OR (#status = :running AND #leaseUntil < :nowMillis)",
+			map[string]string{"#status": "status", "#leaseUntil": "leaseUntil"},
+			map[string]any{":queued": StatusQueued, ":nowMillis": now.UnixMilli()},
+		),
+	)
+}
+
+// renew extends owner's lease and saves the progress. It fails with ErrConditionFailed once
+// another runner has taken the job over.
+func (s *Store) renew(ctx context.Context, id, owner string, lease time.Duration, progress Progress) error {
+	key, err := jobKey(id)
+	if err != nil {
+		return err
+	}
+	now := time.Now().UTC()
+	return s.Client.UpdateItem(ctx, s.TableName, key,
+		"SET #leaseUntil = :leaseUntil, #progress = :progress, #updatedAt = :now",
+		map[string]any{":leaseUntil": now.Add(lease).UnixMilli(), ":progress": progress, ":now": now},
+		dynamodbClient.ExpressionNames(map[string]string{"#leaseUntil": "leaseUntil", "#progress": "progress", "#updatedAt": "updatedAt"}),
+		ownedBy(owner),
+	)
+}
+
+// finish records the outcome of a job owner is running.
+func (s *Store) finish(ctx context.Context, job *Job, owner string) error {
+	return s.Client.TransactWriteItems(ctx, s.TableName, job, ownedBy(owner))
+}
+
+// requestCancel cancels a queued job right away and flags a running one for its runner,
+// which stops it at the next lease renewal.
+func (s *Store) requestCancel(ctx context.Context, id string) error {
+	key, err := jobKey(id)
+	if err != nil {
+		return err
+	}
+	now := time.Now().UTC()
+	err = s.Client.UpdateItem(ctx, s.TableName, key,
+		"SET #status = :canceled, #finishedAt = :now, #updatedAt = :now, #expiresAt = :expiresAt",
+		map[string]any{":canceled": StatusCanceled, ":now": now, ":expiresAt": now.Add(Retention).Unix()},
+		dynamodbClient.ExpressionNames(map[string]string{"#finishedAt": "finishedAt", "#updatedAt": "updatedAt", "#expiresAt": "expiresAt"}),
+		dynamodbClient.Condition("#status = :queued", map[string]string{"#status": "status"}, map[string]any{":queued": StatusQueued}),
+	)
+	if !errors.Is(err, dynamodbClient.ErrConditionFailed) {
+		return err
+	}
+	return s.Client.UpdateItem(ctx, s.TableName, key,
+		"SET #cancelRequested = :true, #updatedAt = :now",
+		map[string]any{":true": true, ":now": now},
+		dynamodbClient.ExpressionNames(map[string]string{"#cancelRequested": "cancelRequested", "#updatedAt": "updatedAt"}),
+		dynamodbClient.Condition("#status = :running", map[string]string{"#status": "status"}, map[string]any{":running": StatusRunning}),
+	)
+}
+
+// pending lists the jobs a runner may claim: queued ones and running ones with an expired lease.
+func (s *Store) pending(ctx context.Context, limit int) ([]string, error) {
+	var ids []string
+	cursor := ""
+	for {
+		var page []struct {
+			ID string `dynamodbav:"id"`
+		}
+		next, err := s.Client.ScanPage(ctx, s.TableName, dynamodbClient.ScanRequest{
+			Filter:     "#status = :queued OR (#status = :running AND #leaseUntil < :now)",
+			Projection: "id",
+			Names:      map[string]string{"#status": "status", "#leaseUntil": "leaseUntil"},
+			Values:     map[string]any{":queued": StatusQueued, ":running": StatusRunning, ":now": time.Now().UnixMilli()},
+			Cursor:     cursor,
+		}, &page)
+		if err != nil {
+			return nil, err
+		}
+		for _, item := range page {
+			ids = append(ids, item.ID)
+		}
+		if next == "" || len(ids) >= limit {
+			return ids, nil
+		}
+		cursor = next
+	}
+}
+
+func ownedBy(owner string) dynamodbClient.WriteOption {
+	return dynamodbClient.Condition("#owner = :owner", map[string]string{"#owner": "owner"}, map[string]any{":owner": owner})
+}
+
+func jobKey(id string) (map[string]types.AttributeValue, error) {
+	key, err := attributevalue.Marshal(id)
+	if err != nil {
+		return nil, err
+	}
+	return map[string]types.AttributeValue{"id": key}, nil
+}
@@ -2,39 +2,170 @@ package main
 
 import (
 	"context"
+	"dytest/config"
 	dynamodbClient "dytest/dynamodb"
-	"dytest/test1"
-	"fmt"
-
-	"github.com/gofiber/fiber/v2"
+	"dytest/events"
+	"dytest/fixtures"
+	"dytest/grpcserver"
+	"dytest/model"
+	"dytest/outbox"
+	"dytest/server"
+	"dytest/stream"
+	"log"
+	"net/http"
+	"os"
+	"time"
 )
 
-type ErrorMessage struct {
-	Error string `json:"error"`
-}
-
 func main() {
-	app := fiber.New()
+	cfg, err := config.Load(os.Args[1:])
+	if err != nil {
+		log.Fatalf("Failed to load config: %v", err)
+	}
+
+	broker := events.NewBroker(cfg.Events.History)
+
+	ttlAttributes, err := dynamodbClient.TTLAttributes(&model.TrendingSnapshot{}, &model.UploadSession{})
+	if err != nil {
+		log.Fatalf("Invalid TTL model: %v", err)
+	}
+	opts := []dynamodbClient.Option{dynamodbClient.WithTTLAttributes(ttlAttributes)}
+	if cfg.Events.Source == "hook" {
+		opts = append(opts, dynamodbClient.WithWriteHook(events.WriteHook(broker)))
+	}
+
+	if cfg.Outbox.Enabled {
+		ob := &outbox.Outbox{TableName: cfg.Outbox.TableName, Tables: cfg.Outbox.Tables}
+		opts = append(opts, dynamodbClient.WithOutbox(ob.Record))
+	}
+
+	// The registry routes each table to its configured client and is used as the client everywhere.
+	client, err := dynamodbClient.NewRegistry(context.Background(), cfg, opts...)
+	if err != nil {
+		log.Fatalf("Failed to create DynamoDB client: %v", err)
+	}
+
+	// Lazy connect starts serving right away and lets /readyz report when DynamoDB is up.
+	if !cfg.Server.LazyConnect {
+		if err := dynamodbClient.WaitForConnection(context.Background(), client, dynamodbClient.DefaultBackoff); err != nil {
+			log.Fatalf("Connection Error: %v", err)
+		}
+	}
+
+	// Seed files create the tables they describe and upsert their items, so a fresh dynamodb-local
+	// is ready to use. With lazy connect, seeding waits for DynamoDB in the background.
+	if len(cfg.Seed.Paths) > 0 {
+		if cfg.Server.LazyConnect {
+			go func() {
+				if err := dynamodbClient.WaitForConnection(context.Background(), client, dynamodbClient.DefaultBackoff); err != nil {
+					log.Printf("Failed to seed: %v", err)
+					return
+				}
+				if err := seed(client, cfg.Seed.Paths); err != nil {
+					log.Printf("Failed to seed: %v", err)
+				}
+			}()
+		} else if err := seed(client, cfg.Seed.Paths); err != nil {
+			log.Fatalf("Failed to seed: %v", err)
+		}
+	}
+
+	if cfg.Streams.Enabled {
+		handler := stream.HandleFunc(func(ctx context.Context, change stream.Change[model.MovieItem]) error {
+			log.Printf("Movies %s %s", change.EventName, change.SequenceNumber)
+			return nil
+		})
+		if cfg.Events.Source == "stream" {
+			var tenantPartitionKey string
+			if table := cfg.Tenant.Tables["Movies"]; table.Mode == dynamodbClient.TenantModePrefix {
+				tenantPartitionKey = table.PartitionKey
+			}
+			handler = events.StreamHandler(broker, "Movies", tenantPartitionKey)
+		}
+		go consumeMovieStream(cfg, client, handler)
+	}
 
-	client, err := dynamodbClient.NewDynamodbClient(context.Background(),"your-profile-name")
+	if cfg.Outbox.Enabled {
+		go relayOutbox(cfg.Outbox, client)
+	}
+
+	srv, err := server.New(cfg, client, broker)
 	if err != nil {
-		fmt.Println("Connection Error")
+		log.Fatalf("Failed to build server: %v", err)
+	}
+	if err := srv.Start(context.Background()); err != nil {
+		log.Fatalf("Failed to start server: %v", err)
+	}
+
+	if cfg.GRPC.Enabled {
+		movies := &grpcserver.MovieServer{Client: client, Webhooks: srv.Webhooks}
+		go func() {
+			if err := grpcserver.Serve(cfg.GRPC.Addr, grpcserver.NewServer(cfg.Tenant, movies)); err != nil {
+				log.Fatalf("gRPC server stopped: %v", err)
+			}
+		}()
+	}
+
+	log.Fatal(srv.App.Listen(cfg.Server.Addr))
+}
+
+func consumeMovieStream(cfg *config.Config, client dynamodbClient.DynamodbClient, handler stream.RecordHandler) {
+	ctx := context.Background()
+	streams, err := stream.NewStreamsClient(ctx, cfg.ClientConfigFor("Movies"))
+	if err != nil {
+		log.Printf("Failed to create streams client: %v", err)
 		return
 	}
 
-	controller := &test1.DynamoDBController2{Client: client}
-	// app.Get("/get",controller.GetTableList)
+	consumer := &stream.Consumer{
+		Client:       client,
+		Streams:      streams,
+		TableName:    "Movies",
+		Leases:       &stream.LeaseStore{Client: client, TableName: cfg.Streams.LeaseTable, Owner: "dytest"},
+		PollInterval: cfg.Streams.PollInterval,
+		Handler:      handler,
+	}
+	if err := consumer.Run(ctx); err != nil {
+		log.Printf("Movie stream consumer stopped: %v", err)
+	}
+}
+
+func relayOutbox(cfg config.OutboxConfig, client dynamodbClient.DynamodbClient) {
+	ctx := context.Background()
+	if err := outbox.EnsureTable(ctx, client, cfg.TableName); err != nil {
+		log.Printf("Failed to create outbox table: %v", err)
+		return
+	}
 
+	var sink outbox.Sink
+	switch cfg.Sink {
+	case "file":
+		sink = &outbox.FileSink{Path: cfg.FilePath}
+	case "webhook":
+		sink = &outbox.WebhookSink{URL: cfg.WebhookURL, Client: &http.Client{Timeout: 10 * time.Second}}
+	default:
+		sink = &outbox.WriterSink{W: os.Stdout}
+	}
 
-	// controller := &DynamoDBController{Client: client}
-	// app.Get("/get-table", controller.GetTableList)
-	// app.Post("/create-table", controller.CreateTable)
-	// app.Post("/delete-table", controller.DeleteTable)
-	app.Post("/save-movie", controller.SaveMovieItem)
-	app.Post("/get-movie", controller.GetMovieItem)
-	app.Get("/scan-movies", controller.ScanMovies)
-	app.Post("/delete-movie", controller.DeleteMovieItem)
-	app.Post("/update-movie", controller.UpdateMovieItem)
+	relay := &outbox.Relay{
+		Client:      client,
+		TableName:   cfg.TableName,
+		Sink:        sink,
+		Interval:    cfg.Interval,
+		MaxAttempts: cfg.MaxAttempts,
+		Backoff:     cfg.Backoff,
+		MaxBackoff:  cfg.MaxBackoff,
+		Retention:   cfg.Retention,
+	}
+	if err := relay.Run(ctx); err != nil {
+		log.Printf("Outbox relay stopped: %v", err)
+	}
+}
 
-	app.Listen(":3000")
+func seed(client dynamodbClient.DynamodbClient, paths []string) error {
+	results, err := fixtures.Load(context.Background(), client, paths...)
+	for _, result := range results {
+		log.Printf("Seeded %s from %s: %d items, table created: %v", result.Table, result.Path, result.Items, result.Created)
+	}
+	return err
 }
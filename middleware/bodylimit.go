@@ -0,0 +1,41 @@
+package middleware
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"slices"
+
+	"dytest/problem"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// BodyLimit refuses request bodies larger than limit, except on the routes listed as
+// "METHOD /path", which read the body as a stream and enforce their own limit. It is meant for
+// an app with StreamRequestBody set, where fasthttp streams a body larger than the app's
+// BodyLimit, or one sent in chunks, instead of refusing it.
+func BodyLimit(limit int, except ...string) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if slices.Contains(except, c.Method()+" "+c.Path()) {
+			return c.Next()
+		}
+
+		tooLarge := problem.Status(http.StatusRequestEntityTooLarge, fmt.Sprintf("The request body is larger than %d bytes", limit))
+		if c.Request().Header.ContentLength() > limit {
+			return tooLarge
+		}
+		// A chunked body has no length up front; read at most one byte past the limit.
+		if stream := c.Context().RequestBodyStream(); stream != nil && c.Request().Header.ContentLength() < 0 {
+			body, err := io.ReadAll(io.LimitReader(stream, int64(limit)+1))
+			if err != nil {
+				return err
+			}
+			if len(body) > limit {
+				return tooLarge
+			}
+			c.Request().SetBody(body)
+		}
+		return c.Next()
+	}
+}
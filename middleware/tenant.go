@@ -0,0 +1,117 @@
+package middleware
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"dytest/config"
+	dynamodbClient "dytest/dynamodb"
+	"dytest/problem"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+var tenantIDPattern = regexp.MustCompile(`^[A-Za-z0-9_-]{1,64}$`)
+
+// Tenant puts the caller's tenant into the request's user context, where the DynamodbClient picks it up.
+// Requests without a tenant pass through; tenant-scoped tables then refuse them.
+func Tenant(cfg config.TenantConfig) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		tenantID, err := TenantID(cfg, c.Get(fiber.HeaderAuthorization), c.Get(cfg.Header))
+		if err != nil {
+			return err
+		}
+		if tenantID == "" {
+			return c.Next()
+		}
+
+		c.SetUserContext(dynamodbClient.WithTenant(c.UserContext(), tenantID))
+		return c.Next()
+	}
+}
+
+// TenantID picks the tenant from the Authorization and tenant header values of a request.
+// It returns "" when there is none, and a problem for a bad token or tenant id.
+func TenantID(cfg config.TenantConfig, authorization, header string) (string, error) {
+	tenantID, err := tenantFromRequest(cfg, authorization, header)
+	if err != nil {
+		return "", problem.New(http.StatusUnauthorized, problem.CodeUnauthorized, err.Error())
+	}
+	if tenantID != "" && !tenantIDPattern.MatchString(tenantID) {
+		return "", problem.BadRequest("Invalid tenant id")
+	}
+	return tenantID, nil
+}
+
+// tenantFromRequest only trusts the token when a JWT claim is configured: a tenant header sent
+// without one is refused rather than believed.
+func tenantFromRequest(cfg config.TenantConfig, authorization, header string) (string, error) {
+	if cfg.JWTClaim != "" {
+		token, ok := strings.CutPrefix(authorization, "Bearer ")
+		switch {
+		case ok:
+			return tenantFromJWT(token, cfg.JWTClaim, []byte(cfg.JWTSecret))
+		case authorization != "" || header != "":
+			return "", errors.New("a bearer token is required")
+		}
+		return "", nil
+	}
+	if cfg.Header != "" {
+		return header, nil
+	}
+	return "", nil
+}
+
+// tenantFromJWT verifies an HS256 token and returns the string claim.
+func tenantFromJWT(token, claim string, secret []byte) (string, error) {
+	if len(secret) == 0 {
+		return "", errors.New("jwt secret is not configured")
+	}
+
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return "", errors.New("malformed token")
+	}
+
+	var header struct {
+		Alg string `json:"alg"`
+	}
+	if err := decodeSegment(parts[0], &header); err != nil || header.Alg != "HS256" {
+		return "", errors.New("unsupported token")
+	}
+
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(parts[0] + "." + parts[1]))
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil || !hmac.Equal(signature, mac.Sum(nil)) {
+		return "", errors.New("invalid token signature")
+	}
+
+	var claims map[string]any
+	if err := decodeSegment(parts[1], &claims); err != nil {
+		return "", errors.New("malformed token")
+	}
+	if exp, ok := claims["exp"].(float64); ok && time.Now().Unix() >= int64(exp) {
+		return "", errors.New("token expired")
+	}
+
+	tenantID, ok := claims[claim].(string)
+	if !ok || tenantID == "" {
+		return "", errors.New("token has no tenant claim")
+	}
+	return tenantID, nil
+}
+
+func decodeSegment(segment string, v any) error {
+	data, err := base64.RawURLEncoding.DecodeString(segment)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, v)
+}
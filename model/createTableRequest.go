@@ -3,7 +3,7 @@ package model
 import "github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
 
 type CreateTableRequest struct {
-	TableName            string                      `json:"table_name"`
-	AttributeDefinitions []types.AttributeDefinition `json:"attribute_definitions"`
-	KeySchema            []types.KeySchemaElement    `json:"key_schema"`
+	TableName            string                      `json:"table_name" validate:"required,tablename"`
+	AttributeDefinitions []types.AttributeDefinition `json:"attribute_definitions" validate:"required,min=1"`
+	KeySchema            []types.KeySchemaElement    `json:"key_schema" validate:"required,min=1,max=2"`
 }
@@ -1,5 +1,5 @@
 package model
 
 type DeleteTableRequest struct {
-	TableName string `json:"tableName"`
+	TableName string `json:"tableName" validate:"required,tablename"`
 }
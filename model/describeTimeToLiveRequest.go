@@ -0,0 +1,5 @@
+package model
+
+type DescribeTimeToLiveRequest struct {
+	TableName string `json:"tableName" validate:"required,tablename"`
+}
@@ -1,9 +1,10 @@
 package model
 
 type MovieGetItem struct {
-	TableName string `json:"tableName"`
-	Title     string `json:"title"`
-	Year      int    `json:"year"`
+	// TableName is ignored: the deprecated routes only read the Movies table.
+	TableName string `json:"tableName,omitempty"`
+	Title     string `json:"title" validate:"required,max=500"`
+	Year      int    `json:"year" validate:"required,gte=1870,lte=2100"`
 }
 
 type MovieGetItem2 struct {
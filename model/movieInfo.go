@@ -0,0 +1,14 @@
+package model
+
+// MovieInfo is the schema of MovieItem.Info, after the fields of the AWS sample movie data.
+type MovieInfo struct {
+	Directors       []string `json:"directors" validate:"omitempty,max=20,dive,required,max=200"`
+	ReleaseDate     string   `json:"release_date" validate:"omitempty,datetime=2006-01-02T15:04:05Z07:00"`
+	Rating          *float64 `json:"rating" validate:"omitempty,gte=0,lte=10"`
+	Genres          []string `json:"genres" validate:"omitempty,max=10,dive,oneof=Action Adventure Animation Biography Comedy Crime Documentary Drama Family Fantasy Film-Noir History Horror Music Musical Mystery Romance Sci-Fi Sport Thriller War Western"`
+	ImageURL        string   `json:"image_url" validate:"omitempty,url"`
+	Plot            string   `json:"plot" validate:"omitempty,max=2000"`
+	Rank            *int     `json:"rank" validate:"omitempty,gte=1"`
+	RunningTimeSecs *int     `json:"running_time_secs" validate:"omitempty,gte=1,lte=86400"`
+	Actors          []string `json:"actors" validate:"omitempty,max=50,dive,required,max=200"`
+}
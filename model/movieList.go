@@ -0,0 +1,6 @@
+package model
+
+type MovieList struct {
+	Items      []MovieItem `json:"items"`
+	NextCursor string      `json:"nextCursor,omitempty"`
+}
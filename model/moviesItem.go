@@ -1,7 +1,16 @@
 package model
 
 type MovieItem struct {
-	Title string                 `dynamodbav:"title"`
-	Year  int                    `dynamodbav:"year"`
-	Info  map[string]interface{} `dynamodbav:"info"`
+	Title string                 `dynamodbav:"title" json:"title" validate:"required,max=500"`
+	Year  int                    `dynamodbav:"year" json:"year" validate:"required,gte=1870,lte=2100"`
+	Info  map[string]interface{} `dynamodbav:"info" json:"info" validate:"omitempty,schema=MovieInfo"`
+}
+
+func (m *MovieItem) TableName() string {
+	return "Movies"
+}
+
+// UpdatableFields is the allowlist for partial updates; the key attributes are not on it.
+func (m *MovieItem) UpdatableFields() []string {
+	return []string{"info"}
 }
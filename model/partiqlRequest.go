@@ -0,0 +1,12 @@
+package model
+
+type PartiQLRequest struct {
+	Statement  string `json:"statement" validate:"required,max=8192"`
+	Parameters []any  `json:"parameters"`
+	// AllowScan lets a statement through that has no condition on the partition key.
+	AllowScan bool `json:"allowScan"`
+}
+
+type PartiQLResponse struct {
+	Items []map[string]any `json:"items"`
+}
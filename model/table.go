@@ -0,0 +1,13 @@
+package model
+
+// Table is implemented by models that know which DynamoDB table they live in.
+type Table interface {
+	TableName() string
+}
+
+// Updatable is implemented by models that accept partial updates. UpdatableFields lists the
+// attributes a patch may change; a listed map attribute may be changed at any depth.
+type Updatable interface {
+	Table
+	UpdatableFields() []string
+}
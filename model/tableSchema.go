@@ -0,0 +1,124 @@
+package model
+
+import (
+	"fmt"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+// TableSchema describes a table in a schema file, e.g. for `dytest tables create`.
+type TableSchema struct {
+	TableName    string        `json:"tableName" yaml:"tableName" validate:"required,tablename"`
+	PartitionKey KeyAttribute  `json:"partitionKey" yaml:"partitionKey" validate:"required"`
+	SortKey      *KeyAttribute `json:"sortKey,omitempty" yaml:"sortKey,omitempty"`
+	// ReadCapacity and WriteCapacity provision the table; without them it is billed per request.
+	ReadCapacity  int64         `json:"readCapacity,omitempty" yaml:"readCapacity,omitempty"`
+	WriteCapacity int64         `json:"writeCapacity,omitempty" yaml:"writeCapacity,omitempty"`
+	Indexes       []IndexSchema `json:"indexes,omitempty" yaml:"indexes,omitempty" validate:"dive"`
+	// TTLAttribute enables time to live on this attribute.
+	TTLAttribute string `json:"ttlAttribute,omitempty" yaml:"ttlAttribute,omitempty"`
+}
+
+type KeyAttribute struct {
+	Name string `json:"name" yaml:"name" validate:"required"`
+	// Type is S, N or B.
+	Type string `json:"type" yaml:"type" validate:"required,oneof=S N B"`
+}
+
+// IndexSchema is a global secondary index, or a local one when Local is set.
+type IndexSchema struct {
+	Name         string        `json:"name" yaml:"name" validate:"required"`
+	PartitionKey KeyAttribute  `json:"partitionKey" yaml:"partitionKey" validate:"required"`
+	SortKey      *KeyAttribute `json:"sortKey,omitempty" yaml:"sortKey,omitempty"`
+	Local        bool          `json:"local,omitempty" yaml:"local,omitempty"`
+	// Projection is ALL (the default), KEYS_ONLY or INCLUDE with NonKeyAttributes.
+	Projection       string   `json:"projection,omitempty" yaml:"projection,omitempty" validate:"omitempty,oneof=ALL KEYS_ONLY INCLUDE"`
+	NonKeyAttributes []string `json:"nonKeyAttributes,omitempty" yaml:"nonKeyAttributes,omitempty"`
+}
+
+// CreateTableInput builds the request for the schema; CreateTable sets the table name.
+func (s *TableSchema) CreateTableInput() (*dynamodb.CreateTableInput, error) {
+	input := &dynamodb.CreateTableInput{KeySchema: keySchema(s.PartitionKey, s.SortKey)}
+
+	attributeTypes := map[string]types.ScalarAttributeType{}
+	define := func(attribute *KeyAttribute) error {
+		if attribute == nil {
+			return nil
+		}
+		attributeType := types.ScalarAttributeType(attribute.Type)
+		if existing, ok := attributeTypes[attribute.Name]; ok && existing != attributeType {
+			return fmt.Errorf("attribute %s is declared as both %s and %s", attribute.Name, existing, attributeType)
+		}
+		attributeTypes[attribute.Name] = attributeType
+		return nil
+	}
+	if err := define(&s.PartitionKey); err != nil {
+		return nil, err
+	}
+	if err := define(s.SortKey); err != nil {
+		return nil, err
+	}
+
+	input.BillingMode = types.BillingModePayPerRequest
+	if s.ReadCapacity > 0 || s.WriteCapacity > 0 {
+		input.BillingMode = types.BillingModeProvisioned
+		input.ProvisionedThroughput = s.throughput()
+	}
+
+	for _, index := range s.Indexes {
+		if err := define(&index.PartitionKey); err != nil {
+			return nil, err
+		}
+		if err := define(index.SortKey); err != nil {
+			return nil, err
+		}
+		projection := &types.Projection{ProjectionType: types.ProjectionTypeAll}
+		if index.Projection != "" {
+			projection.ProjectionType = types.ProjectionType(index.Projection)
+			projection.NonKeyAttributes = index.NonKeyAttributes
+		}
+
+		if index.Local {
+			input.LocalSecondaryIndexes = append(input.LocalSecondaryIndexes, types.LocalSecondaryIndex{
+				IndexName:  aws.String(index.Name),
+				KeySchema:  keySchema(index.PartitionKey, index.SortKey),
+				Projection: projection,
+			})
+			continue
+		}
+		gsi := types.GlobalSecondaryIndex{
+			IndexName:  aws.String(index.Name),
+			KeySchema:  keySchema(index.PartitionKey, index.SortKey),
+			Projection: projection,
+		}
+		if input.BillingMode == types.BillingModeProvisioned {
+			gsi.ProvisionedThroughput = s.throughput()
+		}
+		input.GlobalSecondaryIndexes = append(input.GlobalSecondaryIndexes, gsi)
+	}
+
+	for name, attributeType := range attributeTypes {
+		input.AttributeDefinitions = append(input.AttributeDefinitions, types.AttributeDefinition{
+			AttributeName: aws.String(name),
+			AttributeType: attributeType,
+		})
+	}
+	return input, nil
+}
+
+func (s *TableSchema) throughput() *types.ProvisionedThroughput {
+	return &types.ProvisionedThroughput{
+		ReadCapacityUnits:  aws.Int64(max(s.ReadCapacity, 1)),
+		WriteCapacityUnits: aws.Int64(max(s.WriteCapacity, 1)),
+	}
+}
+
+func keySchema(partitionKey KeyAttribute, sortKey *KeyAttribute) []types.KeySchemaElement {
+	schema := []types.KeySchemaElement{{AttributeName: aws.String(partitionKey.Name), KeyType: types.KeyTypeHash}}
+	if sortKey != nil {
+		schema = append(schema, types.KeySchemaElement{AttributeName: aws.String(sortKey.Name), KeyType: types.KeyTypeRange})
+	}
+	return schema
+}
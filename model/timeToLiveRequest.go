@@ -0,0 +1,7 @@
+package model
+
+type TimeToLiveRequest struct {
+	TableName     string `json:"tableName" validate:"required,tablename"`
+	AttributeName string `json:"attributeName" validate:"required,max=255"`
+	Enabled       bool   `json:"enabled"`
+}
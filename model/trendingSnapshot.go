@@ -0,0 +1,14 @@
+package model
+
+import "time"
+
+// TrendingSnapshot is a short-lived ranking that DynamoDB removes after ExpiresAt.
+type TrendingSnapshot struct {
+	ID        string      `dynamodbav:"id" json:"id"`
+	Movies    []MovieItem `dynamodbav:"movies" json:"movies"`
+	ExpiresAt time.Time   `dynamodbav:"expiresAt,unixtime" json:"expiresAt" ttl:"true"`
+}
+
+func (m *TrendingSnapshot) TableName() string {
+	return "TrendingSnapshots"
+}
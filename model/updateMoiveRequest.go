@@ -1,9 +1,8 @@
 package model
 
 type UpdateMovie struct {
-	TableName                 string                 `json:"tableName"`
-	Title                     string                 `json:"title"`
-	Year                      int                    `json:"year"`
-	UpdateExpression          string                 `json:"updateExpression"`
-	ExpressionAttributeValues map[string]interface{} `json:"expressionAttributeValues"`
-}
\ No newline at end of file
+	Title                     string                 `json:"title" validate:"required,max=500"`
+	Year                      int                    `json:"year" validate:"required,gte=1870,lte=2100"`
+	UpdateExpression          string                 `json:"updateExpression" validate:"required,updateexpr"`
+	ExpressionAttributeValues map[string]interface{} `json:"expressionAttributeValues" validate:"required"`
+}
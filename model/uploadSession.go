@@ -0,0 +1,14 @@
+package model
+
+import "time"
+
+// UploadSession tracks an in-progress upload; DynamoDB removes it after ExpiresAt.
+type UploadSession struct {
+	ID        string    `dynamodbav:"id" json:"id"`
+	FileName  string    `dynamodbav:"fileName" json:"fileName"`
+	ExpiresAt time.Time `dynamodbav:"expiresAt,unixtime" json:"expiresAt" ttl:"true"`
+}
+
+func (m *UploadSession) TableName() string {
+	return "UploadSessions"
+}
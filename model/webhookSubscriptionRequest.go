@@ -0,0 +1,7 @@
+package model
+
+type WebhookSubscriptionRequest struct {
+	URL    string   `json:"url" validate:"required,http_url"`
+	Events []string `json:"events" validate:"omitempty,dive,oneof=movie.created movie.updated movie.deleted"`
+	Secret string   `json:"secret" validate:"omitempty,min=16,max=256"`
+}
@@ -0,0 +1,34 @@
+package openapi
+
+import (
+	_ "embed"
+	"encoding/json"
+	"fmt"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+//go:embed docs.html
+var docsPage []byte
+
+// Register serves the document at /openapi.json and a Redoc page at /docs. It must be called after
+// every other route is registered. Routes without an Operation are listed without details.
+func Register(app *fiber.App, operations map[string]Operation) error {
+	var spec []byte
+	app.Get("/openapi.json", func(c *fiber.Ctx) error {
+		c.Set(fiber.HeaderContentType, fiber.MIMEApplicationJSONCharsetUTF8)
+		return c.Send(spec)
+	})
+	app.Get("/docs", func(c *fiber.Ctx) error {
+		c.Set(fiber.HeaderContentType, fiber.MIMETextHTMLCharsetUTF8)
+		return c.Send(docsPage)
+	})
+
+	doc, _ := Build(app.GetRoutes(true), operations)
+
+	var err error
+	if spec, err = json.Marshal(doc); err != nil {
+		return fmt.Errorf("failed to encode OpenAPI document: %v", err)
+	}
+	return nil
+}
@@ -0,0 +1,48 @@
+package openapi_test
+
+import (
+	"strings"
+	"testing"
+
+	"dytest/config"
+	"dytest/dynamodb/memory"
+	"dytest/events"
+	"dytest/openapi"
+	"dytest/server"
+)
+
+// newServer builds the app with every optional route group turned on.
+func newServer(t *testing.T) *server.Server {
+	t.Helper()
+	cfg := config.Default()
+	cfg.Webhooks.Enabled = true
+	cfg.PartiQL.Enabled = true
+	cfg.Jobs.DataDir = t.TempDir()
+
+	srv, err := server.New(cfg, memory.New(), events.NewBroker(10))
+	if err != nil {
+		t.Fatalf("failed to build server: %v", err)
+	}
+	return srv
+}
+
+func TestEveryRouteHasAnOperation(t *testing.T) {
+	srv := newServer(t)
+
+	_, missing := openapi.Build(srv.App.GetRoutes(true), openapi.Operations)
+	if len(missing) > 0 {
+		t.Errorf("routes without an OpenAPI operation: %s", strings.Join(missing, ", "))
+	}
+}
+
+func TestEveryOperationHasARoute(t *testing.T) {
+	srv := newServer(t)
+
+	doc, _ := openapi.Build(srv.App.GetRoutes(true), openapi.Operations)
+	for key := range openapi.Operations {
+		method, path, _ := strings.Cut(key, " ")
+		if _, ok := doc.Paths[path][strings.ToLower(method)]; !ok {
+			t.Errorf("operation %s has no route", key)
+		}
+	}
+}
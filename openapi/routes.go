@@ -0,0 +1,285 @@
+package openapi
+
+import (
+	"net/http"
+
+	"dytest/graphqlapi"
+	"dytest/importer"
+	"dytest/jobs"
+	"dytest/model"
+	"dytest/patch"
+	"dytest/test1"
+	"dytest/webhook"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/gofiber/fiber/v2"
+)
+
+const text = fiber.MIMETextPlainCharsetUTF8
+
+var (
+	jsonBody = func(v any) map[string]any { return map[string]any{fiber.MIMEApplicationJSON: v} }
+
+	yearParam  = Param{Name: "year", In: "path", Type: "integer"}
+	titleParam = Param{Name: "title", In: "path", Description: "URL-encoded title"}
+	asyncParam = Param{Name: "Prefer", In: "header", Description: "wait=<seconds> answers with the result instead of a job"}
+	accepted   = Response{Status: http.StatusAccepted, Body: jobs.Job{}, Description: "The job; Location is /jobs/{id}"}
+
+	listParams = []Param{
+		{Name: "limit", In: "query", Type: "integer", Description: "Page size, 1 to 100"},
+		{Name: "cursor", In: "query"},
+		{Name: "year", In: "query", Type: "integer"},
+		{Name: "titlePrefix", In: "query"},
+	}
+)
+
+// Operations documents every route package server registers; the package tests fail on a route without an entry.
+var Operations = map[string]Operation{
+	"GET /healthz": {
+		Summary: "Liveness probe", Tag: "health",
+		Responses: []Response{{Status: http.StatusOK, Body: test1.HealthStatus{}}},
+	},
+	"GET /readyz": {
+		Summary: "Readiness probe: DynamoDB is reachable and the required tables are active", Tag: "health",
+		Responses: []Response{
+			{Status: http.StatusOK, Body: test1.HealthStatus{}},
+			{Status: http.StatusServiceUnavailable, Description: "DynamoDB or a required table is not available"},
+		},
+	},
+
+	"GET /movies": {
+		Summary: "List movies", Tag: "movies",
+		Description: "Queries one year with year, otherwise scans. Pass nextCursor back as cursor for the next page.",
+		Params:      listParams,
+		Responses:   []Response{{Status: http.StatusOK, Body: model.MovieList{}}},
+	},
+	"POST /movies": {
+		Summary: "Create a movie", Tag: "movies",
+		Bodies: jsonBody(model.MovieItem{}),
+		Responses: []Response{
+			{Status: http.StatusCreated, Body: model.MovieItem{}},
+			{Status: http.StatusConflict, Description: "The movie already exists"},
+		},
+	},
+	"GET /movies/{year}/{title}": {
+		Summary: "Get a movie", Tag: "movies",
+		Params:    []Param{yearParam, titleParam},
+		Responses: []Response{{Status: http.StatusOK, Body: model.MovieItem{}}},
+	},
+	"PUT /movies/{year}/{title}": {
+		Summary: "Create or replace a movie", Tag: "movies",
+		Params: []Param{yearParam, titleParam},
+		Bodies: jsonBody(model.MovieItem{}),
+		Responses: []Response{
+			{Status: http.StatusOK, Body: model.MovieItem{}},
+			{Status: http.StatusCreated, Body: model.MovieItem{}},
+		},
+	},
+	"PATCH /movies/{year}/{title}": {
+		Summary: "Partially update a movie", Tag: "movies",
+		Description: "Accepts a JSON Merge Patch (RFC 7396) or a JSON Patch (RFC 6902). Only info can be changed. " +
+			"JSON Patch tests must come before the operations that change their paths.",
+		Params: []Param{yearParam, titleParam},
+		Bodies: map[string]any{
+			patch.MergePatchContentType: model.MovieItem{},
+			patch.JSONPatchContentType:  []patch.Operation{},
+		},
+		Responses: []Response{
+			{Status: http.StatusOK, Body: model.MovieItem{}},
+			{Status: http.StatusConflict, Description: "A test fails, or a replaced or removed member or an added member's parent is missing"},
+		},
+	},
+	"DELETE /movies/{year}/{title}": {
+		Summary: "Delete a movie", Tag: "movies",
+		Params:    []Param{yearParam, titleParam},
+		Responses: []Response{{Status: http.StatusNoContent}},
+	},
+	"GET /movies/events": {
+		Summary: "Stream movie changes as Server-Sent Events", Tag: "movies",
+		Description: "Resume with the id of the last event seen. Ids start a new epoch when the server restarts; " +
+			"an id from an earlier epoch replays every event the server still has.",
+		Params: []Param{
+			{Name: "Last-Event-ID", In: "header"},
+			{Name: "lastEventId", In: "query"},
+		},
+		Responses: []Response{{Status: http.StatusOK, Body: "", ContentType: "text/event-stream"}},
+	},
+	"GET /movies/export": {
+		Summary: "Export movies", Tag: "movies",
+		Description: "Streams every matching movie a page at a time as NDJSON, CSV or a JSON array. " +
+			"CSV flattens nested attributes into the columns given as path or header=path.",
+		Params: []Param{
+			{Name: "format", In: "query", Description: "ndjson (default), csv or json"},
+			{Name: "year", In: "query", Type: "integer", Description: "Export one year's partition"},
+			{Name: "filter", In: "query", Description: "Comma-separated conditions such as year>=2000,info.rating>7; ^= is begins_with"},
+			{Name: "fields", In: "query", Description: "Comma-separated attribute paths to include"},
+			{Name: "columns", In: "query", Description: "Comma-separated CSV columns, each path or header=path"},
+			asyncParam,
+		},
+		Responses: []Response{
+			{Status: http.StatusOK, Body: "", ContentType: "application/x-ndjson"},
+			{Status: http.StatusOK, Body: "", ContentType: "text/csv"},
+			{Status: http.StatusOK, Body: []model.MovieItem{}},
+			accepted,
+		},
+	},
+
+	"POST /imports": {
+		Summary: "Import rows from an NDJSON, CSV or JSON array file", Tag: "imports",
+		Description: "Rows are validated against the table's model and written in batches. Rejected rows are " +
+			"listed in the report. If the import fails, send the same file again with the id from Location to resume.",
+		Params: []Param{
+			{Name: "table", In: "query", Description: "Defaults to Movies"},
+			{Name: "format", In: "query", Description: "ndjson, csv or json; taken from Content-Type when missing"},
+			{Name: "id", In: "query", Description: "Resume this import"},
+			asyncParam,
+		},
+		Bodies: map[string]any{
+			"application/x-ndjson":    "",
+			"text/csv":                "",
+			fiber.MIMEApplicationJSON: []model.MovieItem{},
+		},
+		Responses: []Response{
+			{Status: http.StatusOK, Body: importer.Import{}},
+			accepted,
+			{Status: http.StatusConflict, Description: "The import to resume has another table or format"},
+		},
+	},
+	"GET /imports/{id}": {
+		Summary: "Get an import's checkpoint and report", Tag: "imports",
+		Params:    []Param{{Name: "id", In: "path"}},
+		Responses: []Response{{Status: http.StatusOK, Body: importer.Import{}}},
+	},
+
+	"GET /jobs/{id}": {
+		Summary: "Get a job's status and progress", Tag: "jobs",
+		Description: "A succeeded job has a result: the table's status, the import report or the exported item count.",
+		Params:      []Param{{Name: "id", In: "path"}},
+		Responses:   []Response{{Status: http.StatusOK, Body: jobs.Job{}}},
+	},
+	"POST /jobs/{id}/cancel": {
+		Summary: "Cancel a job", Tag: "jobs",
+		Description: "A queued job is canceled right away, a running one stops shortly after.",
+		Params:      []Param{{Name: "id", In: "path"}},
+		Responses: []Response{
+			{Status: http.StatusAccepted, Body: jobs.Job{}},
+			{Status: http.StatusConflict, Description: "The job has already finished"},
+		},
+	},
+	"GET /jobs/{id}/result": {
+		Summary: "Download the file of a succeeded export job", Tag: "jobs",
+		Params: []Param{{Name: "id", In: "path"}},
+		Responses: []Response{
+			{Status: http.StatusOK, Body: "", ContentType: "application/x-ndjson"},
+			{Status: http.StatusOK, Body: "", ContentType: "text/csv"},
+			{Status: http.StatusOK, Body: []model.MovieItem{}},
+			{Status: http.StatusConflict, Description: "The job has not succeeded"},
+		},
+	},
+
+	"POST /save-movie": {
+		Summary: "Save a movie", Tag: "movies (deprecated)", Deprecated: true,
+		Bodies:    jsonBody(model.MovieItem{}),
+		Responses: []Response{{Status: http.StatusCreated, Body: "", ContentType: text}},
+	},
+	"POST /get-movie": {
+		Summary: "Get a movie", Tag: "movies (deprecated)", Deprecated: true,
+		Bodies:    jsonBody(model.MovieGetItem{}),
+		Responses: []Response{{Status: http.StatusOK, Body: model.MovieGetItem2{}}},
+	},
+	"GET /scan-movies": {
+		Summary: "List movies", Tag: "movies (deprecated)", Deprecated: true,
+		Description: "The same pages as GET /movies.",
+		Params:      listParams,
+		Responses:   []Response{{Status: http.StatusOK, Body: model.MovieList{}}},
+	},
+	"POST /delete-movie": {
+		Summary: "Delete a movie", Tag: "movies (deprecated)", Deprecated: true,
+		Bodies:    jsonBody(model.MovieGetItem{}),
+		Responses: []Response{{Status: http.StatusOK, Body: "", ContentType: text}},
+	},
+	"POST /update-movie": {
+		Summary: "Update a movie's info", Tag: "movies (deprecated)", Deprecated: true,
+		Bodies:    jsonBody(model.UpdateMovie{}),
+		Responses: []Response{{Status: http.StatusOK, Body: "", ContentType: text}},
+	},
+
+	"GET /get-table": {
+		Summary: "List tables", Tag: "tables",
+		Responses: []Response{{Status: http.StatusOK, Body: []string{}}},
+	},
+	"POST /create-table": {
+		Summary: "Create a table", Tag: "tables",
+		Description: "Creates the table in a job; send Prefer: wait to wait until the table is active instead, which can take minutes.",
+		Params:      []Param{asyncParam},
+		Bodies:      jsonBody(model.CreateTableRequest{}),
+		Responses:   []Response{{Status: http.StatusCreated, Body: "", ContentType: text}, accepted},
+	},
+	"POST /delete-table": {
+		Summary: "Delete a table", Tag: "tables",
+		Bodies:    jsonBody(model.DeleteTableRequest{}),
+		Responses: []Response{{Status: http.StatusOK, Body: "", ContentType: text}},
+	},
+	"POST /update-table-ttl": {
+		Summary: "Enable or disable time to live on a table", Tag: "tables",
+		Bodies:    jsonBody(model.TimeToLiveRequest{}),
+		Responses: []Response{{Status: http.StatusOK, Body: "", ContentType: text}},
+	},
+	"POST /describe-table-ttl": {
+		Summary: "Describe a table's time to live", Tag: "tables",
+		Bodies:    jsonBody(model.DescribeTimeToLiveRequest{}),
+		Responses: []Response{{Status: http.StatusOK, Body: types.TimeToLiveDescription{}}},
+	},
+
+	"POST /webhooks": {
+		Summary: "Register a webhook", Tag: "webhooks",
+		Description: "The response carries the signing secret; it is not returned again. URLs on loopback, " +
+			"link-local or private addresses are refused.",
+		Bodies:    jsonBody(model.WebhookSubscriptionRequest{}),
+		Responses: []Response{{Status: http.StatusCreated, Body: webhook.Subscription{}}},
+	},
+	"GET /webhooks": {
+		Summary: "List webhooks", Tag: "webhooks",
+		Responses: []Response{{Status: http.StatusOK, Body: []webhook.Subscription{}}},
+	},
+	"DELETE /webhooks/{id}": {
+		Summary: "Delete a webhook", Tag: "webhooks",
+		Responses: []Response{{Status: http.StatusOK, Body: "", ContentType: text}},
+	},
+	"GET /webhooks/{id}/deliveries": {
+		Summary: "List a webhook's deliveries", Tag: "webhooks",
+		Params:    []Param{{Name: "status", In: "query", Description: "e.g. dead_lettered"}},
+		Responses: []Response{{Status: http.StatusOK, Body: []webhook.Delivery{}}},
+	},
+
+	"GET /graphql": {
+		Summary: "Run a GraphQL query", Tag: "graphql",
+		Params: []Param{
+			{Name: "query", In: "query", Required: true},
+			{Name: "operationName", In: "query"},
+			{Name: "variables", In: "query", Description: "JSON object"},
+		},
+		Responses: []Response{{Status: http.StatusOK, Body: graphqlapi.Response{}}},
+	},
+	"POST /graphql": {
+		Summary: "Run a GraphQL query or mutation", Tag: "graphql",
+		Description: "Movie lookups by key within one request are batched into BatchGetItem calls.",
+		Bodies:      jsonBody(graphqlapi.Request{}),
+		Responses:   []Response{{Status: http.StatusOK, Body: graphqlapi.Response{}}},
+	},
+
+	"POST /partiql": {
+		Summary: "Run a PartiQL statement", Tag: "admin",
+		Bodies:    jsonBody(model.PartiQLRequest{}),
+		Responses: []Response{{Status: http.StatusOK, Body: model.PartiQLResponse{}}},
+	},
+
+	"GET /openapi.json": {
+		Summary: "This document", Tag: "docs",
+		Responses: []Response{{Status: http.StatusOK, Body: map[string]any{}}},
+	},
+	"GET /docs": {
+		Summary: "API reference page", Tag: "docs",
+		Responses: []Response{{Status: http.StatusOK, Body: "", ContentType: fiber.MIMETextHTML}},
+	},
+}
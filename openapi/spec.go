@@ -0,0 +1,266 @@
+package openapi
+
+import (
+	"fmt"
+	"net/http"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	"dytest/problem"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+var (
+	paramPattern = regexp.MustCompile(`:([A-Za-z0-9_]+)\??`)
+	wordPattern  = regexp.MustCompile(`[A-Za-z0-9]+`)
+)
+
+// Operation documents one route. Operations are keyed by method and OpenAPI path,
+// e.g. "GET /movies/{year}/{title}".
+type Operation struct {
+	Summary     string
+	Description string
+	Tag         string
+	Deprecated  bool
+	Params      []Param
+	// Bodies maps each accepted request content type to a value of the body's type.
+	Bodies    map[string]any
+	Responses []Response
+}
+
+type Param struct {
+	Name        string
+	In          string
+	Description string
+	Type        string
+	Required    bool
+}
+
+type Response struct {
+	Status      int
+	Description string
+	Body        any
+	// ContentType defaults to application/json when there is a body.
+	ContentType string
+}
+
+type Document struct {
+	OpenAPI    string              `json:"openapi"`
+	Info       Info                `json:"info"`
+	Paths      map[string]PathItem `json:"paths"`
+	Components Components          `json:"components"`
+	Tags       []Tag               `json:"tags,omitempty"`
+}
+
+type Info struct {
+	Title   string `json:"title"`
+	Version string `json:"version"`
+}
+
+type Components struct {
+	Schemas map[string]*Schema `json:"schemas"`
+}
+
+type Tag struct {
+	Name string `json:"name"`
+}
+
+type PathItem map[string]*OperationObject
+
+type OperationObject struct {
+	OperationID string                    `json:"operationId"`
+	Summary     string                    `json:"summary,omitempty"`
+	Description string                    `json:"description,omitempty"`
+	Tags        []string                  `json:"tags,omitempty"`
+	Deprecated  bool                      `json:"deprecated,omitempty"`
+	Parameters  []ParameterObject         `json:"parameters,omitempty"`
+	RequestBody *RequestBodyObject        `json:"requestBody,omitempty"`
+	Responses   map[string]ResponseObject `json:"responses"`
+}
+
+type ParameterObject struct {
+	Name        string  `json:"name"`
+	In          string  `json:"in"`
+	Description string  `json:"description,omitempty"`
+	Required    bool    `json:"required"`
+	Schema      *Schema `json:"schema"`
+}
+
+type RequestBodyObject struct {
+	Required bool                 `json:"required"`
+	Content  map[string]MediaType `json:"content"`
+}
+
+type MediaType struct {
+	Schema *Schema `json:"schema,omitempty"`
+}
+
+type ResponseObject struct {
+	Description string               `json:"description"`
+	Content     map[string]MediaType `json:"content,omitempty"`
+}
+
+// Build documents the registered routes. It returns the routes that have no Operation,
+// which the document then lists without details.
+func Build(routes []fiber.Route, operations map[string]Operation) (*Document, []string) {
+	s := &schemas{components: map[string]*Schema{}}
+	doc := &Document{
+		OpenAPI: "3.1.0",
+		Info:    Info{Title: "dytest", Version: "1.0.0"},
+		Paths:   map[string]PathItem{},
+	}
+	problemSchema := s.of(problem.Problem{})
+
+	var missing []string
+	tags := map[string]bool{}
+	for _, route := range routes {
+		if route.Method == fiber.MethodHead || route.Method == fiber.MethodOptions {
+			continue
+		}
+		path := Path(route.Path)
+		key := route.Method + " " + path
+		if doc.Paths[path] == nil {
+			doc.Paths[path] = PathItem{}
+		}
+		if _, ok := doc.Paths[path][strings.ToLower(route.Method)]; ok {
+			continue
+		}
+
+		operation, ok := operations[key]
+		if !ok {
+			missing = append(missing, key)
+		}
+		if operation.Tag != "" {
+			tags[operation.Tag] = true
+		}
+		doc.Paths[path][strings.ToLower(route.Method)] = operation.object(s, key, route.Params, problemSchema)
+	}
+
+	doc.Components = Components{Schemas: s.components}
+	for tag := range tags {
+		doc.Tags = append(doc.Tags, Tag{Name: tag})
+	}
+	slices.SortFunc(doc.Tags, func(a, b Tag) int { return strings.Compare(a.Name, b.Name) })
+	slices.Sort(missing)
+	return doc, missing
+}
+
+func (o Operation) object(s *schemas, key string, pathParams []string, problemSchema *Schema) *OperationObject {
+	object := &OperationObject{
+		OperationID: operationID(key),
+		Summary:     o.Summary,
+		Description: o.Description,
+		Deprecated:  o.Deprecated,
+		Responses:   map[string]ResponseObject{},
+	}
+	if o.Tag != "" {
+		object.Tags = []string{o.Tag}
+	}
+
+	for _, name := range pathParams {
+		param := Param{Name: name, In: "path", Type: "string", Required: true}
+		if i := slices.IndexFunc(o.Params, func(p Param) bool { return p.Name == name && p.In == "path" }); i >= 0 {
+			param = o.Params[i]
+			param.Required = true
+		}
+		object.Parameters = append(object.Parameters, param.object())
+	}
+	for _, param := range o.Params {
+		if param.In != "path" {
+			object.Parameters = append(object.Parameters, param.object())
+		}
+	}
+
+	if len(o.Bodies) > 0 {
+		object.RequestBody = &RequestBodyObject{Required: true, Content: map[string]MediaType{}}
+		for contentType, body := range o.Bodies {
+			object.RequestBody.Content[contentType] = MediaType{Schema: s.of(body)}
+		}
+	}
+
+	for _, response := range o.Responses {
+		description := response.Description
+		if description == "" {
+			description = http.StatusText(response.Status)
+		}
+		// Responses with the same status are alternative content types of one response.
+		r, ok := object.Responses[strconv.Itoa(response.Status)]
+		if !ok {
+			r = ResponseObject{Description: description}
+		}
+		if response.Body != nil {
+			contentType := response.ContentType
+			if contentType == "" {
+				contentType = fiber.MIMEApplicationJSON
+			}
+			if r.Content == nil {
+				r.Content = map[string]MediaType{}
+			}
+			r.Content[contentType] = MediaType{Schema: s.of(response.Body)}
+		}
+		object.Responses[strconv.Itoa(response.Status)] = r
+	}
+	if len(object.Responses) == 0 {
+		object.Responses["200"] = ResponseObject{Description: "OK"}
+	}
+	object.Responses["default"] = ResponseObject{
+		Description: "Error",
+		Content:     map[string]MediaType{problem.ContentType: {Schema: problemSchema}},
+	}
+	return object
+}
+
+func (p Param) object() ParameterObject {
+	schemaType := p.Type
+	if schemaType == "" {
+		schemaType = "string"
+	}
+	return ParameterObject{
+		Name:        p.Name,
+		In:          p.In,
+		Description: p.Description,
+		Required:    p.Required,
+		Schema:      &Schema{Type: schemaType},
+	}
+}
+
+// Path turns a Fiber route path into an OpenAPI one: /movies/:year/:title becomes /movies/{year}/{title}.
+func Path(route string) string {
+	return paramPattern.ReplaceAllString(route, "{$1}")
+}
+
+// operationID derives a stable ID from the key: "GET /movies/{year}/{title}" becomes getMoviesYearTitle.
+func operationID(key string) string {
+	method, path, _ := strings.Cut(key, " ")
+	id := strings.ToLower(method)
+	for _, word := range wordPattern.FindAllString(path, -1) {
+		id += strings.ToUpper(word[:1]) + word[1:]
+	}
+	return id
+}
+
+// CheckCoverage fails when a client misses a non-deprecated operation with one of tags,
+// or lists an operation that does not exist.
+func CheckCoverage(operations map[string]Operation, covered []string, tags ...string) error {
+	var missing, unknown []string
+	for key, operation := range operations {
+		if !operation.Deprecated && slices.Contains(tags, operation.Tag) && !slices.Contains(covered, key) {
+			missing = append(missing, key)
+		}
+	}
+	for _, key := range covered {
+		if _, ok := operations[key]; !ok {
+			unknown = append(unknown, key)
+		}
+	}
+	if len(missing) == 0 && len(unknown) == 0 {
+		return nil
+	}
+
+	slices.Sort(missing)
+	slices.Sort(unknown)
+	return fmt.Errorf("not covered: %s; unknown: %s", strings.Join(missing, ", "), strings.Join(unknown, ", "))
+}
@@ -0,0 +1,146 @@
+package outbox
+
+import (
+	"context"
+	dynamodbClient "dytest/dynamodb"
+	"encoding/json"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/google/uuid"
+)
+
+const (
+	StatusPending = "pending"
+	StatusDone    = "done"
+	StatusFailed  = "failed"
+)
+
+// PendingIndex is a sparse index of the records waiting for delivery, by when they are due.
+const PendingIndex = "pending"
+
+// Record is one pending notification. Payload is the JSON of the write as seen by the client.
+type Record struct {
+	ID        string     `dynamodbav:"id" json:"id"`
+	Topic     string     `dynamodbav:"topic" json:"topic"`
+	TableName string     `dynamodbav:"tableName" json:"tableName"`
+	Op        string     `dynamodbav:"op" json:"op"`
+	Tenant    string     `dynamodbav:"tenant,omitempty" json:"tenant,omitempty"`
+	Payload   string     `dynamodbav:"payload" json:"payload"`
+	Status    string     `dynamodbav:"status" json:"status"`
+	Attempts  int        `dynamodbav:"attempts" json:"attempts"`
+	LastError string     `dynamodbav:"lastError,omitempty" json:"lastError,omitempty"`
+	CreatedAt time.Time  `dynamodbav:"createdAt" json:"createdAt"`
+	ExpiresAt *time.Time `dynamodbav:"expiresAt,unixtime,omitempty" json:"-" ttl:"true"`
+
+	// Pending and NextAttemptAt, in Unix milliseconds, key PendingIndex. Both are dropped once the
+	// record is delivered or given up on, which takes it out of the index.
+	Pending       string `dynamodbav:"pending,omitempty" json:"-"`
+	NextAttemptAt int64  `dynamodbav:"nextAttemptAt,omitempty" json:"-"`
+}
+
+type payload struct {
+	Key              map[string]any `json:"key,omitempty"`
+	Item             map[string]any `json:"item,omitempty"`
+	UpdateExpression string         `json:"updateExpression,omitempty"`
+	Values           map[string]any `json:"values,omitempty"`
+}
+
+// Outbox turns writes to the watched tables into records of its own table.
+type Outbox struct {
+	TableName string
+	Tables    []string
+}
+
+// Record is a dynamodbClient.OutboxFunc; pass it with dynamodbClient.WithOutbox.
+func (o *Outbox) Record(ctx context.Context, event dynamodbClient.WriteEvent) (string, any, error) {
+	if !o.watches(event.TableName) {
+		return "", nil, nil
+	}
+
+	var p payload
+	var err error
+	if p.Key, err = toMap(event.Key); err != nil {
+		return "", nil, err
+	}
+	if p.Item, err = toMap(event.Item); err != nil {
+		return "", nil, err
+	}
+	if p.Values, err = toMap(event.Values); err != nil {
+		return "", nil, err
+	}
+	p.UpdateExpression = event.UpdateExpression
+
+	data, err := json.Marshal(p)
+	if err != nil {
+		return "", nil, err
+	}
+
+	tenantID, _ := dynamodbClient.TenantFromContext(ctx)
+	now := time.Now().UTC()
+	return o.TableName, Record{
+		ID:            uuid.NewString(),
+		Topic:         event.TableName + "." + event.Op,
+		TableName:     event.TableName,
+		Op:            event.Op,
+		Tenant:        tenantID,
+		Payload:       string(data),
+		Status:        StatusPending,
+		CreatedAt:     now,
+		Pending:       StatusPending,
+		NextAttemptAt: now.UnixMilli(),
+	}, nil
+}
+
+func (o *Outbox) watches(tableName string) bool {
+	for _, t := range o.Tables {
+		if t == tableName {
+			return true
+		}
+	}
+	return false
+}
+
+// EnsureTable creates the outbox table with PendingIndex, and TTL on expiresAt so delivered
+// records are cleaned up.
+func EnsureTable(ctx context.Context, client dynamodbClient.DynamodbClient, tableName string) error {
+	if _, err := client.DescribeTable(ctx, tableName); err == nil {
+		return nil
+	}
+	err := client.CreateTable(ctx, tableName, &dynamodb.CreateTableInput{
+		AttributeDefinitions: []types.AttributeDefinition{
+			{AttributeName: aws.String("id"), AttributeType: types.ScalarAttributeTypeS},
+			{AttributeName: aws.String("pending"), AttributeType: types.ScalarAttributeTypeS},
+			{AttributeName: aws.String("nextAttemptAt"), AttributeType: types.ScalarAttributeTypeN},
+		},
+		KeySchema: []types.KeySchemaElement{
+			{AttributeName: aws.String("id"), KeyType: types.KeyTypeHash},
+		},
+		GlobalSecondaryIndexes: []types.GlobalSecondaryIndex{{
+			IndexName: aws.String(PendingIndex),
+			KeySchema: []types.KeySchemaElement{
+				{AttributeName: aws.String("pending"), KeyType: types.KeyTypeHash},
+				{AttributeName: aws.String("nextAttemptAt"), KeyType: types.KeyTypeRange},
+			},
+			Projection: &types.Projection{ProjectionType: types.ProjectionTypeAll},
+		}},
+		BillingMode: types.BillingModePayPerRequest,
+	})
+	if err != nil {
+		return err
+	}
+	attributeName, _ := dynamodbClient.TTLAttribute(Record{})
+	return client.UpdateTimeToLive(ctx, tableName, attributeName, true)
+}
+
+func toMap(av map[string]types.AttributeValue) (map[string]any, error) {
+	if av == nil {
+		return nil, nil
+	}
+	var m map[string]any
+	err := attributevalue.UnmarshalMap(av, &m)
+	return m, err
+}
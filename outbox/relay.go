@@ -0,0 +1,115 @@
+package outbox
+
+import (
+	"context"
+	dynamodbClient "dytest/dynamodb"
+	"log"
+	"sort"
+	"time"
+)
+
+// Relay delivers pending records to a sink. Records are marked done only after the sink
+// accepted them, so a crash in between redelivers: sinks must tolerate duplicates by ID.
+type Relay struct {
+	Client      dynamodbClient.DynamodbClient
+	TableName   string
+	Sink        Sink
+	Interval    time.Duration
+	MaxAttempts int
+	// Backoff is the delay after the first failed attempt; it doubles up to MaxBackoff.
+	Backoff    time.Duration
+	MaxBackoff time.Duration
+	// Retention is how long delivered records stay in the table before TTL removes them.
+	Retention time.Duration
+}
+
+func (r *Relay) Run(ctx context.Context) error {
+	for {
+		if err := r.RunOnce(ctx); err != nil {
+			log.Printf("Outbox relay failed: %v\n", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(r.Interval):
+		}
+	}
+}
+
+// RunOnce delivers every record that is due, oldest first.
+func (r *Relay) RunOnce(ctx context.Context) error {
+	records, err := r.pending(ctx)
+	if err != nil {
+		return err
+	}
+	sort.Slice(records, func(i, j int) bool {
+		return records[i].CreatedAt.Before(records[j].CreatedAt)
+	})
+
+	for _, record := range records {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if err := r.deliver(ctx, record); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// pending queries PendingIndex for the records that are due; delivered records are not in it.
+func (r *Relay) pending(ctx context.Context) ([]Record, error) {
+	var records []Record
+	query := dynamodbClient.QueryRequest{
+		IndexName:    PendingIndex,
+		KeyCondition: "#pending = :pending AND #nextAttemptAt <= :now",
+		Names:        map[string]string{"#pending": "pending", "#nextAttemptAt": "nextAttemptAt"},
+		Values:       map[string]any{":pending": StatusPending, ":now": time.Now().UnixMilli()},
+	}
+	for {
+		var page []Record
+		cursor, err := r.Client.Query(ctx, r.TableName, query, &page)
+		if err != nil {
+			return nil, err
+		}
+		records = append(records, page...)
+		if cursor == "" {
+			return records, nil
+		}
+		query.Cursor = cursor
+	}
+}
+
+func (r *Relay) deliver(ctx context.Context, record Record) error {
+	record.Attempts++
+	err := r.Sink.Deliver(ctx, record)
+
+	now := time.Now().UTC()
+	switch {
+	case err == nil:
+		record.Status = StatusDone
+		record.LastError = ""
+		expiresAt := now.Add(r.Retention)
+		record.ExpiresAt = &expiresAt
+		record.Pending, record.NextAttemptAt = "", 0
+	case record.Attempts >= r.MaxAttempts:
+		log.Printf("Giving up on outbox record %s after %d attempts: %v\n", record.ID, record.Attempts, err)
+		record.Status = StatusFailed
+		record.LastError = err.Error()
+		record.Pending, record.NextAttemptAt = "", 0
+	default:
+		record.LastError = err.Error()
+		record.NextAttemptAt = now.Add(r.backoff(record.Attempts)).UnixMilli()
+	}
+
+	return r.Client.TransactWriteItems(ctx, r.TableName, record)
+}
+
+func (r *Relay) backoff(attempts int) time.Duration {
+	delay := r.Backoff << (attempts - 1)
+	if delay <= 0 || delay > r.MaxBackoff {
+		return r.MaxBackoff
+	}
+	return delay
+}
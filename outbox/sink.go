@@ -0,0 +1,103 @@
+package outbox
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"sync"
+)
+
+type Sink interface {
+	Deliver(ctx context.Context, record Record) error
+}
+
+// WriterSink writes one JSON line per record, e.g. to os.Stdout.
+type WriterSink struct {
+	mu sync.Mutex
+	W  io.Writer
+}
+
+func (s *WriterSink) Deliver(ctx context.Context, record Record) error {
+	data, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	_, err = s.W.Write(append(data, '\n'))
+	return err
+}
+
+// FileSink appends JSON lines to a file and syncs after each record.
+type FileSink struct {
+	mu   sync.Mutex
+	Path string
+}
+
+func (s *FileSink) Deliver(ctx context.Context, record Record) error {
+	data, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	f, err := os.OpenFile(s.Path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	if _, err := f.Write(append(data, '\n')); err != nil {
+		return err
+	}
+	return f.Sync()
+}
+
+// WebhookSink POSTs the record as JSON. The record ID is sent as Idempotency-Key for deduplication.
+type WebhookSink struct {
+	URL    string
+	Client *http.Client
+}
+
+func (s *WebhookSink) Deliver(ctx context.Context, record Record) error {
+	data, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.URL, bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Idempotency-Key", record.ID)
+
+	client := s.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook answered %s", resp.Status)
+	}
+	return nil
+}
+
+// ChannelSink hands records to an in-process consumer and waits until it takes them.
+type ChannelSink chan Record
+
+func (s ChannelSink) Deliver(ctx context.Context, record Record) error {
+	select {
+	case s <- record:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,488 @@
+package patch
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	dynamodbClient "dytest/dynamodb"
+	"dytest/problem"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+const (
+	MergePatchContentType = "application/merge-patch+json"
+	JSONPatchContentType  = "application/json-patch+json"
+)
+
+var indexPattern = regexp.MustCompile(`^(0|[1-9][0-9]*)$`)
+
+// Change is one edit of an item attribute.
+type Change struct {
+	Path   []string
+	Value  any
+	Remove bool
+	// Append adds Value to the end of the list at Path.
+	Append bool
+	// MustExist fails the update unless Path is already set, as JSON Patch's replace requires.
+	MustExist bool
+	// Object makes Path an empty object unless it already is one, as {} does in a merge patch.
+	// Value is an empty map.
+	Object bool
+	// Pointer marks a path read from a JSON Pointer. Only there are segments of digits list
+	// indexes, and the change follows RFC 6902: a removed member and an added member's parent
+	// must exist.
+	Pointer bool
+}
+
+// Test fails the whole update unless the attribute at Path equals Value.
+type Test struct {
+	Path    []string
+	Value   any
+	Pointer bool
+}
+
+type Patch struct {
+	Changes []Change
+	Tests   []Test
+}
+
+// Operation is one entry of an RFC 6902 JSON Patch document.
+type Operation struct {
+	Op    string `json:"op"`
+	Path  string `json:"path"`
+	Value any    `json:"value"`
+	From  string `json:"from"`
+}
+
+// FromMergePatch reads an RFC 7396 merge patch: null removes a member,
+// an object is merged into the member and anything else replaces it.
+func FromMergePatch(doc map[string]any) Patch {
+	var p Patch
+	mergeChanges(&p, nil, doc)
+	return p
+}
+
+func mergeChanges(p *Patch, parent []string, doc map[string]any) {
+	keys := make([]string, 0, len(doc))
+	for key := range doc {
+		keys = append(keys, key)
+	}
+	slices.Sort(keys)
+
+	for _, key := range keys {
+		path := append(slices.Clone(parent), key)
+		switch value := doc[key].(type) {
+		case nil:
+			p.Changes = append(p.Changes, Change{Path: path, Remove: true})
+		case map[string]any:
+			if len(value) == 0 {
+				p.Changes = append(p.Changes, Change{Path: path, Value: map[string]any{}, Object: true})
+				continue
+			}
+			mergeChanges(p, path, value)
+		default:
+			p.Changes = append(p.Changes, Change{Path: path, Value: value})
+		}
+	}
+}
+
+// FromJSONPatch reads an RFC 6902 JSON Patch. add, replace, remove and test are supported;
+// move and copy cannot be expressed as a single update and are refused. Tests are checked
+// against the item before the update, so a test that follows a change to its path is refused.
+func FromJSONPatch(operations []Operation) (Patch, []problem.FieldError) {
+	var p Patch
+	var fields []problem.FieldError
+
+	for i, operation := range operations {
+		field := fmt.Sprintf("operations[%d]", i)
+		path, err := parsePointer(operation.Path)
+		if err != nil {
+			fields = append(fields, problem.FieldError{Field: field + ".path", Message: err.Error()})
+			continue
+		}
+
+		last := path[len(path)-1]
+		switch operation.Op {
+		case "add":
+			switch {
+			case last == "-":
+				p.Changes = append(p.Changes, Change{Path: path[:len(path)-1], Value: []any{operation.Value}, Append: true, Pointer: true})
+			case indexPattern.MatchString(last):
+				fields = append(fields, problem.FieldError{Field: field + ".path", Message: "inserting into a list is not supported; append with /- or use replace"})
+			default:
+				p.Changes = append(p.Changes, Change{Path: path, Value: operation.Value, Pointer: true})
+			}
+		case "replace":
+			p.Changes = append(p.Changes, Change{Path: path, Value: operation.Value, MustExist: true, Pointer: true})
+		case "remove":
+			p.Changes = append(p.Changes, Change{Path: path, Remove: true, Pointer: true})
+		case "test":
+			// Tests are checked before the update, so one must not follow a change it would see.
+			changed := slices.IndexFunc(p.Changes, func(c Change) bool { return isPrefix(c.Path, path) || isPrefix(path, c.Path) })
+			if changed >= 0 {
+				fields = append(fields, problem.FieldError{Field: field + ".path", Message: "follows the change to " + FieldName(p.Changes[changed].Path, true) + "; put tests first"})
+				continue
+			}
+			p.Tests = append(p.Tests, Test{Path: path, Value: operation.Value, Pointer: true})
+		case "move", "copy":
+			fields = append(fields, problem.FieldError{Field: field + ".op", Message: "is not supported"})
+		default:
+			fields = append(fields, problem.FieldError{Field: field + ".op", Message: "must be one of: add, replace, remove, test"})
+		}
+	}
+	return p, fields
+}
+
+var clausePattern = regexp.MustCompile(`(?:^|\s)(SET|REMOVE)\s`)
+
+// FromUpdateExpression reads the SET and REMOVE actions of an update expression that has passed
+// the updateexpr validator, looking each value up by its placeholder. Its changes are merged into
+// the item like a merge patch's.
+func FromUpdateExpression(expression string, values map[string]any) (Patch, []problem.FieldError) {
+	var p Patch
+	var fields []problem.FieldError
+
+	clauses := clausePattern.FindAllStringSubmatchIndex(expression, -1)
+	for i, clause := range clauses {
+		end := len(expression)
+		if i+1 < len(clauses) {
+			end = clauses[i+1][0]
+		}
+		keyword := expression[clause[2]:clause[3]]
+		for _, action := range strings.Split(expression[clause[1]:end], ",") {
+			name, placeholder, _ := strings.Cut(action, "=")
+			path := strings.Split(strings.TrimSpace(name), ".")
+			if keyword == "REMOVE" {
+				p.Changes = append(p.Changes, Change{Path: path, Remove: true})
+				continue
+			}
+			placeholder = strings.TrimSpace(placeholder)
+			value, ok := values[placeholder]
+			if !ok {
+				fields = append(fields, problem.FieldError{Field: "expressionAttributeValues", Message: "has no value for " + placeholder})
+				continue
+			}
+			p.Changes = append(p.Changes, Change{Path: path, Value: value})
+		}
+	}
+	return p, fields
+}
+
+// parsePointer splits an RFC 6901 JSON Pointer into unescaped segments.
+func parsePointer(pointer string) ([]string, error) {
+	if pointer == "" || pointer == "/" {
+		return nil, errors.New("must point below the document root")
+	}
+	if !strings.HasPrefix(pointer, "/") {
+		return nil, errors.New("must start with /")
+	}
+
+	segments := strings.Split(pointer[1:], "/")
+	for i, segment := range segments {
+		if segment == "" {
+			return nil, errors.New("must not have empty segments")
+		}
+		segments[i] = strings.ReplaceAll(strings.ReplaceAll(segment, "~1", "/"), "~0", "~")
+	}
+	return segments, nil
+}
+
+// Check refuses changes outside the allowlist and changes that overlap, which DynamoDB
+// cannot apply in one update.
+// An allowed attribute may be changed at any depth.
+func (p Patch) Check(allowed []string) []problem.FieldError {
+	var fields []problem.FieldError
+	if len(p.Changes) == 0 {
+		fields = append(fields, problem.FieldError{Field: "body", Message: "the patch changes nothing"})
+	}
+
+	for _, change := range p.Changes {
+		if !slices.Contains(allowed, change.Path[0]) {
+			fields = append(fields, problem.FieldError{Field: FieldName(change.Path, change.Pointer), Message: "cannot be changed"})
+		}
+	}
+	for _, test := range p.Tests {
+		if !slices.Contains(allowed, test.Path[0]) {
+			fields = append(fields, problem.FieldError{Field: FieldName(test.Path, test.Pointer), Message: "cannot be changed"})
+		}
+	}
+
+	for i, a := range p.Changes {
+		for _, b := range p.Changes[i+1:] {
+			if isPrefix(a.Path, b.Path) || isPrefix(b.Path, a.Path) {
+				fields = append(fields, problem.FieldError{Field: FieldName(b.Path, b.Pointer), Message: "overlaps the change to " + FieldName(a.Path, a.Pointer)})
+			}
+		}
+	}
+	return fields
+}
+
+// FieldName renders a path the way validation errors name fields, e.g. info.genres[0]. Segments
+// of digits are list indexes only in a JSON Pointer.
+func FieldName(path []string, pointer bool) string {
+	var b strings.Builder
+	for i, segment := range path {
+		switch {
+		case i > 0 && pointer && indexPattern.MatchString(segment):
+			b.WriteString("[" + segment + "]")
+		case i > 0:
+			b.WriteString("." + segment)
+		default:
+			b.WriteString(segment)
+		}
+	}
+	return b.String()
+}
+
+func isPrefix(prefix, path []string) bool {
+	return len(prefix) <= len(path) && slices.Equal(prefix, path[:len(prefix)])
+}
+
+// Update is a patch rendered as DynamoDB expressions, with every name and value behind a placeholder.
+type Update struct {
+	Expression string
+	Condition  string
+	Names      map[string]string
+	Values     map[string]any
+}
+
+func (p Patch) Update() *Update {
+	return p.update(nil)
+}
+
+// guard is a map attribute a merge patch writes into, or the missing one it sets as a whole.
+// The update fails if that has changed since the item was read.
+type guard struct {
+	Path  []string
+	IsMap bool
+}
+
+func (p Patch) update(guards []guard) *Update {
+	u := &Update{Names: map[string]string{}, Values: map[string]any{}}
+	var sets, removes, conditions []string
+
+	for _, change := range p.Changes {
+		path := u.path(change.Path, change.Pointer)
+		switch {
+		case change.Remove:
+			removes = append(removes, path)
+		case change.Append:
+			sets = append(sets, fmt.Sprintf("%s = list_append(%s, %s)", path, path, u.value(change.Value)))
+		case change.Object:
+			// Apply folds these; alone, the update can only create a missing object.
+			sets = append(sets, fmt.Sprintf("%s = if_not_exists(%s, %s)", path, path, u.value(change.Value)))
+		default:
+			sets = append(sets, path+" = "+u.value(change.Value))
+		}
+		switch {
+		case change.MustExist || (change.Pointer && change.Remove):
+			conditions = append(conditions, "attribute_exists("+path+")")
+		case change.Pointer && change.Append:
+			conditions = append(conditions, fmt.Sprintf("attribute_type(%s, %s)", path, u.value("L")))
+		case change.Pointer && len(change.Path) > 1:
+			// add at an index is refused, so the parent of an added member is an object.
+			parent := u.path(change.Path[:len(change.Path)-1], true)
+			conditions = append(conditions, fmt.Sprintf("attribute_type(%s, %s)", parent, u.value("M")))
+		}
+	}
+	for _, test := range p.Tests {
+		conditions = append(conditions, u.path(test.Path, test.Pointer)+" = "+u.value(test.Value))
+	}
+	for _, g := range guards {
+		condition := fmt.Sprintf("attribute_type(%s, %s)", u.path(g.Path, false), u.value("M"))
+		if !g.IsMap {
+			condition = "NOT " + condition
+		}
+		conditions = append(conditions, condition)
+	}
+
+	var clauses []string
+	if len(sets) > 0 {
+		clauses = append(clauses, "SET "+strings.Join(sets, ", "))
+	}
+	if len(removes) > 0 {
+		clauses = append(clauses, "REMOVE "+strings.Join(removes, ", "))
+	}
+	u.Expression = strings.Join(clauses, " ")
+	u.Condition = strings.Join(conditions, " AND ")
+	return u
+}
+
+func (u *Update) path(segments []string, pointer bool) string {
+	var b strings.Builder
+	for i, segment := range segments {
+		if i > 0 && pointer && indexPattern.MatchString(segment) {
+			b.WriteString("[" + segment + "]")
+			continue
+		}
+		placeholder := u.name(segment)
+		if i > 0 {
+			b.WriteString(".")
+		}
+		b.WriteString(placeholder)
+	}
+	return b.String()
+}
+
+func (u *Update) name(name string) string {
+	for placeholder, existing := range u.Names {
+		if existing == name {
+			return placeholder
+		}
+	}
+	placeholder := "#p" + strconv.Itoa(len(u.Names))
+	u.Names[placeholder] = name
+	return placeholder
+}
+
+func (u *Update) value(value any) string {
+	placeholder := ":v" + strconv.Itoa(len(u.Values))
+	u.Values[placeholder] = value
+	return placeholder
+}
+
+// applyAttempts bounds how often a merge patch is re-read and retried when the maps it writes
+// into change under it.
+const applyAttempts = 3
+
+// Apply runs the patch as one conditional update of an existing item. A merge patch that writes
+// into a member that is not an object sets that member to an object holding the changes, in the
+// same update; the item is read first to find such members and the update is retried if they
+// change in between.
+// Failed tests, a missing item and a JSON Patch whose target or parent is missing all come back
+// as ErrConditionFailed.
+func (p Patch) Apply(ctx context.Context, client dynamodbClient.DynamodbClient, tableName string, key map[string]types.AttributeValue, keyAttribute string) error {
+	parents := p.mergeParents()
+	if len(parents) == 0 {
+		return p.Update().apply(ctx, client, tableName, key, keyAttribute)
+	}
+
+	var err error
+	for attempt := 0; attempt < applyAttempts; attempt++ {
+		var item map[string]any
+		if getErr := client.GetItem(ctx, tableName, key, &item); errors.Is(getErr, dynamodbClient.ErrNotFound) {
+			return fmt.Errorf("%w: the item does not exist", dynamodbClient.ErrConditionFailed)
+		} else if getErr != nil {
+			return getErr
+		}
+
+		folded, guards := p.fold(item, parents)
+		if len(folded.Changes) == 0 && len(folded.Tests) == 0 {
+			// Every change was to make an object that is one already, or to remove from a missing one.
+			return nil
+		}
+		err = folded.update(guards).apply(ctx, client, tableName, key, keyAttribute)
+		if !errors.Is(err, dynamodbClient.ErrConditionFailed) {
+			return err
+		}
+	}
+	return err
+}
+
+func (u *Update) apply(ctx context.Context, client dynamodbClient.DynamodbClient, tableName string, key map[string]types.AttributeValue, keyAttribute string) error {
+	opts := []dynamodbClient.WriteOption{
+		dynamodbClient.IfExists(keyAttribute),
+		dynamodbClient.ExpressionNames(u.Names),
+	}
+	if u.Condition != "" {
+		opts = append(opts, dynamodbClient.Condition(u.Condition, nil, nil))
+	}
+	return client.UpdateItem(ctx, tableName, key, u.Expression, u.Values, opts...)
+}
+
+// mergeParents returns the maps merge patch changes write into, shallowest first.
+func (p Patch) mergeParents() [][]string {
+	var parents [][]string
+	for _, change := range p.Changes {
+		if change.Pointer || change.Remove {
+			continue
+		}
+		last := len(change.Path) - 1
+		if change.Object {
+			last++
+		}
+		for end := 1; end <= last; end++ {
+			parent := change.Path[:end]
+			if !slices.ContainsFunc(parents, func(m []string) bool { return slices.Equal(m, parent) }) {
+				parents = append(parents, parent)
+			}
+		}
+	}
+	slices.SortStableFunc(parents, func(a, b []string) int { return len(a) - len(b) })
+	return parents
+}
+
+// fold replaces the changes under each parent the item has no map at with one change that sets
+// the parent to a map of them. Removals under such a parent have nothing to remove and are
+// dropped, as are Object changes to a map.
+func (p Patch) fold(item map[string]any, parents [][]string) (Patch, []guard) {
+	var missing [][]string
+	var guards []guard
+	for _, parent := range parents {
+		if slices.ContainsFunc(missing, func(m []string) bool { return isPrefix(m, parent) }) {
+			continue
+		}
+		_, isMap := lookup(item, parent).(map[string]any)
+		if !isMap {
+			missing = append(missing, parent)
+		}
+		guards = append(guards, guard{Path: parent, IsMap: isMap})
+	}
+
+	folded := Patch{Tests: p.Tests}
+	values := make([]map[string]any, len(missing))
+	for _, change := range p.Changes {
+		i := -1
+		if !change.Pointer {
+			i = slices.IndexFunc(missing, func(m []string) bool { return isPrefix(m, change.Path) })
+		}
+		switch {
+		case i < 0 && change.Object:
+		case i < 0:
+			folded.Changes = append(folded.Changes, change)
+		case !change.Remove:
+			if values[i] == nil {
+				values[i] = map[string]any{}
+			}
+			if rest := change.Path[len(missing[i]):]; len(rest) > 0 {
+				setPath(values[i], rest, change.Value)
+			}
+		}
+	}
+	for i, parent := range missing {
+		if values[i] != nil {
+			folded.Changes = append(folded.Changes, Change{Path: parent, Value: values[i]})
+		}
+	}
+	return folded, guards
+}
+
+func lookup(item map[string]any, path []string) any {
+	var value any = item
+	for _, segment := range path {
+		m, ok := value.(map[string]any)
+		if !ok {
+			return nil
+		}
+		value = m[segment]
+	}
+	return value
+}
+
+func setPath(m map[string]any, path []string, value any) {
+	for _, segment := range path[:len(path)-1] {
+		child, ok := m[segment].(map[string]any)
+		if !ok {
+			child = map[string]any{}
+			m[segment] = child
+		}
+		m = child
+	}
+	m[path[len(path)-1]] = value
+}
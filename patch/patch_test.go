@@ -0,0 +1,406 @@
+package patch_test
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	dynamodbClient "dytest/dynamodb"
+	"dytest/dynamodb/memory"
+	"dytest/fixtures"
+	"dytest/model"
+	"dytest/patch"
+	"dytest/problem"
+
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+func TestFromMergePatch(t *testing.T) {
+	tests := []struct {
+		name string
+		doc  map[string]any
+		want []patch.Change
+	}{
+		{
+			name: "replaces a member",
+			doc:  map[string]any{"info": map[string]any{"rating": 8.1}},
+			want: []patch.Change{{Path: []string{"info", "rating"}, Value: 8.1}},
+		},
+		{
+			name: "removes a null member",
+			doc:  map[string]any{"info": map[string]any{"plot": nil}},
+			want: []patch.Change{{Path: []string{"info", "plot"}, Remove: true}},
+		},
+		{
+			name: "sorts members",
+			doc:  map[string]any{"info": map[string]any{"rating": 8.1, "plot": "Hunt and Lauda."}},
+			want: []patch.Change{
+				{Path: []string{"info", "plot"}, Value: "Hunt and Lauda."},
+				{Path: []string{"info", "rating"}, Value: 8.1},
+			},
+		},
+		{
+			name: "replaces a list as a whole",
+			doc:  map[string]any{"info": map[string]any{"genres": []any{"Drama"}}},
+			want: []patch.Change{{Path: []string{"info", "genres"}, Value: []any{"Drama"}}},
+		},
+		{
+			name: "makes an empty object member an object",
+			doc:  map[string]any{"info": map[string]any{"awards": map[string]any{}}},
+			want: []patch.Change{{Path: []string{"info", "awards"}, Value: map[string]any{}, Object: true}},
+		},
+		{
+			name: "keeps digits as member names",
+			doc:  map[string]any{"info": map[string]any{"0": "zero"}},
+			want: []patch.Change{{Path: []string{"info", "0"}, Value: "zero"}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := patch.FromMergePatch(tt.doc); !reflect.DeepEqual(got.Changes, tt.want) || got.Tests != nil {
+				t.Errorf("FromMergePatch = %+v, want changes %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFromJSONPatch(t *testing.T) {
+	tests := []struct {
+		name       string
+		operations []patch.Operation
+		want       patch.Patch
+		fields     []problem.FieldError
+	}{
+		{
+			name:       "add",
+			operations: []patch.Operation{{Op: "add", Path: "/info/plot", Value: "Hunt and Lauda."}},
+			want:       patch.Patch{Changes: []patch.Change{{Path: []string{"info", "plot"}, Value: "Hunt and Lauda.", Pointer: true}}},
+		},
+		{
+			name:       "add appends to a list",
+			operations: []patch.Operation{{Op: "add", Path: "/info/genres/-", Value: "Drama"}},
+			want:       patch.Patch{Changes: []patch.Change{{Path: []string{"info", "genres"}, Value: []any{"Drama"}, Append: true, Pointer: true}}},
+		},
+		{
+			name:       "replace must exist",
+			operations: []patch.Operation{{Op: "replace", Path: "/info/genres/0", Value: "Drama"}},
+			want:       patch.Patch{Changes: []patch.Change{{Path: []string{"info", "genres", "0"}, Value: "Drama", MustExist: true, Pointer: true}}},
+		},
+		{
+			name:       "remove",
+			operations: []patch.Operation{{Op: "remove", Path: "/info/plot"}},
+			want:       patch.Patch{Changes: []patch.Change{{Path: []string{"info", "plot"}, Remove: true, Pointer: true}}},
+		},
+		{
+			name: "test before a change",
+			operations: []patch.Operation{
+				{Op: "test", Path: "/info/rating", Value: 8.1},
+				{Op: "replace", Path: "/info/rating", Value: 8.2},
+			},
+			want: patch.Patch{
+				Changes: []patch.Change{{Path: []string{"info", "rating"}, Value: 8.2, MustExist: true, Pointer: true}},
+				Tests:   []patch.Test{{Path: []string{"info", "rating"}, Value: 8.1, Pointer: true}},
+			},
+		},
+		{
+			name: "test after a change elsewhere",
+			operations: []patch.Operation{
+				{Op: "replace", Path: "/info/rating", Value: 8.2},
+				{Op: "test", Path: "/info/plot", Value: "Hunt and Lauda."},
+			},
+			want: patch.Patch{
+				Changes: []patch.Change{{Path: []string{"info", "rating"}, Value: 8.2, MustExist: true, Pointer: true}},
+				Tests:   []patch.Test{{Path: []string{"info", "plot"}, Value: "Hunt and Lauda.", Pointer: true}},
+			},
+		},
+		{
+			name: "test after a change to its path",
+			operations: []patch.Operation{
+				{Op: "replace", Path: "/info/rating", Value: 8.2},
+				{Op: "test", Path: "/info/rating", Value: 8.2},
+			},
+			want:   patch.Patch{Changes: []patch.Change{{Path: []string{"info", "rating"}, Value: 8.2, MustExist: true, Pointer: true}}},
+			fields: []problem.FieldError{{Field: "operations[1].path", Message: "follows the change to info.rating; put tests first"}},
+		},
+		{
+			name: "test under a changed member",
+			operations: []patch.Operation{
+				{Op: "add", Path: "/info/genres/-", Value: "Drama"},
+				{Op: "test", Path: "/info/genres/0", Value: "Drama"},
+			},
+			want:   patch.Patch{Changes: []patch.Change{{Path: []string{"info", "genres"}, Value: []any{"Drama"}, Append: true, Pointer: true}}},
+			fields: []problem.FieldError{{Field: "operations[1].path", Message: "follows the change to info.genres; put tests first"}},
+		},
+		{
+			name:       "insert into a list",
+			operations: []patch.Operation{{Op: "add", Path: "/info/genres/0", Value: "Drama"}},
+			fields:     []problem.FieldError{{Field: "operations[0].path", Message: "inserting into a list is not supported; append with /- or use replace"}},
+		},
+		{
+			name:       "move",
+			operations: []patch.Operation{{Op: "move", From: "/info/plot", Path: "/info/summary"}},
+			fields:     []problem.FieldError{{Field: "operations[0].op", Message: "is not supported"}},
+		},
+		{
+			name:       "unknown op",
+			operations: []patch.Operation{{Op: "merge", Path: "/info"}},
+			fields:     []problem.FieldError{{Field: "operations[0].op", Message: "must be one of: add, replace, remove, test"}},
+		},
+		{
+			name:       "root",
+			operations: []patch.Operation{{Op: "replace", Path: "", Value: map[string]any{}}},
+			fields:     []problem.FieldError{{Field: "operations[0].path", Message: "must point below the document root"}},
+		},
+		{
+			name:       "relative path",
+			operations: []patch.Operation{{Op: "remove", Path: "info/plot"}},
+			fields:     []problem.FieldError{{Field: "operations[0].path", Message: "must start with /"}},
+		},
+		{
+			name:       "empty segment",
+			operations: []patch.Operation{{Op: "remove", Path: "/info//plot"}},
+			fields:     []problem.FieldError{{Field: "operations[0].path", Message: "must not have empty segments"}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, fields := patch.FromJSONPatch(tt.operations)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("FromJSONPatch = %+v, want %+v", got, tt.want)
+			}
+			if !reflect.DeepEqual(fields, tt.fields) {
+				t.Errorf("FromJSONPatch errors = %+v, want %+v", fields, tt.fields)
+			}
+		})
+	}
+}
+
+func TestPointerEscaping(t *testing.T) {
+	tests := []struct {
+		pointer string
+		path    []string
+		field   string
+	}{
+		{pointer: "/info/a~1b", path: []string{"info", "a/b"}, field: "info.a/b"},
+		{pointer: "/info/a~0b", path: []string{"info", "a~b"}, field: "info.a~b"},
+		// ~01 is an escaped ~ followed by 1, not an escaped /.
+		{pointer: "/info/~01", path: []string{"info", "~1"}, field: "info.~1"},
+		{pointer: "/info/genres/10", path: []string{"info", "genres", "10"}, field: "info.genres[10]"},
+		{pointer: "/info/genres/01", path: []string{"info", "genres", "01"}, field: "info.genres.01"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.pointer, func(t *testing.T) {
+			p, fields := patch.FromJSONPatch([]patch.Operation{{Op: "remove", Path: tt.pointer}})
+			if len(fields) > 0 {
+				t.Fatalf("FromJSONPatch errors = %+v", fields)
+			}
+			if got := p.Changes[0].Path; !reflect.DeepEqual(got, tt.path) {
+				t.Errorf("path = %q, want %q", got, tt.path)
+			}
+			if got := patch.FieldName(p.Changes[0].Path, true); got != tt.field {
+				t.Errorf("FieldName = %q, want %q", got, tt.field)
+			}
+		})
+	}
+}
+
+func TestFromUpdateExpression(t *testing.T) {
+	tests := []struct {
+		name       string
+		expression string
+		values     map[string]any
+		want       []patch.Change
+		fields     []problem.FieldError
+	}{
+		{
+			name:       "set",
+			expression: "SET info.rating = :r, info.plot = :p",
+			values:     map[string]any{":r": 8.1, ":p": "Hunt and Lauda."},
+			want: []patch.Change{
+				{Path: []string{"info", "rating"}, Value: 8.1},
+				{Path: []string{"info", "plot"}, Value: "Hunt and Lauda."},
+			},
+		},
+		{
+			name:       "set and remove",
+			expression: "SET info.rating = :r REMOVE info.plot, info.rank",
+			values:     map[string]any{":r": 8.1},
+			want: []patch.Change{
+				{Path: []string{"info", "rating"}, Value: 8.1},
+				{Path: []string{"info", "plot"}, Remove: true},
+				{Path: []string{"info", "rank"}, Remove: true},
+			},
+		},
+		{
+			name:       "remove before set",
+			expression: "REMOVE info.plot SET info.rating = :r",
+			values:     map[string]any{":r": 8.1},
+			want: []patch.Change{
+				{Path: []string{"info", "plot"}, Remove: true},
+				{Path: []string{"info", "rating"}, Value: 8.1},
+			},
+		},
+		{
+			name:       "missing value",
+			expression: "SET info.rating = :r, info.plot = :p",
+			values:     map[string]any{":r": 8.1},
+			want:       []patch.Change{{Path: []string{"info", "rating"}, Value: 8.1}},
+			fields:     []problem.FieldError{{Field: "expressionAttributeValues", Message: "has no value for :p"}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, fields := patch.FromUpdateExpression(tt.expression, tt.values)
+			if !reflect.DeepEqual(got.Changes, tt.want) {
+				t.Errorf("FromUpdateExpression = %+v, want %+v", got.Changes, tt.want)
+			}
+			if !reflect.DeepEqual(fields, tt.fields) {
+				t.Errorf("FromUpdateExpression errors = %+v, want %+v", fields, tt.fields)
+			}
+		})
+	}
+}
+
+func TestCheck(t *testing.T) {
+	allowed := []string{"info"}
+	tests := []struct {
+		name   string
+		patch  patch.Patch
+		fields []problem.FieldError
+	}{
+		{
+			name:  "allowed",
+			patch: patch.FromMergePatch(map[string]any{"info": map[string]any{"rating": 8.1, "plot": nil}}),
+		},
+		{
+			name:   "nothing to change",
+			patch:  patch.Patch{},
+			fields: []problem.FieldError{{Field: "body", Message: "the patch changes nothing"}},
+		},
+		{
+			name:   "change outside the allowlist",
+			patch:  patch.FromMergePatch(map[string]any{"title": "Her"}),
+			fields: []problem.FieldError{{Field: "title", Message: "cannot be changed"}},
+		},
+		{
+			name: "test outside the allowlist",
+			patch: patch.Patch{
+				Changes: []patch.Change{{Path: []string{"info", "rating"}, Value: 8.1, Pointer: true}},
+				Tests:   []patch.Test{{Path: []string{"year"}, Value: 2013, Pointer: true}},
+			},
+			fields: []problem.FieldError{{Field: "year", Message: "cannot be changed"}},
+		},
+		{
+			name: "overlapping changes",
+			patch: patch.Patch{Changes: []patch.Change{
+				{Path: []string{"info"}, Value: map[string]any{}, Pointer: true},
+				{Path: []string{"info", "rating"}, Value: 8.1, Pointer: true},
+			}},
+			fields: []problem.FieldError{{Field: "info.rating", Message: "overlaps the change to info"}},
+		},
+		{
+			name: "the same path twice",
+			patch: patch.Patch{Changes: []patch.Change{
+				{Path: []string{"info", "genres", "0"}, Value: "Drama", Pointer: true},
+				{Path: []string{"info", "genres", "0"}, Remove: true, Pointer: true},
+			}},
+			fields: []problem.FieldError{{Field: "info.genres[0]", Message: "overlaps the change to info.genres[0]"}},
+		},
+		{
+			name: "siblings with a common prefix",
+			patch: patch.Patch{Changes: []patch.Change{
+				{Path: []string{"info", "rank"}, Value: 1},
+				{Path: []string{"info", "ranking"}, Value: 2},
+			}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if fields := tt.patch.Check(allowed); !reflect.DeepEqual(fields, tt.fields) {
+				t.Errorf("Check = %+v, want %+v", fields, tt.fields)
+			}
+		})
+	}
+}
+
+func TestApplyEmptyObject(t *testing.T) {
+	tests := []struct {
+		name string
+		info any
+		want map[string]any
+	}{
+		{name: "missing member", info: map[string]any{"rating": 8.1}, want: map[string]any{"rating": 8.1, "awards": map[string]any{}}},
+		{name: "scalar member", info: map[string]any{"awards": "none"}, want: map[string]any{"awards": map[string]any{}}},
+		{name: "object member", info: map[string]any{"awards": map[string]any{"bafta": 1.0}}, want: map[string]any{"awards": map[string]any{"bafta": 1.0}}},
+		{name: "missing parent", info: nil, want: map[string]any{"awards": map[string]any{}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			db, key := newMovie(t, tt.info)
+
+			p := patch.FromMergePatch(map[string]any{"info": map[string]any{"awards": map[string]any{}}})
+			if err := p.Apply(ctx, db, "Movies", key, "title"); err != nil {
+				t.Fatalf("Apply: %v", err)
+			}
+			var movie model.MovieItem
+			if err := db.GetItem(ctx, "Movies", key, &movie); err != nil {
+				t.Fatalf("GetItem: %v", err)
+			}
+			if !reflect.DeepEqual(movie.Info, tt.want) {
+				t.Errorf("info = %#v, want %#v", movie.Info, tt.want)
+			}
+		})
+	}
+}
+
+func TestApplyTests(t *testing.T) {
+	ctx := context.Background()
+	db, key := newMovie(t, map[string]any{"rating": 8.1})
+
+	p, fields := patch.FromJSONPatch([]patch.Operation{
+		{Op: "test", Path: "/info/rating", Value: 8.0},
+		{Op: "replace", Path: "/info/rating", Value: 8.2},
+	})
+	if len(fields) > 0 {
+		t.Fatalf("FromJSONPatch errors = %+v", fields)
+	}
+	if err := p.Apply(ctx, db, "Movies", key, "title"); !errors.Is(err, dynamodbClient.ErrConditionFailed) {
+		t.Errorf("Apply with a failing test = %v, want ErrConditionFailed", err)
+	}
+
+	p.Tests[0].Value = 8.1
+	if err := p.Apply(ctx, db, "Movies", key, "title"); err != nil {
+		t.Errorf("Apply with a passing test: %v", err)
+	}
+}
+
+// newMovie returns an in-memory Movies table holding Rush with the given info, and its key.
+func newMovie(t *testing.T, info any) (*memory.Client, map[string]types.AttributeValue) {
+	t.Helper()
+	ctx := context.Background()
+	db := memory.New()
+	schema := &model.TableSchema{
+		TableName:    "Movies",
+		PartitionKey: model.KeyAttribute{Name: "year", Type: "N"},
+		SortKey:      &model.KeyAttribute{Name: "title", Type: "S"},
+	}
+	if err := fixtures.CreateTable(ctx, db, schema); err != nil {
+		t.Fatalf("failed to create Movies: %v", err)
+	}
+
+	item := map[string]any{"year": 2013, "title": "Rush"}
+	if info != nil {
+		item["info"] = info
+	}
+	if err := db.TransactWriteItems(ctx, "Movies", item); err != nil {
+		t.Fatalf("failed to save Rush: %v", err)
+	}
+	key, err := attributevalue.MarshalMap(map[string]any{"year": 2013, "title": "Rush"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return db, key
+}
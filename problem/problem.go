@@ -0,0 +1,177 @@
+package problem
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+
+	dynamodbClient "dytest/dynamodb"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+const ContentType = "application/problem+json"
+
+// Stable error codes. Clients should switch on these rather than on titles or details.
+const (
+	CodeBadRequest     = "bad_request"
+	CodeValidation     = "validation_failed"
+	CodeUnauthorized   = "unauthorized"
+	CodeForbidden      = "forbidden"
+	CodeNotFound       = "not_found"
+	CodeMethod         = "method_not_allowed"
+	CodeConflict       = "conflict"
+	CodeMediaType      = "unsupported_media_type"
+	CodeInvalidCursor  = "invalid_cursor"
+	CodeItemTooLarge   = "item_too_large"
+	CodeTenantRequired = "tenant_required"
+	CodeThrottled      = "throttled"
+	CodeUnavailable    = "unavailable"
+	CodeInternal       = "internal_error"
+)
+
+type FieldError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+// Problem is an RFC 7807 problem details body, extended with a code, the request ID and field errors.
+type Problem struct {
+	Type      string       `json:"type"`
+	Title     string       `json:"title"`
+	Status    int          `json:"status"`
+	Code      string       `json:"code"`
+	Detail    string       `json:"detail,omitempty"`
+	Instance  string       `json:"instance,omitempty"`
+	RequestID string       `json:"requestId,omitempty"`
+	Errors    []FieldError `json:"errors,omitempty"`
+}
+
+func (p *Problem) Error() string {
+	if p.Detail == "" {
+		return fmt.Sprintf("%d %s", p.Status, p.Code)
+	}
+	return fmt.Sprintf("%d %s: %s", p.Status, p.Code, p.Detail)
+}
+
+func New(status int, code, detail string) *Problem {
+	return &Problem{
+		Type:   "about:blank",
+		Title:  http.StatusText(status),
+		Status: status,
+		Code:   code,
+		Detail: detail,
+	}
+}
+
+// Status builds a problem with the code that goes with status.
+func Status(status int, detail string) *Problem {
+	return New(status, codeForStatus(status), detail)
+}
+
+func BadRequest(detail string) *Problem {
+	return New(http.StatusBadRequest, CodeBadRequest, detail)
+}
+
+func NotFound(detail string) *Problem {
+	return New(http.StatusNotFound, CodeNotFound, detail)
+}
+
+func Conflict(detail string) *Problem {
+	return New(http.StatusConflict, CodeConflict, detail)
+}
+
+// Validation reports a request that parsed but is not acceptable, with one entry per offending field.
+func Validation(detail string, fields ...FieldError) *Problem {
+	p := New(http.StatusUnprocessableEntity, CodeValidation, detail)
+	p.Errors = fields
+	return p
+}
+
+// From turns any error returned by a handler into a Problem.
+// Unknown errors become a 500 without their text, so SDK messages do not reach clients.
+func From(err error) *Problem {
+	var p *Problem
+	if errors.As(err, &p) {
+		copied := *p
+		return &copied
+	}
+
+	var fiberErr *fiber.Error
+	if errors.As(err, &fiberErr) {
+		return Status(fiberErr.Code, fiberErr.Message)
+	}
+
+	var tooLarge *dynamodbClient.ItemTooLargeError
+	if errors.As(err, &tooLarge) {
+		return New(http.StatusRequestEntityTooLarge, CodeItemTooLarge,
+			fmt.Sprintf("The item is %d bytes; DynamoDB stores at most %d bytes per item", tooLarge.Size, dynamodbClient.MaxItemSize))
+	}
+
+	switch {
+	case errors.Is(err, dynamodbClient.ErrNotFound):
+		return NotFound("Item not found")
+	case errors.Is(err, dynamodbClient.ErrConditionFailed):
+		return Conflict("The item was changed or does not meet the condition")
+	case errors.Is(err, dynamodbClient.ErrInvalidCursor):
+		return New(http.StatusBadRequest, CodeInvalidCursor, "Invalid cursor")
+	case errors.Is(err, dynamodbClient.ErrValidation):
+		return New(http.StatusBadRequest, CodeValidation, "DynamoDB rejected the request as invalid")
+	case errors.Is(err, dynamodbClient.ErrTenantRequired):
+		return New(http.StatusBadRequest, CodeTenantRequired, "A tenant is required for this table")
+	case errors.Is(err, dynamodbClient.ErrCrossTenant):
+		return New(http.StatusForbidden, CodeForbidden, "The item belongs to another tenant")
+	case errors.Is(err, dynamodbClient.ErrThrottled):
+		return New(http.StatusTooManyRequests, CodeThrottled, "DynamoDB is throttling requests, retry later")
+	case errors.Is(err, dynamodbClient.ErrCircuitOpen):
+		return New(http.StatusServiceUnavailable, CodeUnavailable, "DynamoDB is unavailable, retry later")
+	}
+	return New(http.StatusInternalServerError, CodeInternal, "Internal server error")
+}
+
+// ErrorHandler is the app's fiber.Config.ErrorHandler. It writes every error as problem+json.
+func ErrorHandler(c *fiber.Ctx, err error) error {
+	p := From(err)
+	if p.Status >= http.StatusInternalServerError {
+		log.Printf("%s %s: %v\n", c.Method(), c.Path(), err)
+	}
+
+	p.Instance = c.Path()
+	if id, ok := c.Locals("requestid").(string); ok {
+		p.RequestID = id
+	}
+	if p.Status == http.StatusTooManyRequests || p.Status == http.StatusServiceUnavailable {
+		c.Set(fiber.HeaderRetryAfter, "1")
+	}
+	return c.Status(p.Status).JSON(p, ContentType)
+}
+
+func codeForStatus(status int) string {
+	switch status {
+	case http.StatusBadRequest:
+		return CodeBadRequest
+	case http.StatusUnauthorized:
+		return CodeUnauthorized
+	case http.StatusForbidden:
+		return CodeForbidden
+	case http.StatusNotFound:
+		return CodeNotFound
+	case http.StatusMethodNotAllowed:
+		return CodeMethod
+	case http.StatusConflict:
+		return CodeConflict
+	case http.StatusUnsupportedMediaType:
+		return CodeMediaType
+	case http.StatusUnprocessableEntity:
+		return CodeValidation
+	case http.StatusTooManyRequests:
+		return CodeThrottled
+	case http.StatusServiceUnavailable:
+		return CodeUnavailable
+	}
+	if status >= http.StatusInternalServerError {
+		return CodeInternal
+	}
+	return CodeBadRequest
+}
@@ -0,0 +1,200 @@
+// Package server builds the HTTP API: the routes, their controllers and the OpenAPI document. It
+// does not talk to DynamoDB until Start, so tests can build the whole app against any client.
+package server
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"time"
+
+	"dytest/config"
+	dynamodbClient "dytest/dynamodb"
+	"dytest/events"
+	"dytest/export"
+	"dytest/graphqlapi"
+	"dytest/importer"
+	"dytest/jobs"
+	"dytest/middleware"
+	"dytest/openapi"
+	"dytest/problem"
+	"dytest/test1"
+	"dytest/webhook"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/middleware/requestid"
+)
+
+type Server struct {
+	App  *fiber.App
+	Jobs *jobs.Runner
+	// Webhooks is nil unless webhooks are enabled.
+	Webhooks *webhook.Dispatcher
+
+	cfg     *config.Config
+	imports *importer.TableStore
+}
+
+func New(cfg *config.Config, client dynamodbClient.DynamodbClient, broker *events.Broker) (*Server, error) {
+	// Bodies over the default BodyLimit are streamed, so that an import upload is not held in
+	// memory; every other route refuses them.
+	app := fiber.New(fiber.Config{
+		ErrorHandler:      problem.ErrorHandler,
+		StreamRequestBody: true,
+	})
+	s := &Server{App: app, cfg: cfg}
+
+	app.Use(requestid.New())
+	app.Use(middleware.BodyLimit(fiber.DefaultBodyLimit, "POST /imports"))
+	app.Use(middleware.Tenant(cfg.Tenant))
+
+	health := &test1.HealthController{Client: client, RequiredTables: cfg.Server.RequiredTables}
+	app.Get("/healthz", health.Liveness)
+	app.Get("/readyz", health.Readiness)
+
+	// Table creation, imports and exports run as jobs, which a restarted server resumes.
+	s.Jobs = &jobs.Runner{
+		Store:        &jobs.Store{Client: client, TableName: cfg.Jobs.TableName},
+		Workers:      cfg.Jobs.Workers,
+		PollInterval: cfg.Jobs.PollInterval,
+		Lease:        cfg.Jobs.Lease,
+	}
+	if err := os.MkdirAll(cfg.Jobs.DataDir, 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create jobs data directory: %v", err)
+	}
+
+	controller := &test1.DynamoDBController2{Client: client, Jobs: s.Jobs}
+	if cfg.Webhooks.Enabled {
+		store := &webhook.Store{
+			Client:             client,
+			SubscriptionsTable: cfg.Webhooks.SubscriptionsTable,
+			DeliveriesTable:    cfg.Webhooks.DeliveriesTable,
+			CacheTTL:           cfg.Webhooks.CacheTTL,
+		}
+		s.Webhooks = &webhook.Dispatcher{
+			Store:       store,
+			HTTPClient:  webhook.NewHTTPClient(cfg.Webhooks.Timeout, cfg.Webhooks.AllowPrivateAddresses),
+			MaxAttempts: cfg.Webhooks.MaxAttempts,
+			Backoff:     cfg.Webhooks.Backoff,
+			MaxBackoff:  cfg.Webhooks.MaxBackoff,
+
+			SweepInterval: cfg.Webhooks.SweepInterval,
+		}
+		controller.Webhooks = s.Webhooks
+
+		webhooks := &test1.WebhookController{Store: store, AllowPrivateAddresses: cfg.Webhooks.AllowPrivateAddresses}
+		app.Post("/webhooks", webhooks.RegisterWebhook)
+		app.Get("/webhooks", webhooks.ListWebhooks)
+		app.Delete("/webhooks/:id", webhooks.DeleteWebhook)
+		app.Get("/webhooks/:id/deliveries", webhooks.ListDeliveries)
+	}
+
+	app.Get("/get-table", controller.GetTableList)
+	app.Post("/create-table", controller.CreateTable)
+	app.Post("/delete-table", controller.DeleteTable)
+	app.Post("/update-table-ttl", controller.UpdateTimeToLive)
+	app.Post("/describe-table-ttl", controller.DescribeTimeToLive)
+
+	app.Get("/movies", controller.ListMovies)
+	app.Post("/movies", controller.CreateMovie)
+	app.Get("/movies/:year/:title", controller.GetMovie)
+	app.Put("/movies/:year/:title", controller.PutMovie)
+	app.Patch("/movies/:year/:title", controller.PatchMovie)
+	app.Delete("/movies/:year/:title", controller.DeleteMovie)
+
+	// Deprecated RPC-style routes, kept for one release.
+	app.Post("/save-movie", middleware.Deprecated("/movies"), controller.SaveMovieItem)
+	app.Post("/get-movie", middleware.Deprecated("/movies/{year}/{title}"), controller.GetMovieItem)
+	app.Get("/scan-movies", middleware.Deprecated("/movies"), controller.ListMovies)
+	app.Post("/delete-movie", middleware.Deprecated("/movies/{year}/{title}"), controller.DeleteMovieItem)
+	app.Post("/update-movie", middleware.Deprecated("/movies/{year}/{title}"), controller.UpdateMovieItem)
+
+	schema, err := graphqlapi.NewSchema(client, controller.Webhooks)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build GraphQL schema: %v", err)
+	}
+	graphql := &graphqlapi.Handler{Client: client, Schema: schema}
+	app.Get("/graphql", graphql.Serve)
+	app.Post("/graphql", graphql.Serve)
+
+	if cfg.PartiQL.Enabled {
+		partiql := &test1.PartiQLController{Client: client, AllowWrites: cfg.PartiQL.AllowWrites}
+		app.Post("/partiql", partiql.ExecutePartiQL)
+	}
+
+	exportColumns, err := export.ParseColumns(cfg.Export.CSVColumns)
+	if err != nil {
+		return nil, fmt.Errorf("invalid export columns: %v", err)
+	}
+	exports := &test1.ExportController{
+		Client:   client,
+		PageSize: cfg.Export.PageSize,
+		Columns:  exportColumns,
+		Jobs:     s.Jobs,
+		DataDir:  cfg.Jobs.DataDir,
+	}
+	app.Get("/movies/export", exports.ExportMovies)
+
+	imports := &test1.ImportController{
+		Client:  client,
+		Store:   &importer.TableStore{Client: client, TableName: cfg.Import.TableName},
+		Rate:    cfg.Import.Rate,
+		Columns: exportColumns,
+		Jobs:    s.Jobs,
+		DataDir: cfg.Jobs.DataDir,
+
+		MaxUploadBytes: cfg.Import.MaxUploadBytes,
+	}
+	s.imports = imports.Store
+	app.Post("/imports", imports.ImportRows)
+	app.Get("/imports/:id", imports.GetImport)
+
+	s.Jobs.Handle(test1.JobCreateTable, controller.RunCreateTableJob)
+	s.Jobs.Handle(test1.JobImport, imports.RunImportJob)
+	s.Jobs.Handle(test1.JobExport, exports.RunExportJob)
+
+	jobController := &test1.JobController{Runner: s.Jobs, DataDir: cfg.Jobs.DataDir}
+	app.Get("/jobs/:id", jobController.GetJob)
+	app.Post("/jobs/:id/cancel", jobController.CancelJob)
+	app.Get("/jobs/:id/result", jobController.GetJobResult)
+
+	movieEvents := &test1.EventsController{Broker: broker, TableName: "Movies"}
+	app.Get("/movies/events", movieEvents.MovieEvents)
+
+	// Routes without an Operation are listed without details; openapi's tests catch them.
+	if err := openapi.Register(app, openapi.Operations); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// Start creates the tables the server keeps its own state in and starts the job workers and
+// webhook deliveries. The tables are created in the background, retried until DynamoDB is reachable.
+func (s *Server) Start(ctx context.Context) error {
+	if s.Webhooks != nil {
+		go ensure(ctx, "webhook tables", s.Webhooks.Store.EnsureTables)
+		s.Webhooks.Start(ctx, s.cfg.Webhooks.Workers)
+	}
+	go ensure(ctx, "imports table", s.imports.EnsureTable)
+	go ensure(ctx, "jobs table", s.Jobs.Store.EnsureTable)
+	s.Jobs.Start(ctx)
+	return nil
+}
+
+func ensure(ctx context.Context, what string, create func(context.Context) error) {
+	delay := time.Second
+	for {
+		err := create(ctx)
+		if err == nil {
+			return
+		}
+		log.Printf("Failed to create %s, retrying in %s: %v", what, delay, err)
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(delay):
+		}
+		delay = min(delay*2, time.Minute)
+	}
+}
@@ -0,0 +1,222 @@
+package stream
+
+import (
+	"context"
+	appConfig "dytest/config"
+	dynamodbClient "dytest/dynamodb"
+	"errors"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodbstreams"
+	streamTypes "github.com/aws/aws-sdk-go-v2/service/dynamodbstreams/types"
+)
+
+// Consumer follows the stream of one table. Shards are processed once their parent
+// shard is finished, so records of a key are always handled in order.
+// Run a single consumer per table; leases record progress but are not used for locking.
+type Consumer struct {
+	Client    dynamodbClient.DynamodbClient
+	Streams   *dynamodbstreams.Client
+	TableName string
+	Leases    *LeaseStore
+	Handler   RecordHandler
+
+	// PollInterval is the pause after an empty GetRecords call, DiscoverInterval the pause between shard discoveries.
+	PollInterval     time.Duration
+	DiscoverInterval time.Duration
+
+	mu     sync.Mutex
+	active map[string]bool
+}
+
+// NewStreamsClient builds a DynamoDB Streams client from the same settings as the DynamoDB client,
+// so it also reaches dynamodb-local's stream emulation.
+func NewStreamsClient(ctx context.Context, cfg appConfig.DynamoDBConfig) (*dynamodbstreams.Client, error) {
+	awsCfg, err := dynamodbClient.LoadAWSConfig(ctx, cfg)
+	if err != nil {
+		return nil, err
+	}
+	return dynamodbstreams.NewFromConfig(awsCfg, func(o *dynamodbstreams.Options) {
+		if cfg.Endpoint != "" {
+			o.BaseEndpoint = aws.String(cfg.Endpoint)
+		}
+	}), nil
+}
+
+// Run discovers shards and processes them until ctx is done.
+func (c *Consumer) Run(ctx context.Context) error {
+	if c.PollInterval == 0 {
+		c.PollInterval = time.Second
+	}
+	if c.DiscoverInterval == 0 {
+		c.DiscoverInterval = 10 * time.Second
+	}
+	c.active = map[string]bool{}
+
+	if err := c.Leases.EnsureTable(ctx); err != nil {
+		return fmt.Errorf("failed to create lease table: %v", err)
+	}
+
+	var wg sync.WaitGroup
+	defer wg.Wait()
+
+	for {
+		streamARN, shards, err := c.discover(ctx)
+		if err != nil {
+			log.Printf("Failed to discover shards of `%s`: %v\n", c.TableName, err)
+		}
+
+		for _, shard := range shards {
+			shard := shard
+			ready, err := c.ready(ctx, streamARN, shard, shards)
+			if err != nil {
+				log.Printf("Failed to read lease of shard %s: %v\n", aws.ToString(shard.ShardId), err)
+				continue
+			}
+			if !ready || !c.start(aws.ToString(shard.ShardId)) {
+				continue
+			}
+
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				defer c.stop(aws.ToString(shard.ShardId))
+				if err := c.process(ctx, streamARN, aws.ToString(shard.ShardId)); err != nil && !errors.Is(err, context.Canceled) {
+					log.Printf("Failed to process shard %s: %v\n", aws.ToString(shard.ShardId), err)
+				}
+			}()
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(c.DiscoverInterval):
+		}
+	}
+}
+
+func (c *Consumer) discover(ctx context.Context) (string, map[string]streamTypes.Shard, error) {
+	table, err := c.Client.DescribeTable(ctx, c.TableName)
+	if err != nil {
+		return "", nil, err
+	}
+	if table.LatestStreamArn == nil {
+		return "", nil, fmt.Errorf("table `%s` has no stream enabled", c.TableName)
+	}
+	streamARN := *table.LatestStreamArn
+
+	shards := map[string]streamTypes.Shard{}
+	input := &dynamodbstreams.DescribeStreamInput{StreamArn: aws.String(streamARN)}
+	for {
+		output, err := c.Streams.DescribeStream(ctx, input)
+		if err != nil {
+			return "", nil, err
+		}
+		for _, shard := range output.StreamDescription.Shards {
+			shards[aws.ToString(shard.ShardId)] = shard
+		}
+		if output.StreamDescription.LastEvaluatedShardId == nil {
+			break
+		}
+		input.ExclusiveStartShardId = output.StreamDescription.LastEvaluatedShardId
+	}
+	return streamARN, shards, nil
+}
+
+// ready reports whether a shard is unfinished and its parent, if still in the stream, is finished.
+func (c *Consumer) ready(ctx context.Context, streamARN string, shard streamTypes.Shard, shards map[string]streamTypes.Shard) (bool, error) {
+	lease, err := c.Leases.Get(ctx, streamARN, aws.ToString(shard.ShardId))
+	if err != nil || (lease != nil && lease.Finished) {
+		return false, err
+	}
+
+	parentID := aws.ToString(shard.ParentShardId)
+	if _, ok := shards[parentID]; parentID == "" || !ok {
+		// The parent has been trimmed from the stream, nothing left to wait for.
+		return true, nil
+	}
+	parent, err := c.Leases.Get(ctx, streamARN, parentID)
+	if err != nil {
+		return false, err
+	}
+	return parent != nil && parent.Finished, nil
+}
+
+func (c *Consumer) process(ctx context.Context, streamARN, shardID string) error {
+	lease, err := c.Leases.Get(ctx, streamARN, shardID)
+	if err != nil {
+		return err
+	}
+
+	input := &dynamodbstreams.GetShardIteratorInput{
+		StreamArn:         aws.String(streamARN),
+		ShardId:           aws.String(shardID),
+		ShardIteratorType: streamTypes.ShardIteratorTypeTrimHorizon,
+	}
+	sequenceNumber := ""
+	if lease != nil && lease.SequenceNumber != "" {
+		sequenceNumber = lease.SequenceNumber
+		input.ShardIteratorType = streamTypes.ShardIteratorTypeAfterSequenceNumber
+		input.SequenceNumber = aws.String(sequenceNumber)
+	}
+
+	iterator, err := c.Streams.GetShardIterator(ctx, input)
+	if err != nil {
+		return err
+	}
+	shardIterator := iterator.ShardIterator
+
+	for shardIterator != nil {
+		output, err := c.Streams.GetRecords(ctx, &dynamodbstreams.GetRecordsInput{ShardIterator: shardIterator})
+		if err != nil {
+			return err
+		}
+
+		for _, record := range output.Records {
+			if err := c.Handler(ctx, record); err != nil {
+				// Stop here; the shard is picked up again from the last checkpoint on the next discovery.
+				return fmt.Errorf("handler failed on %s: %v", aws.ToString(record.EventID), err)
+			}
+			if record.Dynamodb != nil {
+				sequenceNumber = aws.ToString(record.Dynamodb.SequenceNumber)
+			}
+		}
+		if len(output.Records) > 0 {
+			if err := c.Leases.Checkpoint(ctx, streamARN, shardID, sequenceNumber, false); err != nil {
+				return err
+			}
+		}
+
+		shardIterator = output.NextShardIterator
+		if shardIterator != nil && len(output.Records) == 0 {
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-time.After(c.PollInterval):
+			}
+		}
+	}
+
+	// A nil iterator means the shard is closed and fully read.
+	return c.Leases.Checkpoint(ctx, streamARN, shardID, sequenceNumber, true)
+}
+
+func (c *Consumer) start(shardID string) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.active[shardID] {
+		return false
+	}
+	c.active[shardID] = true
+	return true
+}
+
+func (c *Consumer) stop(shardID string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.active, shardID)
+}
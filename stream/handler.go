@@ -0,0 +1,80 @@
+package stream
+
+import (
+	"context"
+
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	streamTypes "github.com/aws/aws-sdk-go-v2/service/dynamodbstreams/types"
+)
+
+const (
+	EventInsert = "INSERT"
+	EventModify = "MODIFY"
+	EventRemove = "REMOVE"
+)
+
+// Change is a stream record with its images unmarshalled into T. Old is nil for inserts and New is nil for removes,
+// and both depend on the table's StreamViewType.
+type Change[T any] struct {
+	EventID        string
+	EventName      string
+	SequenceNumber string
+	Keys           map[string]types.AttributeValue
+	Old            *T
+	New            *T
+}
+
+type Handler[T any] func(ctx context.Context, change Change[T]) error
+
+// RecordHandler receives raw stream records. Use HandleFunc to get typed changes instead.
+type RecordHandler func(ctx context.Context, record streamTypes.Record) error
+
+// HandleFunc adapts a typed handler, e.g. Handler[model.MovieItem], to a RecordHandler.
+func HandleFunc[T any](handler Handler[T]) RecordHandler {
+	return func(ctx context.Context, record streamTypes.Record) error {
+		change := Change[T]{
+			EventID:   stringValue(record.EventID),
+			EventName: string(record.EventName),
+		}
+		if record.Dynamodb == nil {
+			return handler(ctx, change)
+		}
+
+		change.SequenceNumber = stringValue(record.Dynamodb.SequenceNumber)
+
+		var err error
+		if change.Keys, err = attributevalue.FromDynamoDBStreamsMap(record.Dynamodb.Keys); err != nil {
+			return err
+		}
+		if change.Old, err = unmarshalImage[T](record.Dynamodb.OldImage); err != nil {
+			return err
+		}
+		if change.New, err = unmarshalImage[T](record.Dynamodb.NewImage); err != nil {
+			return err
+		}
+		return handler(ctx, change)
+	}
+}
+
+func unmarshalImage[T any](image map[string]streamTypes.AttributeValue) (*T, error) {
+	if image == nil {
+		return nil, nil
+	}
+	av, err := attributevalue.FromDynamoDBStreamsMap(image)
+	if err != nil {
+		return nil, err
+	}
+	var v T
+	if err := attributevalue.UnmarshalMap(av, &v); err != nil {
+		return nil, err
+	}
+	return &v, nil
+}
+
+func stringValue(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
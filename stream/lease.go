@@ -0,0 +1,81 @@
+package stream
+
+import (
+	"context"
+	dynamodbClient "dytest/dynamodb"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+const DefaultLeaseTable = "StreamLeases"
+
+// Lease is the checkpoint of one shard. Finished marks a closed shard whose children may start.
+type Lease struct {
+	LeaseKey       string    `dynamodbav:"leaseKey"`
+	StreamARN      string    `dynamodbav:"streamArn"`
+	ShardID        string    `dynamodbav:"shardId"`
+	SequenceNumber string    `dynamodbav:"sequenceNumber"`
+	Finished       bool      `dynamodbav:"finished"`
+	Owner          string    `dynamodbav:"owner"`
+	UpdatedAt      time.Time `dynamodbav:"updatedAt"`
+}
+
+type LeaseStore struct {
+	Client    dynamodbClient.DynamodbClient
+	TableName string
+	Owner     string
+}
+
+func leaseKey(streamARN, shardID string) string {
+	return streamARN + "#" + shardID
+}
+
+// EnsureTable creates the lease table when it does not exist yet.
+func (s *LeaseStore) EnsureTable(ctx context.Context) error {
+	if _, err := s.Client.DescribeTable(ctx, s.TableName); err == nil {
+		return nil
+	}
+	return s.Client.CreateTable(ctx, s.TableName, &dynamodb.CreateTableInput{
+		AttributeDefinitions: []types.AttributeDefinition{
+			{AttributeName: aws.String("leaseKey"), AttributeType: types.ScalarAttributeTypeS},
+		},
+		KeySchema: []types.KeySchemaElement{
+			{AttributeName: aws.String("leaseKey"), KeyType: types.KeyTypeHash},
+		},
+		BillingMode: types.BillingModePayPerRequest,
+	})
+}
+
+// Get returns the lease of a shard, or nil when the shard was never checkpointed.
+func (s *LeaseStore) Get(ctx context.Context, streamARN, shardID string) (*Lease, error) {
+	key, err := attributevalue.Marshal(leaseKey(streamARN, shardID))
+	if err != nil {
+		return nil, err
+	}
+
+	lease := &Lease{}
+	output, err := s.Client.TransactGetItem(ctx, s.TableName, map[string]types.AttributeValue{"leaseKey": key}, lease)
+	if err != nil {
+		return nil, err
+	}
+	if output.Responses[0].Item == nil {
+		return nil, nil
+	}
+	return lease, nil
+}
+
+func (s *LeaseStore) Checkpoint(ctx context.Context, streamARN, shardID, sequenceNumber string, finished bool) error {
+	return s.Client.TransactWriteItems(ctx, s.TableName, Lease{
+		LeaseKey:       leaseKey(streamARN, shardID),
+		StreamARN:      streamARN,
+		ShardID:        shardID,
+		SequenceNumber: sequenceNumber,
+		Finished:       finished,
+		Owner:          s.Owner,
+		UpdatedAt:      time.Now().UTC(),
+	})
+}
@@ -0,0 +1,79 @@
+package test1
+
+import (
+	"bufio"
+	dynamodbClient "dytest/dynamodb"
+	"dytest/events"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type EventsController struct {
+	Broker    *events.Broker
+	TableName string
+}
+
+// MovieEvents streams movie changes as Server-Sent Events. Clients resume with the
+// Last-Event-ID header, or the lastEventId query parameter where headers can't be set.
+func (ec *EventsController) MovieEvents(c *fiber.Ctx) error {
+	lastID := c.Get("Last-Event-ID", c.Query("lastEventId"))
+	tenantID, _ := dynamodbClient.TenantFromContext(c.UserContext())
+
+	c.Set(fiber.HeaderContentType, "text/event-stream")
+	c.Set(fiber.HeaderCacheControl, "no-cache")
+	c.Set(fiber.HeaderConnection, "keep-alive")
+	c.Set("X-Accel-Buffering", "no")
+
+	replay, ch, cancel := ec.Broker.Subscribe(lastID)
+
+	c.Context().SetBodyStreamWriter(func(w *bufio.Writer) {
+		defer cancel()
+
+		heartbeat := time.NewTicker(15 * time.Second)
+		defer heartbeat.Stop()
+
+		for _, e := range replay {
+			if err := ec.write(w, e, tenantID); err != nil {
+				return
+			}
+		}
+		if err := w.Flush(); err != nil {
+			return
+		}
+
+		for {
+			select {
+			case e, ok := <-ch:
+				if !ok {
+					return
+				}
+				if err := ec.write(w, e, tenantID); err != nil {
+					return
+				}
+			case <-heartbeat.C:
+				fmt.Fprint(w, ": ping\n\n")
+			}
+			// Flush fails once the client is gone, which ends the stream.
+			if err := w.Flush(); err != nil {
+				return
+			}
+		}
+	})
+
+	return nil
+}
+
+func (ec *EventsController) write(w *bufio.Writer, e events.Event, tenantID string) error {
+	if e.Table != ec.TableName || e.Tenant != tenantID {
+		return nil
+	}
+	data, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(w, "id: %s\nevent: %s\ndata: %s\n\n", e.ID, e.Type, data)
+	return err
+}
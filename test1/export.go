@@ -0,0 +1,189 @@
+package test1
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+	"strings"
+
+	dynamodbClient "dytest/dynamodb"
+	"dytest/export"
+	"dytest/jobs"
+	"dytest/problem"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type ExportController struct {
+	Client   dynamodbClient.DynamodbClient
+	PageSize int
+	// Columns are the CSV columns used when the request names neither columns nor fields.
+	Columns []export.Column
+	// Jobs runs exports, writing them to DataDir, unless a request sends Prefer: wait.
+	Jobs    *jobs.Runner
+	DataDir string
+}
+
+// exportRequest holds the parameters of an export; an export job keeps it as its input.
+type exportRequest struct {
+	Format  export.Format `json:"format"`
+	Year    string        `json:"year,omitempty"`
+	Filter  string        `json:"filter,omitempty"`
+	Fields  string        `json:"fields,omitempty"`
+	Columns string        `json:"columns,omitempty"`
+}
+
+// ExportMovies exports every movie as ndjson, csv or json, one page at a time.
+// ?year= queries that year's partition, ?filter= and ?fields= narrow and project the items,
+// and ?columns= picks the CSV columns. The export runs as a job whose file is downloaded
+// from /jobs/{id}/result; with Prefer: wait it is streamed in the response instead.
+func (ec *ExportController) ExportMovies(c *fiber.Ctx) error {
+	request := exportRequest{
+		Format:  export.Format(c.Query("format", string(export.FormatNDJSON))),
+		Year:    c.Query("year"),
+		Filter:  c.Query("filter"),
+		Fields:  c.Query("fields"),
+		Columns: c.Query("columns"),
+	}
+	pages, columns, err := ec.prepare(request)
+	if err != nil {
+		return err
+	}
+	if ec.Jobs != nil && runsAsync(c) {
+		return submitJob(c, ec.Jobs, JobExport, request)
+	}
+
+	// The first page is read before the response starts, so a bad filter or an unavailable table is still a problem response.
+	ctx := c.UserContext()
+	pages, err = export.Prefetch(ctx, pages)
+	if err != nil {
+		return fmt.Errorf("failed to export movies: %w", err)
+	}
+
+	c.Set(fiber.HeaderContentType, request.Format.ContentType())
+	c.Set(fiber.HeaderContentDisposition, fmt.Sprintf(`attachment; filename="movies.%s"`, request.Format))
+	c.Context().SetBodyStreamWriter(func(w *bufio.Writer) {
+		streamExport(ctx, pages, request.Format, w, columns)
+	})
+	return nil
+}
+
+// RunExportJob writes an export to a file in DataDir. A restarted job writes the file again from the start.
+func (ec *ExportController) RunExportJob(ctx context.Context, job *jobs.Job, progress func(jobs.Progress)) (any, error) {
+	var request exportRequest
+	if err := job.Decode(&request); err != nil {
+		return nil, err
+	}
+	pages, columns, err := ec.prepare(request)
+	if err != nil {
+		return nil, err
+	}
+
+	path := exportFile(ec.DataDir, job.ID, request.Format)
+	f, err := os.Create(path + ".tmp")
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	enc, err := export.NewEncoder(request.Format, bufio.NewWriter(f), columns)
+	if err != nil {
+		return nil, err
+	}
+
+	var done int64
+	counted := func(ctx context.Context, cursor string, items *[]map[string]any) (string, error) {
+		next, err := pages(ctx, cursor, items)
+		done += int64(len(*items))
+		progress(jobs.Progress{Done: done, Message: "movies exported"})
+		return next, err
+	}
+	written, err := export.Copy(ctx, counted, enc)
+	if err != nil {
+		f.Close()
+		os.Remove(path + ".tmp")
+		return nil, fmt.Errorf("failed to export movies: %w", err)
+	}
+	if err := f.Close(); err != nil {
+		return nil, err
+	}
+	if err := os.Rename(path+".tmp", path); err != nil {
+		return nil, err
+	}
+	return map[string]any{"format": request.Format, "items": written}, nil
+}
+
+// prepare checks an export's parameters and returns its pages and CSV columns.
+func (ec *ExportController) prepare(request exportRequest) (export.Pages, []export.Column, error) {
+	switch request.Format {
+	case export.FormatNDJSON, export.FormatCSV, export.FormatJSON:
+	default:
+		return nil, nil, problem.BadRequest("format must be ndjson, csv or json")
+	}
+
+	var fields []string
+	if request.Fields != "" {
+		fields = strings.Split(request.Fields, ",")
+	}
+	expressions, err := export.Parse(request.Filter, fields)
+	if err != nil {
+		return nil, nil, problem.BadRequest(err.Error())
+	}
+
+	columns := ec.Columns
+	if request.Columns != "" {
+		columns, err = export.ParseColumns(strings.Split(request.Columns, ","))
+	} else if len(fields) > 0 {
+		columns, err = export.ParseColumns(fields)
+	}
+	if err != nil {
+		return nil, nil, problem.BadRequest(err.Error())
+	}
+	if request.Format == export.FormatCSV && len(columns) == 0 {
+		return nil, nil, problem.BadRequest("columns are required for csv")
+	}
+
+	if request.Year == "" {
+		return export.ScanPages(ec.Client, moviesTable, dynamodbClient.ScanRequest{
+			Filter:     expressions.Filter,
+			Projection: expressions.Projection,
+			Names:      expressions.Names,
+			Values:     expressions.Values,
+			Limit:      int32(ec.PageSize),
+		}), columns, nil
+	}
+
+	year, err := strconv.Atoi(request.Year)
+	if err != nil {
+		return nil, nil, problem.BadRequest("year must be a number")
+	}
+	names := map[string]string{"#year": "year"}
+	for placeholder, name := range expressions.Names {
+		names[placeholder] = name
+	}
+	values := map[string]any{":year": year}
+	for placeholder, value := range expressions.Values {
+		values[placeholder] = value
+	}
+	return export.QueryPages(ec.Client, moviesTable, dynamodbClient.QueryRequest{
+		KeyCondition: "#year = :year",
+		Filter:       expressions.Filter,
+		Projection:   expressions.Projection,
+		Names:        names,
+		Values:       values,
+		Limit:        int32(ec.PageSize),
+	}), columns, nil
+}
+
+// streamExport runs after the handler has returned; an error can only cut the body short.
+func streamExport(ctx context.Context, pages export.Pages, format export.Format, w *bufio.Writer, columns []export.Column) {
+	enc, err := export.NewEncoder(format, w, columns)
+	if err == nil {
+		_, err = export.Copy(ctx, pages, enc)
+	}
+	if err != nil {
+		log.Printf("Movie export stopped: %v\n", err)
+	}
+}
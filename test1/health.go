@@ -0,0 +1,39 @@
+package test1
+
+import (
+	"context"
+	dynamodbClient "dytest/dynamodb"
+	"dytest/problem"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type HealthController struct {
+	Client         dynamodbClient.DynamodbClient
+	RequiredTables []string
+}
+
+type HealthStatus struct {
+	Status string `json:"status"`
+}
+
+// Liveness only reports that the process is serving requests.
+func (h *HealthController) Liveness(c *fiber.Ctx) error {
+	return c.JSON(HealthStatus{Status: "ok"})
+}
+
+// Readiness reports whether DynamoDB is reachable and the required tables are ACTIVE.
+func (h *HealthController) Readiness(c *fiber.Ctx) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	// The probe is unauthenticated, so the cause is only logged.
+	if err := h.Client.HealthCheck(ctx, h.RequiredTables...); err != nil {
+		log.Printf("Readiness check failed: %v\n", err)
+		return problem.New(http.StatusServiceUnavailable, problem.CodeUnavailable, "DynamoDB or a required table is not available")
+	}
+	return c.JSON(HealthStatus{Status: "ok"})
+}
@@ -0,0 +1,217 @@
+package test1
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"path/filepath"
+
+	dynamodbClient "dytest/dynamodb"
+	"dytest/export"
+	"dytest/importer"
+	"dytest/jobs"
+	"dytest/problem"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+type ImportController struct {
+	Client dynamodbClient.DynamodbClient
+	Store  *importer.TableStore
+	Rate   int
+	// Columns map CSV headers to attribute paths, as for exports.
+	Columns []export.Column
+	// Jobs runs imports from the upload saved in DataDir, unless a request sends Prefer: wait.
+	Jobs    *jobs.Runner
+	DataDir string
+	// MaxUploadBytes is the largest upload accepted. Uploads are read as they arrive, so it may
+	// be well above the app's BodyLimit.
+	MaxUploadBytes int
+}
+
+// importJob is the input of an import job.
+type importJob struct {
+	ImportID  string        `json:"importId"`
+	TableName string        `json:"tableName"`
+	Format    export.Format `json:"format"`
+	File      string        `json:"file"`
+}
+
+// ImportRows loads the uploaded file into ?table=. The format comes from ?format= or the content type.
+// Pass the id of a failed import as ?id= with the same file to resume after its checkpoint.
+// The upload is saved and imported by a job; with Prefer: wait it is imported while the request waits.
+func (ic *ImportController) ImportRows(c *fiber.Ctx) error {
+	tableName := c.Query("table", moviesTable)
+	if _, ok := importer.Models[tableName]; !ok {
+		return problem.BadRequest(fmt.Sprintf("Rows cannot be imported into %q", tableName))
+	}
+
+	format := export.Format(c.Query("format"))
+	if format == "" {
+		switch mediaType(c) {
+		case "application/x-ndjson":
+			format = export.FormatNDJSON
+		case "text/csv":
+			format = export.FormatCSV
+		default:
+			format = export.FormatJSON
+		}
+	}
+	if c.Request().Header.ContentLength() > ic.MaxUploadBytes {
+		return ic.tooLarge()
+	}
+	body := ic.upload(c)
+	rows, err := importer.NewReader(format, body, ic.Columns, importer.TextFields(tableName))
+	if err != nil {
+		return problem.BadRequest("format must be ndjson, csv or json")
+	}
+
+	imp := &importer.Import{ID: uuid.NewString(), TableName: tableName, Format: string(format)}
+	if id := c.Query("id"); id != "" {
+		imp, err = ic.Store.Load(c.UserContext(), id)
+		if errors.Is(err, importer.ErrImportNotFound) {
+			return problem.NotFound("Import not found")
+		}
+		if err != nil {
+			return fmt.Errorf("failed to load import: %w", err)
+		}
+		if imp.TableName != tableName || imp.Format != string(format) {
+			return problem.Conflict(fmt.Sprintf("Import %s is a %s import into %s", id, imp.Format, imp.TableName))
+		}
+	}
+
+	if ic.Jobs != nil && runsAsync(c) {
+		file := filepath.Join(ic.DataDir, imp.ID+".upload")
+		if err := saveUpload(file, body); err != nil {
+			os.Remove(file)
+			if body.exceeded {
+				return ic.tooLarge()
+			}
+			return fmt.Errorf("failed to save upload: %w", err)
+		}
+		return submitJob(c, ic.Jobs, JobImport, importJob{ImportID: imp.ID, TableName: tableName, Format: format, File: file})
+	}
+
+	// The Location stays on an error response, so the caller knows which id to resume.
+	c.Location("/imports/" + imp.ID)
+	im := &importer.Importer{Client: ic.Client, TableName: tableName, Store: ic.Store, Rate: ic.Rate}
+	if err := im.Run(c.UserContext(), imp, rows); err != nil {
+		if body.exceeded {
+			return ic.tooLarge()
+		}
+		return fmt.Errorf("import %s stopped at row %d: %w", imp.ID, imp.Row, err)
+	}
+	return c.JSON(imp)
+}
+
+func (ic *ImportController) tooLarge() error {
+	return problem.Status(http.StatusRequestEntityTooLarge, fmt.Sprintf("The upload is larger than %d bytes", ic.MaxUploadBytes))
+}
+
+// upload reads the request body as it arrives: the app streams bodies larger than its BodyLimit
+// instead of buffering them.
+func (ic *ImportController) upload(c *fiber.Ctx) *uploadReader {
+	body := c.Context().RequestBodyStream()
+	if body == nil {
+		body = bytes.NewReader(c.Body())
+	}
+	return &uploadReader{r: body, left: int64(ic.MaxUploadBytes)}
+}
+
+// uploadReader fails once more than left bytes have been read, and marks the upload exceeded.
+type uploadReader struct {
+	r        io.Reader
+	left     int64
+	exceeded bool
+}
+
+var errUploadTooLarge = errors.New("the upload is too large")
+
+func (u *uploadReader) Read(p []byte) (int, error) {
+	if u.left <= 0 {
+		var extra [1]byte
+		n, err := io.ReadFull(u.r, extra[:])
+		if n > 0 {
+			u.exceeded = true
+			return 0, errUploadTooLarge
+		}
+		return 0, err
+	}
+	if int64(len(p)) > u.left {
+		p = p[:u.left]
+	}
+	n, err := u.r.Read(p)
+	u.left -= int64(n)
+	return n, err
+}
+
+func saveUpload(file string, body io.Reader) error {
+	f, err := os.Create(file)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(f, body); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+func (ic *ImportController) GetImport(c *fiber.Ctx) error {
+	imp, err := ic.Store.Load(c.UserContext(), c.Params("id"))
+	if errors.Is(err, importer.ErrImportNotFound) {
+		return problem.NotFound("Import not found")
+	}
+	if err != nil {
+		return fmt.Errorf("failed to load import: %w", err)
+	}
+	return c.JSON(imp)
+}
+
+// RunImportJob imports a saved upload. The import's checkpoint lets a restarted job skip the rows it has handled.
+func (ic *ImportController) RunImportJob(ctx context.Context, job *jobs.Job, progress func(jobs.Progress)) (any, error) {
+	var input importJob
+	if err := job.Decode(&input); err != nil {
+		return nil, err
+	}
+	f, err := os.Open(input.File)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	rows, err := importer.NewReader(input.Format, f, ic.Columns, importer.TextFields(input.TableName))
+	if err != nil {
+		return nil, err
+	}
+
+	imp, err := ic.Store.Load(ctx, input.ImportID)
+	if errors.Is(err, importer.ErrImportNotFound) {
+		imp = &importer.Import{ID: input.ImportID, TableName: input.TableName, Format: string(input.Format)}
+	} else if err != nil {
+		return nil, fmt.Errorf("failed to load import: %w", err)
+	}
+
+	im := &importer.Importer{
+		Client:    ic.Client,
+		TableName: input.TableName,
+		Store:     ic.Store,
+		Rate:      ic.Rate,
+		Progress: func(imp *importer.Import) {
+			progress(jobs.Progress{Done: int64(imp.Row), Message: fmt.Sprintf("%d rows written, %d rejected", imp.Written, imp.Rejected)})
+		},
+	}
+	if err := im.Run(ctx, imp, rows); err != nil {
+		return nil, fmt.Errorf("import %s stopped at row %d: %w", imp.ID, imp.Row, err)
+	}
+	f.Close()
+	if err := os.Remove(input.File); err != nil {
+		log.Printf("Failed to remove upload %s: %v\n", input.File, err)
+	}
+	return imp, nil
+}
@@ -0,0 +1,111 @@
+package test1
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"dytest/export"
+	"dytest/jobs"
+	"dytest/problem"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// Job kinds.
+const (
+	JobCreateTable = "create-table"
+	JobImport      = "import"
+	JobExport      = "export"
+)
+
+type JobController struct {
+	Runner *jobs.Runner
+	// DataDir holds uploads waiting to be imported and finished exports.
+	DataDir string
+}
+
+// runsAsync reports whether the request runs as a job, which is the default for long-running
+// requests. A request that sends the wait preference of RFC 7240, e.g. Prefer: wait=600, waits
+// for the result instead; the duration is not enforced.
+func runsAsync(c *fiber.Ctx) bool {
+	for _, preference := range strings.Split(c.Get("Prefer"), ",") {
+		name, _, _ := strings.Cut(strings.TrimSpace(preference), "=")
+		if strings.EqualFold(strings.TrimSpace(name), "wait") {
+			return false
+		}
+	}
+	return true
+}
+
+func submitJob(c *fiber.Ctx, runner *jobs.Runner, kind string, input any) error {
+	job, err := runner.Submit(c.UserContext(), kind, input)
+	if err != nil {
+		return fmt.Errorf("failed to submit %s job: %w", kind, err)
+	}
+	c.Location("/jobs/" + job.ID)
+	return c.Status(http.StatusAccepted).JSON(job)
+}
+
+func (jc *JobController) GetJob(c *fiber.Ctx) error {
+	job, err := jc.Runner.Store.Load(c.UserContext(), c.Params("id"))
+	if errors.Is(err, jobs.ErrJobNotFound) {
+		return problem.NotFound("Job not found")
+	}
+	if err != nil {
+		return fmt.Errorf("failed to load job: %w", err)
+	}
+	return c.JSON(job)
+}
+
+func (jc *JobController) CancelJob(c *fiber.Ctx) error {
+	job, err := jc.Runner.Cancel(c.UserContext(), c.Params("id"))
+	switch {
+	case errors.Is(err, jobs.ErrJobNotFound):
+		return problem.NotFound("Job not found")
+	case errors.Is(err, jobs.ErrJobFinished):
+		return problem.Conflict(fmt.Sprintf("The job has already %s", job.Status))
+	case err != nil:
+		return err
+	}
+	return c.Status(http.StatusAccepted).JSON(job)
+}
+
+// GetJobResult downloads the file a succeeded export job wrote.
+func (jc *JobController) GetJobResult(c *fiber.Ctx) error {
+	job, err := jc.Runner.Store.Load(c.UserContext(), c.Params("id"))
+	if errors.Is(err, jobs.ErrJobNotFound) {
+		return problem.NotFound("Job not found")
+	}
+	if err != nil {
+		return fmt.Errorf("failed to load job: %w", err)
+	}
+	if job.Kind != JobExport {
+		return problem.NotFound("The job has no result file")
+	}
+	if job.Status != jobs.StatusSucceeded {
+		return problem.Conflict(fmt.Sprintf("The job is %s", job.Status))
+	}
+
+	var input exportRequest
+	if err := job.Decode(&input); err != nil {
+		return fmt.Errorf("invalid export job %s: %w", job.ID, err)
+	}
+	f, err := os.Open(exportFile(jc.DataDir, job.ID, input.Format))
+	if errors.Is(err, os.ErrNotExist) {
+		return problem.NotFound("The export file is gone")
+	}
+	if err != nil {
+		return err
+	}
+	c.Set(fiber.HeaderContentType, input.Format.ContentType())
+	c.Set(fiber.HeaderContentDisposition, fmt.Sprintf(`attachment; filename="movies.%s"`, input.Format))
+	return c.SendStream(f)
+}
+
+func exportFile(dataDir, jobID string, format export.Format) string {
+	return filepath.Join(dataDir, jobID+"."+string(format))
+}
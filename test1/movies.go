@@ -0,0 +1,275 @@
+package test1
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	dynamodbClient "dytest/dynamodb"
+	"dytest/model"
+	"dytest/patch"
+	"dytest/problem"
+	"dytest/validation"
+	"dytest/webhook"
+
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/gofiber/fiber/v2"
+)
+
+const (
+	defaultPageSize = 20
+	maxPageSize     = 100
+)
+
+func movieKey(title string, year int) (map[string]types.AttributeValue, error) {
+	titleAttr, err := attributevalue.Marshal(title)
+	if err != nil {
+		return nil, err
+	}
+	yearAttr, err := attributevalue.Marshal(year)
+	if err != nil {
+		return nil, err
+	}
+	return map[string]types.AttributeValue{"title": titleAttr, "year": yearAttr}, nil
+}
+
+func movieLocation(title string, year int) string {
+	return fmt.Sprintf("/movies/%d/%s", year, url.PathEscape(title))
+}
+
+// moviePath reads the :year and :title route parameters.
+func moviePath(c *fiber.Ctx) (string, int, error) {
+	year, err := strconv.Atoi(c.Params("year"))
+	if err != nil {
+		return "", 0, errors.New("year must be a number")
+	}
+	title, err := url.PathUnescape(c.Params("title"))
+	if err != nil || title == "" {
+		return "", 0, errors.New("invalid title")
+	}
+	return title, year, nil
+}
+
+// ListMovies pages through the movies. With ?year= it queries that year's partition,
+// otherwise it scans; ?titlePrefix= narrows either.
+func (cs *DynamoDBController2) ListMovies(c *fiber.Ctx) error {
+	limit := c.QueryInt("limit", defaultPageSize)
+	if limit < 1 || limit > maxPageSize {
+		return problem.BadRequest(fmt.Sprintf("limit must be between 1 and %d", maxPageSize))
+	}
+	titlePrefix := c.Query("titlePrefix")
+
+	list := model.MovieList{Items: []model.MovieItem{}}
+	var err error
+
+	if c.Query("year") != "" {
+		year, convErr := strconv.Atoi(c.Query("year"))
+		if convErr != nil {
+			return problem.BadRequest("year must be a number")
+		}
+		query := dynamodbClient.QueryRequest{
+			KeyCondition: "#year = :year",
+			Names:        map[string]string{"#year": "year"},
+			Values:       map[string]any{":year": year},
+			Limit:        int32(limit),
+			Cursor:       c.Query("cursor"),
+		}
+		if titlePrefix != "" {
+			query.KeyCondition += " AND begins_with(#title, :titlePrefix)"
+			query.Names["#title"] = "title"
+			query.Values[":titlePrefix"] = titlePrefix
+		}
+		list.NextCursor, err = cs.Client.Query(c.UserContext(), moviesTable, query, &list.Items)
+	} else {
+		scan := dynamodbClient.ScanRequest{
+			Limit:  int32(limit),
+			Cursor: c.Query("cursor"),
+		}
+		if titlePrefix != "" {
+			scan.Filter = "begins_with(#title, :titlePrefix)"
+			scan.Names = map[string]string{"#title": "title"}
+			scan.Values = map[string]any{":titlePrefix": titlePrefix}
+		}
+		list.NextCursor, err = cs.Client.ScanPage(c.UserContext(), moviesTable, scan, &list.Items)
+	}
+
+	if err != nil {
+		return fmt.Errorf("failed to list movies: %w", err)
+	}
+	return c.JSON(list)
+}
+
+func (cs *DynamoDBController2) GetMovie(c *fiber.Ctx) error {
+	title, year, err := moviePath(c)
+	if err != nil {
+		return problem.BadRequest(err.Error())
+	}
+	key, err := movieKey(title, year)
+	if err != nil {
+		return problem.BadRequest(err.Error())
+	}
+
+	var movie model.MovieItem
+	err = cs.Client.GetItem(c.UserContext(), moviesTable, key, &movie)
+	if errors.Is(err, dynamodbClient.ErrNotFound) {
+		return problem.NotFound("Movie not found")
+	}
+	if err != nil {
+		return fmt.Errorf("failed to get movie item: %w", err)
+	}
+	return c.JSON(movie)
+}
+
+func (cs *DynamoDBController2) CreateMovie(c *fiber.Ctx) error {
+	var movie model.MovieItem
+	if err := c.BodyParser(&movie); err != nil {
+		return problem.BadRequest("Invalid request body")
+	}
+	if err := validation.Struct(&movie); err != nil {
+		return err
+	}
+
+	err := cs.Client.TransactWriteItems(c.UserContext(), moviesTable, movie, dynamodbClient.IfNotExists("title"))
+	if errors.Is(err, dynamodbClient.ErrConditionFailed) {
+		return problem.Conflict("Movie already exists")
+	}
+	if err != nil {
+		return fmt.Errorf("failed to save movie item: %w", err)
+	}
+
+	cs.notify(c, webhook.EventMovieCreated, movie)
+	c.Location(movieLocation(movie.Title, movie.Year))
+	return c.Status(http.StatusCreated).JSON(movie)
+}
+
+// PutMovie creates or replaces a movie. The key comes from the path; a body key must match it.
+func (cs *DynamoDBController2) PutMovie(c *fiber.Ctx) error {
+	title, year, err := moviePath(c)
+	if err != nil {
+		return problem.BadRequest(err.Error())
+	}
+
+	var movie model.MovieItem
+	if err := c.BodyParser(&movie); err != nil {
+		return problem.BadRequest("Invalid request body")
+	}
+	var mismatched []problem.FieldError
+	if movie.Title != "" && movie.Title != title {
+		mismatched = append(mismatched, problem.FieldError{Field: "title", Message: "must match the path"})
+	}
+	if movie.Year != 0 && movie.Year != year {
+		mismatched = append(mismatched, problem.FieldError{Field: "year", Message: "must match the path"})
+	}
+	if len(mismatched) > 0 {
+		return problem.Validation("Title and year in the body must match the path", mismatched...)
+	}
+	movie.Title, movie.Year = title, year
+	if err := validation.Struct(&movie); err != nil {
+		return err
+	}
+
+	err = cs.Client.TransactWriteItems(c.UserContext(), moviesTable, movie, dynamodbClient.IfNotExists("title"))
+	if err == nil {
+		cs.notify(c, webhook.EventMovieCreated, movie)
+		c.Location(movieLocation(title, year))
+		return c.Status(http.StatusCreated).JSON(movie)
+	}
+	if !errors.Is(err, dynamodbClient.ErrConditionFailed) {
+		return fmt.Errorf("failed to save movie item: %w", err)
+	}
+
+	if err := cs.Client.TransactWriteItems(c.UserContext(), moviesTable, movie); err != nil {
+		return fmt.Errorf("failed to save movie item: %w", err)
+	}
+	cs.notify(c, webhook.EventMovieUpdated, movie)
+	return c.JSON(movie)
+}
+
+// PatchMovie applies a JSON Merge Patch, or a JSON Patch when sent as application/json-patch+json.
+// Only the movie's updatable fields can be changed.
+func (cs *DynamoDBController2) PatchMovie(c *fiber.Ctx) error {
+	title, year, err := moviePath(c)
+	if err != nil {
+		return problem.BadRequest(err.Error())
+	}
+
+	var p patch.Patch
+	switch mediaType(c) {
+	case patch.JSONPatchContentType:
+		var operations []patch.Operation
+		if err := json.Unmarshal(c.Body(), &operations); err != nil {
+			return problem.BadRequest("Invalid JSON Patch document")
+		}
+		var invalid []problem.FieldError
+		if p, invalid = patch.FromJSONPatch(operations); len(invalid) > 0 {
+			return problem.Validation("Invalid patch", invalid...)
+		}
+	case patch.MergePatchContentType, fiber.MIMEApplicationJSON:
+		var doc map[string]any
+		if err := json.Unmarshal(c.Body(), &doc); err != nil {
+			return problem.BadRequest("Invalid merge patch document")
+		}
+		p = patch.FromMergePatch(doc)
+	default:
+		return problem.New(http.StatusUnsupportedMediaType, problem.CodeMediaType,
+			"Send a "+patch.MergePatchContentType+" or "+patch.JSONPatchContentType+" document")
+	}
+
+	var movie model.MovieItem
+	if err := validation.Patch(p, &movie); err != nil {
+		return err
+	}
+
+	key, err := movieKey(title, year)
+	if err != nil {
+		return problem.BadRequest(err.Error())
+	}
+	err = p.Apply(c.UserContext(), cs.Client, moviesTable, key, "title")
+	if errors.Is(err, dynamodbClient.ErrConditionFailed) {
+		if getErr := cs.Client.GetItem(c.UserContext(), moviesTable, key, &movie); errors.Is(getErr, dynamodbClient.ErrNotFound) {
+			return problem.NotFound("Movie not found")
+		}
+		return problem.Conflict("The patch does not apply to the current movie")
+	}
+	if err != nil {
+		return fmt.Errorf("failed to update movie item: %w", err)
+	}
+
+	if err := cs.Client.GetItem(c.UserContext(), moviesTable, key, &movie); err != nil {
+		return fmt.Errorf("failed to get movie item: %w", err)
+	}
+	cs.notify(c, webhook.EventMovieUpdated, movie)
+	return c.JSON(movie)
+}
+
+func mediaType(c *fiber.Ctx) string {
+	contentType, _, _ := strings.Cut(c.Get(fiber.HeaderContentType), ";")
+	return strings.ToLower(strings.TrimSpace(contentType))
+}
+
+func (cs *DynamoDBController2) DeleteMovie(c *fiber.Ctx) error {
+	title, year, err := moviePath(c)
+	if err != nil {
+		return problem.BadRequest(err.Error())
+	}
+	key, err := movieKey(title, year)
+	if err != nil {
+		return problem.BadRequest(err.Error())
+	}
+
+	err = cs.Client.DeleteItem(c.UserContext(), moviesTable, key, dynamodbClient.IfExists("title"))
+	if errors.Is(err, dynamodbClient.ErrConditionFailed) {
+		return problem.NotFound("Movie not found")
+	}
+	if err != nil {
+		return fmt.Errorf("failed to delete movie item: %w", err)
+	}
+
+	cs.notify(c, webhook.EventMovieDeleted, fiber.Map{"title": title, "year": year})
+	return c.SendStatus(http.StatusNoContent)
+}
@@ -1,9 +1,15 @@
 package test1
 
 import (
-	"context"
 	dynamodbClient "dytest/dynamodb"
+	"dytest/jobs"
 	"dytest/model"
+	"dytest/patch"
+	"dytest/problem"
+	"dytest/validation"
+	"dytest/webhook"
+	"errors"
+	"fmt"
 	"net/http"
 
 	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
@@ -11,37 +17,33 @@ import (
 	"github.com/gofiber/fiber/v2"
 )
 
-type DynamoDBController2 struct {
-	Client dynamodbClient.DynamodbClient
-}
+const moviesTable = "Movies"
 
-type ErrorMessage struct {
-	Error string `json:"error"`
+type DynamoDBController2 struct {
+	Client   dynamodbClient.DynamodbClient
+	Webhooks *webhook.Dispatcher
+	// Jobs creates tables in the background unless a request sends Prefer: wait.
+	Jobs *jobs.Runner
 }
 
-// func (cs *DynamoDBController2) GetTableList(c *fiber.Ctx) error {
-// 	res, err := cs.Client.GetTableList(context.Background())
-
-// 	if err != nil {
-// 		c.Status(http.StatusInternalServerError).JSON(ErrorMessage{Error: err.Error()})
-// 	}
-
-// 	return c.JSON(res)
-// }
-
 func (cs *DynamoDBController2) SaveMovieItem(c *fiber.Ctx) error {
 	var movie model.MovieItem
 
 	if err := c.BodyParser(&movie); err != nil {
-		return c.Status(http.StatusBadRequest).SendString("Invalid request body")
+		return problem.BadRequest("Invalid request body")
+	}
+	if err := validation.Struct(&movie); err != nil {
+		return err
 	}
 
-	err := cs.Client.TransactWriteItems(context.Background(), "Movies", movie)
+	err := cs.Client.TransactWriteItems(c.UserContext(), movie.TableName(), movie)
 
 	if err != nil {
-		return c.Status(http.StatusInternalServerError).SendString("Failed to save movie item: " + err.Error())
+		return fmt.Errorf("failed to save movie item: %w", err)
 	}
 
+	cs.notify(c, webhook.EventMovieCreated, movie)
+
 	return c.Status(http.StatusCreated).SendString("Movie item saved successfully")
 }
 
@@ -50,26 +52,23 @@ func (cs *DynamoDBController2) GetMovieItem(c *fiber.Ctx) error {
 	type keys map[string]types.AttributeValue
 	var movie model.MovieGetItem
 	if err := c.BodyParser(&movie); err != nil {
-		return c.Status(http.StatusBadRequest).SendString("Invalid request body!")
+		return problem.BadRequest("Invalid request body")
+	}
+	if err := validation.Struct(&movie); err != nil {
+		return err
 	}
 	titleAttr, _ := attributevalue.Marshal(movie.Title)
 	yearAttr, _ := attributevalue.Marshal(movie.Year)
 
 	movieResult := &model.MovieGetItem2{}
-	_, err := cs.Client.TransactGetItem(context.Background(), movie.TableName,  keys{"title": titleAttr, "year": yearAttr}, movieResult)
+	output, err := cs.Client.TransactGetItem(c.UserContext(), moviesTable, keys{"title": titleAttr, "year": yearAttr}, movieResult)
 	if err != nil {
-		return c.Status(http.StatusInternalServerError).SendString("Failed to get movie item: " + err.Error())
+		return fmt.Errorf("failed to get movie item: %w", err)
 	}
-
-	return c.JSON(movieResult)
-}
-
-func (cs *DynamoDBController2) ScanMovies(c *fiber.Ctx) error {
-	movieResult := &[]model.MovieGetItem2{}
-	_, err := cs.Client.Scan(context.Background(), "Movies2", movieResult)
-	if err != nil {
-		return c.Status(http.StatusInternalServerError).SendString("Failed to scan movies: " + err.Error())
+	if output.Responses[0].Item == nil {
+		return problem.NotFound("Movie not found")
 	}
+
 	return c.JSON(movieResult)
 }
 
@@ -78,33 +77,73 @@ func (cs *DynamoDBController2) DeleteMovieItem(c *fiber.Ctx) error {
 
 	var movie model.MovieGetItem
 	if err := c.BodyParser(&movie); err != nil {
-		return c.Status(http.StatusBadRequest).SendString("Invalid request body!")
+		return problem.BadRequest("Invalid request body")
+	}
+	if err := validation.Struct(&movie); err != nil {
+		return err
 	}
 	titleAttr, _ := attributevalue.Marshal(movie.Title)
 	yearAttr, _ := attributevalue.Marshal(movie.Year)
-	err := cs.Client.DeleteItem(context.Background(), movie.TableName, keys{"title": titleAttr, "year": yearAttr})
+	err := cs.Client.DeleteItem(c.UserContext(), moviesTable, keys{"title": titleAttr, "year": yearAttr})
 	if err != nil {
-		return c.Status(http.StatusInternalServerError).SendString("Failed to delete movie item: " + err.Error())
+		return fmt.Errorf("failed to delete movie item: %w", err)
 	}
+
+	cs.notify(c, webhook.EventMovieDeleted, fiber.Map{"title": movie.Title, "year": movie.Year})
 	return c.SendString("Movie item deleted successfully")
 }
 
+// UpdateMovieItem reads the update expression into a patch, so it is checked and applied the same
+// way as PATCH /movies/{year}/{title}.
 func (cs *DynamoDBController2) UpdateMovieItem(c *fiber.Ctx) error {
 
 	type keys map[string]types.AttributeValue
 	var requestBody model.UpdateMovie
 
 	if err := c.BodyParser(&requestBody); err != nil {
-		return c.Status(http.StatusBadRequest).SendString("Invalid request body")
+		return problem.BadRequest("Invalid request body")
+	}
+	if err := validation.Struct(&requestBody); err != nil {
+		return err
+	}
+
+	p, invalid := patch.FromUpdateExpression(requestBody.UpdateExpression, requestBody.ExpressionAttributeValues)
+	if len(invalid) > 0 {
+		return problem.Validation("Invalid update expression", invalid...)
+	}
+	var movie model.MovieItem
+	if err := validation.Patch(p, &movie); err != nil {
+		return err
 	}
 
 	titleAttr, _ := attributevalue.Marshal(requestBody.Title)
 	yearAttr, _ := attributevalue.Marshal(requestBody.Year)
 
-	err := cs.Client.UpdateItem(context.Background(), requestBody.TableName, keys{"title": titleAttr, "year": yearAttr}, requestBody.UpdateExpression, requestBody.ExpressionAttributeValues)
+	err := p.Apply(c.UserContext(), cs.Client, moviesTable, keys{"title": titleAttr, "year": yearAttr}, "title")
+	if errors.Is(err, dynamodbClient.ErrConditionFailed) {
+		return problem.NotFound("Movie not found")
+	}
 	if err != nil {
-		return c.Status(http.StatusInternalServerError).SendString("Failed to update movie item: " + err.Error())
+		return fmt.Errorf("failed to update movie item: %w", err)
 	}
 
+	cs.notify(c, webhook.EventMovieUpdated, fiber.Map{
+		"title":                     requestBody.Title,
+		"year":                      requestBody.Year,
+		"updateExpression":          requestBody.UpdateExpression,
+		"expressionAttributeValues": requestBody.ExpressionAttributeValues,
+	})
+
 	return c.SendString("Movie item updated successfully")
 }
+
+func tenantOf(c *fiber.Ctx) (string, bool) {
+	return dynamodbClient.TenantFromContext(c.UserContext())
+}
+
+// notify sends a webhook event when webhooks are enabled.
+func (cs *DynamoDBController2) notify(c *fiber.Ctx, event string, data any) {
+	if cs.Webhooks != nil {
+		cs.Webhooks.Dispatch(c.UserContext(), event, data)
+	}
+}
@@ -0,0 +1,181 @@
+package test1
+
+import (
+	dynamodbClient "dytest/dynamodb"
+	"dytest/model"
+	"dytest/problem"
+	"dytest/validation"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/gofiber/fiber/v2"
+)
+
+type PartiQLController struct {
+	Client dynamodbClient.DynamodbClient
+	// AllowWrites also accepts INSERT, UPDATE and DELETE; otherwise only SELECT runs.
+	AllowWrites bool
+}
+
+// ExecutePartiQL is an admin endpoint for ad-hoc statements. Statements without an equality
+// or IN condition on the partition key would scan the table and are refused unless allowScan is set.
+func (pc *PartiQLController) ExecutePartiQL(c *fiber.Ctx) error {
+	var requestBody model.PartiQLRequest
+
+	if err := c.BodyParser(&requestBody); err != nil {
+		return problem.BadRequest("Invalid request body")
+	}
+
+	requestBody.Statement = strings.TrimSpace(requestBody.Statement)
+	if err := validation.Struct(&requestBody); err != nil {
+		return err
+	}
+	statement := requestBody.Statement
+
+	verb := strings.ToUpper(strings.Fields(statement)[0])
+	if verb != "SELECT" && !pc.AllowWrites {
+		return problem.New(http.StatusForbidden, problem.CodeForbidden, "Only SELECT statements are allowed")
+	}
+
+	tableNames := dynamodbClient.TablesInStatement(statement)
+	if len(tableNames) == 0 {
+		return problem.BadRequest("Statement must name a table")
+	}
+
+	if verb != "INSERT" && !requestBody.AllowScan {
+		hasKey, err := pc.hasKeyCondition(c, tableNames[0], statement)
+		if err != nil {
+			return fmt.Errorf("failed to describe table: %w", err)
+		}
+		if !hasKey {
+			return problem.BadRequest("Statement has no partition key condition; set allowScan to run it anyway")
+		}
+	}
+
+	items := []map[string]any{}
+	if err := pc.Client.ExecuteStatement(c.UserContext(), statement, requestBody.Parameters, &items); err != nil {
+		return fmt.Errorf("failed to execute statement: %w", err)
+	}
+	return c.JSON(model.PartiQLResponse{Items: items})
+}
+
+func (pc *PartiQLController) hasKeyCondition(c *fiber.Ctx, tableName, statement string) (bool, error) {
+	table, err := pc.Client.DescribeTable(c.UserContext(), tableName)
+	if err != nil {
+		return false, err
+	}
+
+	var partitionKey string
+	for _, key := range table.KeySchema {
+		if key.KeyType == types.KeyTypeHash {
+			partitionKey = *key.AttributeName
+		}
+	}
+
+	tokens := partiqlTokens(statement)
+	where := slices.IndexFunc(tokens, func(token string) bool { return strings.EqualFold(token, "WHERE") })
+	if where < 0 || partitionKey == "" {
+		return false, nil
+	}
+	return keyCondition(tokens[where+1:], partitionKey), nil
+}
+
+// keyCondition reports whether a condition only matches items with the given partition key:
+// an equality or IN on the key must be one of its top-level AND terms, and OR at the top level
+// could match any other item.
+func keyCondition(tokens []string, partitionKey string) bool {
+	if len(splitTopLevel(tokens, "OR")) > 1 {
+		return false
+	}
+	for _, term := range splitTopLevel(tokens, "AND") {
+		if len(term) >= 2 && (term[0] == partitionKey || term[0] == `"`+partitionKey+`"`) &&
+			(term[1] == "=" || strings.EqualFold(term[1], "IN")) {
+			return true
+		}
+		if len(term) > 2 && term[0] == "(" && closingParen(term) == len(term)-1 && keyCondition(term[1:len(term)-1], partitionKey) {
+			return true
+		}
+	}
+	return false
+}
+
+// splitTopLevel splits tokens at the keyword, outside parentheses.
+func splitTopLevel(tokens []string, keyword string) [][]string {
+	var parts [][]string
+	depth, start := 0, 0
+	for i, token := range tokens {
+		switch {
+		case token == "(":
+			depth++
+		case token == ")":
+			depth--
+		case depth == 0 && strings.EqualFold(token, keyword):
+			parts = append(parts, tokens[start:i])
+			start = i + 1
+		}
+	}
+	return append(parts, tokens[start:])
+}
+
+// closingParen returns the index of the parenthesis closing the one tokens start with.
+func closingParen(tokens []string) int {
+	depth := 0
+	for i, token := range tokens {
+		switch token {
+		case "(":
+			depth++
+		case ")":
+			if depth--; depth == 0 {
+				return i
+			}
+		}
+	}
+	return -1
+}
+
+// partiqlTokens splits a statement into words, quoted identifiers, string literals and single
+// symbols, so that keywords and conditions inside literals are not taken for real ones.
+func partiqlTokens(statement string) []string {
+	var tokens []string
+	for i := 0; i < len(statement); {
+		ch := statement[i]
+		switch {
+		case ch == ' ' || ch == '\t' || ch == '\n' || ch == '\r':
+			i++
+		case ch == '\'' || ch == '"':
+			end := i + 1
+			for end < len(statement) {
+				if statement[end] == ch {
+					// A doubled quote is an escaped one.
+					if end+1 < len(statement) && statement[end+1] == ch {
+						end += 2
+						continue
+					}
+					break
+				}
+				end++
+			}
+			end = min(end+1, len(statement))
+			tokens = append(tokens, statement[i:end])
+			i = end
+		case isWordChar(ch):
+			end := i
+			for end < len(statement) && isWordChar(statement[end]) {
+				end++
+			}
+			tokens = append(tokens, statement[i:end])
+			i = end
+		default:
+			tokens = append(tokens, string(ch))
+			i++
+		}
+	}
+	return tokens
+}
+
+func isWordChar(ch byte) bool {
+	return ch == '_' || ch == '.' || ch == '?' || ch >= '0' && ch <= '9' || ch >= 'a' && ch <= 'z' || ch >= 'A' && ch <= 'Z'
+}
@@ -0,0 +1,152 @@
+package test1
+
+import (
+	"context"
+	"dytest/jobs"
+	"dytest/model"
+	"dytest/problem"
+	"dytest/validation"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/gofiber/fiber/v2"
+)
+
+func (cs *DynamoDBController2) GetTableList(c *fiber.Ctx) error {
+	res, err := cs.Client.ListTables(c.UserContext())
+	if err != nil {
+		return fmt.Errorf("failed to list tables: %w", err)
+	}
+	return c.JSON(res)
+}
+
+// CreateTable answers 202 right away and creates the table in a job. With Prefer: wait it
+// waits until the table is active instead, which can take minutes.
+func (cs *DynamoDBController2) CreateTable(c *fiber.Ctx) error {
+	var requestBody model.CreateTableRequest
+
+	if err := c.BodyParser(&requestBody); err != nil {
+		return problem.BadRequest("Invalid request body")
+	}
+
+	if err := validation.Struct(&requestBody); err != nil {
+		return err
+	}
+	if cs.Jobs != nil && runsAsync(c) {
+		return submitJob(c, cs.Jobs, JobCreateTable, requestBody)
+	}
+
+	err := cs.Client.CreateTable(c.UserContext(), requestBody.TableName, createTableInput(requestBody))
+	if err != nil {
+		return fmt.Errorf("failed to create table: %w", err)
+	}
+
+	return c.Status(http.StatusCreated).SendString("Table created successfully")
+}
+
+// RunCreateTableJob creates a table and waits until it is active. A restarted job waits for the
+// table the first attempt already started creating.
+func (cs *DynamoDBController2) RunCreateTableJob(ctx context.Context, job *jobs.Job, progress func(jobs.Progress)) (any, error) {
+	var requestBody model.CreateTableRequest
+	if err := job.Decode(&requestBody); err != nil {
+		return nil, err
+	}
+
+	if _, err := cs.Client.DescribeTable(ctx, requestBody.TableName); err != nil {
+		progress(jobs.Progress{Message: "creating table"})
+		err := cs.Client.CreateTable(ctx, requestBody.TableName, createTableInput(requestBody))
+		var inUse *types.ResourceInUseException
+		if err != nil && !errors.As(err, &inUse) {
+			return nil, fmt.Errorf("failed to create table: %w", err)
+		}
+	}
+
+	for {
+		description, err := cs.Client.DescribeTable(ctx, requestBody.TableName)
+		if err != nil {
+			return nil, fmt.Errorf("failed to describe table: %w", err)
+		}
+		if description.TableStatus == types.TableStatusActive {
+			return map[string]any{"tableName": requestBody.TableName, "tableStatus": description.TableStatus}, nil
+		}
+		progress(jobs.Progress{Message: "table is " + strings.ToLower(string(description.TableStatus))})
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(2 * time.Second):
+		}
+	}
+}
+
+func createTableInput(requestBody model.CreateTableRequest) *dynamodb.CreateTableInput {
+	return &dynamodb.CreateTableInput{
+		AttributeDefinitions: requestBody.AttributeDefinitions,
+		KeySchema:            requestBody.KeySchema,
+		ProvisionedThroughput: &types.ProvisionedThroughput{
+			ReadCapacityUnits:  aws.Int64(10),
+			WriteCapacityUnits: aws.Int64(10),
+		},
+	}
+}
+
+func (cs *DynamoDBController2) DeleteTable(c *fiber.Ctx) error {
+	var requestBody model.DeleteTableRequest
+
+	if err := c.BodyParser(&requestBody); err != nil {
+		return problem.BadRequest("Invalid request body")
+	}
+
+	if err := validation.Struct(&requestBody); err != nil {
+		return err
+	}
+
+	err := cs.Client.DeleteTable(c.UserContext(), requestBody.TableName)
+	if err != nil {
+		return fmt.Errorf("failed to delete table: %w", err)
+	}
+
+	return c.Status(http.StatusOK).SendString("Table deleted successfully")
+}
+
+func (cs *DynamoDBController2) UpdateTimeToLive(c *fiber.Ctx) error {
+	var requestBody model.TimeToLiveRequest
+
+	if err := c.BodyParser(&requestBody); err != nil {
+		return problem.BadRequest("Invalid request body")
+	}
+
+	if err := validation.Struct(&requestBody); err != nil {
+		return err
+	}
+
+	err := cs.Client.UpdateTimeToLive(c.UserContext(), requestBody.TableName, requestBody.AttributeName, requestBody.Enabled)
+	if err != nil {
+		return fmt.Errorf("failed to update time to live: %w", err)
+	}
+
+	return c.SendString("Time to live updated successfully")
+}
+
+func (cs *DynamoDBController2) DescribeTimeToLive(c *fiber.Ctx) error {
+	var requestBody model.DescribeTimeToLiveRequest
+
+	if err := c.BodyParser(&requestBody); err != nil {
+		return problem.BadRequest("Invalid request body")
+	}
+
+	if err := validation.Struct(&requestBody); err != nil {
+		return err
+	}
+
+	res, err := cs.Client.DescribeTimeToLive(c.UserContext(), requestBody.TableName)
+	if err != nil {
+		return fmt.Errorf("failed to describe time to live: %w", err)
+	}
+	return c.JSON(res)
+}
@@ -0,0 +1,102 @@
+package test1
+
+import (
+	"crypto/rand"
+	"dytest/model"
+	"dytest/problem"
+	"dytest/validation"
+	"dytest/webhook"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+type WebhookController struct {
+	Store *webhook.Store
+	// AllowPrivateAddresses accepts webhook URLs on loopback, link-local and private addresses.
+	AllowPrivateAddresses bool
+}
+
+func (wc *WebhookController) RegisterWebhook(c *fiber.Ctx) error {
+	var requestBody model.WebhookSubscriptionRequest
+
+	if err := c.BodyParser(&requestBody); err != nil {
+		return problem.BadRequest("Invalid request body")
+	}
+
+	if err := validation.Struct(&requestBody); err != nil {
+		return err
+	}
+	if !wc.AllowPrivateAddresses {
+		if err := webhook.CheckURL(c.UserContext(), requestBody.URL); err != nil {
+			return problem.Validation("The request body is invalid", problem.FieldError{Field: "url", Message: err.Error()})
+		}
+	}
+
+	if len(requestBody.Events) == 0 {
+		requestBody.Events = webhook.Events
+	}
+
+	if requestBody.Secret == "" {
+		secret := make([]byte, 32)
+		if _, err := rand.Read(secret); err != nil {
+			return fmt.Errorf("failed to generate secret: %w", err)
+		}
+		requestBody.Secret = hex.EncodeToString(secret)
+	}
+
+	subscription := webhook.Subscription{
+		ID:        uuid.NewString(),
+		URL:       requestBody.URL,
+		Events:    requestBody.Events,
+		Secret:    requestBody.Secret,
+		CreatedAt: time.Now().UTC(),
+	}
+	subscription.Tenant, _ = tenantOf(c)
+
+	if err := wc.Store.SaveSubscription(c.UserContext(), subscription); err != nil {
+		return fmt.Errorf("failed to save webhook: %w", err)
+	}
+
+	// The secret is only returned here, on registration.
+	return c.Status(http.StatusCreated).JSON(subscription)
+}
+
+func (wc *WebhookController) ListWebhooks(c *fiber.Ctx) error {
+	subscriptions, err := wc.Store.Subscriptions(c.UserContext())
+	if err != nil {
+		return fmt.Errorf("failed to list webhooks: %w", err)
+	}
+	for i := range subscriptions {
+		subscriptions[i].Secret = ""
+	}
+	return c.JSON(subscriptions)
+}
+
+func (wc *WebhookController) DeleteWebhook(c *fiber.Ctx) error {
+	err := wc.Store.DeleteSubscription(c.UserContext(), c.Params("id"))
+	if errors.Is(err, webhook.ErrSubscriptionNotFound) {
+		return problem.NotFound("Webhook not found")
+	}
+	if err != nil {
+		return fmt.Errorf("failed to delete webhook: %w", err)
+	}
+	return c.SendString("Webhook deleted successfully")
+}
+
+// ListDeliveries returns the delivery history of a webhook; ?status=dead_lettered lists its dead letters.
+func (wc *WebhookController) ListDeliveries(c *fiber.Ctx) error {
+	deliveries, err := wc.Store.Deliveries(c.UserContext(), c.Params("id"), c.Query("status"))
+	if errors.Is(err, webhook.ErrSubscriptionNotFound) {
+		return problem.NotFound("Webhook not found")
+	}
+	if err != nil {
+		return fmt.Errorf("failed to list deliveries: %w", err)
+	}
+	return c.JSON(deliveries)
+}
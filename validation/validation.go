@@ -0,0 +1,330 @@
+package validation
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"reflect"
+	"regexp"
+	"strings"
+
+	"dytest/model"
+	"dytest/patch"
+	"dytest/problem"
+
+	"github.com/go-playground/validator/v10"
+)
+
+var (
+	tableNamePattern = regexp.MustCompile(`^[A-Za-z0-9_.-]{3,255}$`)
+
+	// updateExpressionPattern only lets SET and REMOVE actions on attributes under info through,
+	// with every value passed as a placeholder.
+	infoPath                = `info(\.[A-Za-z_][A-Za-z0-9_]*)+`
+	setAction               = infoPath + `\s*=\s*:[A-Za-z0-9_]+`
+	setClause               = `SET\s+` + setAction + `(\s*,\s*` + setAction + `)*`
+	removeClause            = `REMOVE\s+` + infoPath + `(\s*,\s*` + infoPath + `)*`
+	updateExpressionPattern = regexp.MustCompile(`^\s*(` + setClause + `|` + removeClause + `)(\s+(` + setClause + `|` + removeClause + `))*\s*$`)
+	placeholderPattern      = regexp.MustCompile(`:[A-Za-z0-9_]+`)
+)
+
+// Schemas are the structs a map field can be checked against with the schema=<name> tag.
+var Schemas = map[string]reflect.Type{
+	"MovieInfo": reflect.TypeOf(model.MovieInfo{}),
+}
+
+var validate = newValidator()
+
+func newValidator() *validator.Validate {
+	v := validator.New(validator.WithRequiredStructEnabled())
+	v.RegisterTagNameFunc(func(field reflect.StructField) string {
+		name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if name == "-" {
+			return ""
+		}
+		if name == "" {
+			return field.Name
+		}
+		return name
+	})
+	v.RegisterValidation("tablename", func(fl validator.FieldLevel) bool {
+		return tableNamePattern.MatchString(fl.Field().String())
+	})
+	v.RegisterValidation("updateexpr", func(fl validator.FieldLevel) bool {
+		return updateExpressionPattern.MatchString(fl.Field().String())
+	})
+	// schema is checked in Struct, which reports the nested errors; the tag itself always passes.
+	v.RegisterValidation("schema", func(fl validator.FieldLevel) bool {
+		return true
+	})
+	v.RegisterStructValidation(updateMovieLevel, model.UpdateMovie{})
+	return v
+}
+
+// Struct checks v against its validate tags and returns a 422 problem listing every violation,
+// or nil when v is valid.
+func Struct(v any) error {
+	var fields []problem.FieldError
+	err := validate.Struct(v)
+
+	var invalid validator.ValidationErrors
+	if errors.As(err, &invalid) {
+		for _, fieldErr := range invalid {
+			fields = append(fields, problem.FieldError{Field: fieldPath(fieldErr), Message: message(fieldErr)})
+		}
+	} else if err != nil {
+		return err
+	}
+
+	fields = append(fields, schemaErrors(reflect.ValueOf(v))...)
+	if len(fields) > 0 {
+		return problem.Validation("The request body is invalid", fields...)
+	}
+	return nil
+}
+
+// Partial checks only the given keys of data against a schema, for patches that leave the other keys alone.
+func Partial(schema string, prefix string, data map[string]any) []problem.FieldError {
+	fields, value := decodeSchema(Schemas[schema], prefix, data)
+	if value == nil {
+		return fields
+	}
+
+	present := make([]string, 0, len(data))
+	for i := 0; i < value.Elem().NumField(); i++ {
+		field := value.Elem().Type().Field(i)
+		name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if _, ok := data[name]; ok {
+			present = append(present, field.Name)
+		}
+	}
+	if len(present) == 0 {
+		return fields
+	}
+
+	var invalid validator.ValidationErrors
+	if errors.As(validate.StructPartial(value.Interface(), present...), &invalid) {
+		for _, fieldErr := range invalid {
+			fields = append(fields, problem.FieldError{Field: prefix + "." + fieldPath(fieldErr), Message: message(fieldErr)})
+		}
+	}
+	return fields
+}
+
+// Patch checks a patch against the model's allowlist, and the values it writes into map fields
+// against their schemas. It returns a 422 problem listing every violation, or nil.
+func Patch(p patch.Patch, m model.Updatable) error {
+	fields := p.Check(m.UpdatableFields())
+
+	t := reflect.TypeOf(m)
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	for i := 0; i < t.NumField(); i++ {
+		schema := schemaTag(t.Field(i).Tag.Get("validate"))
+		if schema == "" {
+			continue
+		}
+		name, _, _ := strings.Cut(t.Field(i).Tag.Get("json"), ",")
+		fields = append(fields, patchSchemaErrors(p, name, schema)...)
+	}
+
+	if len(fields) > 0 {
+		return problem.Validation("Invalid patch", fields...)
+	}
+	return nil
+}
+
+// patchSchemaErrors collects what a patch writes under field and checks it against schema.
+func patchSchemaErrors(p patch.Patch, field, schema string) []problem.FieldError {
+	values := map[string]any{}
+	for _, change := range p.Changes {
+		if change.Remove || change.Path[0] != field {
+			continue
+		}
+		switch len(change.Path) {
+		case 1:
+			doc, ok := change.Value.(map[string]any)
+			if !ok {
+				return []problem.FieldError{{Field: field, Message: "must be an object"}}
+			}
+			maps.Copy(values, doc)
+		case 2:
+			values[change.Path[1]] = change.Value
+		default:
+			// Deeper changes are only checked for a known field.
+			if _, ok := values[change.Path[1]]; !ok {
+				values[change.Path[1]] = nil
+			}
+		}
+	}
+	if len(values) == 0 {
+		return nil
+	}
+	return Partial(schema, field, values)
+}
+
+// schemaErrors validates the map fields tagged schema=<name> as the named struct.
+func schemaErrors(v reflect.Value) []problem.FieldError {
+	for v.Kind() == reflect.Pointer {
+		v = v.Elem()
+	}
+	if v.Kind() != reflect.Struct {
+		return nil
+	}
+
+	var fields []problem.FieldError
+	for i := 0; i < v.NumField(); i++ {
+		field := v.Type().Field(i)
+		schema := schemaTag(field.Tag.Get("validate"))
+		if schema == "" || v.Field(i).Kind() != reflect.Map || v.Field(i).Len() == 0 {
+			continue
+		}
+		data, ok := v.Field(i).Interface().(map[string]interface{})
+		if !ok {
+			continue
+		}
+
+		prefix, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+		decodeErrors, value := decodeSchema(Schemas[schema], prefix, data)
+		fields = append(fields, decodeErrors...)
+		if value == nil {
+			continue
+		}
+		var invalid validator.ValidationErrors
+		if errors.As(validate.Struct(value.Interface()), &invalid) {
+			for _, fieldErr := range invalid {
+				fields = append(fields, problem.FieldError{Field: prefix + "." + fieldPath(fieldErr), Message: message(fieldErr)})
+			}
+		}
+	}
+	return fields
+}
+
+// decodeSchema converts data into a new value of schema, reporting unknown keys and wrongly typed values.
+// It returns a nil value when data does not fit the schema at all.
+func decodeSchema(schema reflect.Type, prefix string, data map[string]any) ([]problem.FieldError, *reflect.Value) {
+	if schema == nil {
+		return []problem.FieldError{{Field: prefix, Message: "has no schema"}}, nil
+	}
+
+	var fields []problem.FieldError
+	known := map[string]bool{}
+	for i := 0; i < schema.NumField(); i++ {
+		name, _, _ := strings.Cut(schema.Field(i).Tag.Get("json"), ",")
+		known[name] = true
+	}
+	for key := range data {
+		if !known[key] {
+			fields = append(fields, problem.FieldError{Field: prefix + "." + key, Message: "is not a known field"})
+		}
+	}
+
+	value := reflect.New(schema)
+	for key, raw := range data {
+		if !known[key] {
+			continue
+		}
+		encoded, err := json.Marshal(map[string]any{key: raw})
+		if err != nil {
+			return append(fields, problem.FieldError{Field: prefix + "." + key, Message: "cannot be encoded"}), nil
+		}
+		// A failed decode can leave a partial value behind, e.g. a zero *int for 1.5, which would
+		// fail the field's rules as well; only values that decode cleanly are validated.
+		var typeErr *json.UnmarshalTypeError
+		if err := json.Unmarshal(encoded, reflect.New(schema).Interface()); errors.As(err, &typeErr) {
+			fields = append(fields, problem.FieldError{Field: prefix + "." + key, Message: "must be " + describeType(typeErr.Type)})
+			continue
+		} else if err != nil {
+			fields = append(fields, problem.FieldError{Field: prefix + "." + key, Message: "is invalid"})
+			continue
+		}
+		json.Unmarshal(encoded, value.Interface())
+	}
+	return fields, &value
+}
+
+func updateMovieLevel(sl validator.StructLevel) {
+	request := sl.Current().Interface().(model.UpdateMovie)
+	for _, placeholder := range placeholderPattern.FindAllString(request.UpdateExpression, -1) {
+		if _, ok := request.ExpressionAttributeValues[placeholder]; !ok {
+			sl.ReportError(request.ExpressionAttributeValues, "expressionAttributeValues", "ExpressionAttributeValues", "missing", placeholder)
+		}
+	}
+}
+
+func schemaTag(tag string) string {
+	for _, rule := range strings.Split(tag, ",") {
+		if name, ok := strings.CutPrefix(rule, "schema="); ok {
+			return name
+		}
+	}
+	return ""
+}
+
+// fieldPath drops the struct name from the namespace: MovieItem.info.genres[0] becomes info.genres[0].
+func fieldPath(fieldErr validator.FieldError) string {
+	_, path, found := strings.Cut(fieldErr.Namespace(), ".")
+	if !found {
+		return fieldErr.Field()
+	}
+	return path
+}
+
+func message(fieldErr validator.FieldError) string {
+	param := fieldErr.Param()
+	switch fieldErr.Tag() {
+	case "required":
+		return "is required"
+	case "min":
+		if isLengthKind(fieldErr.Kind()) {
+			return fmt.Sprintf("must have at least %s elements or characters", param)
+		}
+		return "must be at least " + param
+	case "max":
+		if isLengthKind(fieldErr.Kind()) {
+			return fmt.Sprintf("must have at most %s elements or characters", param)
+		}
+		return "must be at most " + param
+	case "gte":
+		return "must be at least " + param
+	case "lte":
+		return "must be at most " + param
+	case "oneof":
+		return "must be one of: " + strings.ReplaceAll(param, " ", ", ")
+	case "url", "http_url":
+		return "must be a valid url"
+	case "datetime":
+		return "must be a date and time like 2013-09-02T00:00:00Z"
+	case "tablename":
+		return "must be 3 to 255 letters, digits, '_', '-' or '.'"
+	case "updateexpr":
+		return "may only SET or REMOVE attributes under info, with values passed as placeholders"
+	case "missing":
+		return "has no value for " + param
+	}
+	return "failed the " + fieldErr.Tag() + " rule"
+}
+
+func isLengthKind(kind reflect.Kind) bool {
+	return kind == reflect.String || kind == reflect.Slice || kind == reflect.Map || kind == reflect.Array
+}
+
+func describeType(t reflect.Type) string {
+	switch t.Kind() {
+	case reflect.String:
+		return "a string"
+	case reflect.Slice, reflect.Array:
+		return "a list of " + strings.TrimPrefix(describeType(t.Elem()), "a ") + "s"
+	case reflect.Int, reflect.Int32, reflect.Int64:
+		return "an integer"
+	case reflect.Float32, reflect.Float64:
+		return "a number"
+	case reflect.Bool:
+		return "a boolean"
+	case reflect.Pointer:
+		return describeType(t.Elem())
+	}
+	return "a " + t.String()
+}
@@ -0,0 +1,170 @@
+package validation_test
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+
+	"dytest/model"
+	"dytest/patch"
+	"dytest/problem"
+	"dytest/validation"
+)
+
+// fields returns the field paths of a validation problem, sorted, or nil when err is nil.
+func fields(t *testing.T, err error) []string {
+	t.Helper()
+	if err == nil {
+		return nil
+	}
+	var p *problem.Problem
+	if !errors.As(err, &p) || p.Status != http.StatusUnprocessableEntity || p.Code != problem.CodeValidation {
+		t.Fatalf("error = %v, want a 422 validation problem", err)
+	}
+	paths := make([]string, 0, len(p.Errors))
+	for _, field := range p.Errors {
+		paths = append(paths, field.Field)
+	}
+	sort.Strings(paths)
+	return paths
+}
+
+func TestUpdateExpression(t *testing.T) {
+	values := map[string]interface{}{":r": 8.5, ":p": "A plot", ":g": []string{"Drama"}}
+	tests := []struct {
+		name       string
+		expression string
+		fields     []string
+	}{
+		{"set", "SET info.rating = :r", nil},
+		{"set several", "SET info.rating = :r, info.plot = :p", nil},
+		{"nested attribute", "SET info.details.plot = :p", nil},
+		{"remove", "REMOVE info.rating", nil},
+		{"set and remove", "SET info.rating = :r REMOVE info.plot, info.genres", nil},
+		{"surrounding space", "  SET info.rating=:r  ", nil},
+		{"key attribute", "SET title = :p", []string{"updateExpression"}},
+		{"outside info", "SET rating = :r", []string{"updateExpression"}},
+		{"whole info", "REMOVE info", []string{"updateExpression"}},
+		{"literal value", "SET info.rating = 8", []string{"updateExpression"}},
+		{"function", "SET info.genres = list_append(info.genres, :g)", []string{"updateExpression"}},
+		{"add action", "ADD info.rank :r", []string{"updateExpression"}},
+		{"delete action", "DELETE info.genres :g", []string{"updateExpression"}},
+		{"lower case", "set info.rating = :r", []string{"updateExpression"}},
+		{"trailing comma", "SET info.rating = :r,", []string{"updateExpression"}},
+		{"missing value", "SET info.rank = :missing", []string{"expressionAttributeValues"}},
+		{"empty", "", []string{"updateExpression"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			request := model.UpdateMovie{Title: "Inception", Year: 2010, UpdateExpression: tt.expression, ExpressionAttributeValues: values}
+			if got := fields(t, validation.Struct(request)); !reflect.DeepEqual(got, tt.fields) {
+				t.Errorf("fields = %v, want %v", got, tt.fields)
+			}
+		})
+	}
+}
+
+func TestUpdateExpressionMessages(t *testing.T) {
+	request := model.UpdateMovie{Title: "Inception", Year: 2010, UpdateExpression: "SET info.rank = :rank", ExpressionAttributeValues: map[string]interface{}{}}
+	var p *problem.Problem
+	if !errors.As(validation.Struct(request), &p) || len(p.Errors) != 1 {
+		t.Fatalf("Struct = %v, want one field error", p)
+	}
+	if got := p.Errors[0]; got.Field != "expressionAttributeValues" || got.Message != "has no value for :rank" {
+		t.Errorf("field error = %+v", got)
+	}
+
+	request.UpdateExpression = "SET title = :title"
+	request.ExpressionAttributeValues[":title"] = "Interstellar"
+	if !errors.As(validation.Struct(request), &p) || len(p.Errors) != 1 {
+		t.Fatalf("Struct = %v, want one field error", p)
+	}
+	if got := p.Errors[0]; got.Field != "updateExpression" || !strings.Contains(got.Message, "under info") {
+		t.Errorf("field error = %+v", got)
+	}
+}
+
+func TestMovieInfoSchema(t *testing.T) {
+	tests := []struct {
+		name   string
+		info   map[string]interface{}
+		fields []string
+	}{
+		{"no info", nil, nil},
+		{"empty info", map[string]interface{}{}, nil},
+		{"valid", map[string]interface{}{
+			"directors":         []interface{}{"Christopher Nolan"},
+			"release_date":      "2010-07-16T00:00:00Z",
+			"rating":            8.8,
+			"genres":            []interface{}{"Action", "Sci-Fi"},
+			"image_url":         "https://example.com/inception.jpg",
+			"plot":              "A thief who steals corporate secrets.",
+			"rank":              1,
+			"running_time_secs": 8880,
+			"actors":            []interface{}{"Leonardo DiCaprio"},
+		}, nil},
+		{"unknown key", map[string]interface{}{"budget": 160000000}, []string{"info.budget"}},
+		{"wrong type", map[string]interface{}{"rating": "high"}, []string{"info.rating"}},
+		{"string for a list", map[string]interface{}{"genres": "Drama"}, []string{"info.genres"}},
+		{"fraction for an integer", map[string]interface{}{"rank": 1.5}, []string{"info.rank"}},
+		{"out of range", map[string]interface{}{"rating": 11, "running_time_secs": 0}, []string{"info.rating", "info.running_time_secs"}},
+		{"unknown genre", map[string]interface{}{"genres": []interface{}{"Drama", "Cooking"}}, []string{"info.genres[1]"}},
+		{"empty director", map[string]interface{}{"directors": []interface{}{""}}, []string{"info.directors[0]"}},
+		{"release date", map[string]interface{}{"release_date": "16 July 2010"}, []string{"info.release_date"}},
+		{"image url", map[string]interface{}{"image_url": "not a url"}, []string{"info.image_url"}},
+		{"plot", map[string]interface{}{"plot": strings.Repeat("x", 2001)}, []string{"info.plot"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			item := model.MovieItem{Title: "Inception", Year: 2010, Info: tt.info}
+			if got := fields(t, validation.Struct(item)); !reflect.DeepEqual(got, tt.fields) {
+				t.Errorf("fields = %v, want %v", got, tt.fields)
+			}
+		})
+	}
+}
+
+func TestStructFields(t *testing.T) {
+	item := model.MovieItem{Year: 1800, Info: map[string]interface{}{"rating": -1}}
+	want := []string{"info.rating", "title", "year"}
+	if got := fields(t, validation.Struct(item)); !reflect.DeepEqual(got, want) {
+		t.Errorf("fields = %v, want %v", got, want)
+	}
+
+	var p *problem.Problem
+	errors.As(validation.Struct(model.MovieItem{Title: "Inception", Year: 1800}), &p)
+	if p == nil || len(p.Errors) != 1 || p.Errors[0].Message != "must be at least 1870" {
+		t.Errorf("Struct = %+v, want year must be at least 1870", p)
+	}
+}
+
+func TestPatch(t *testing.T) {
+	tests := []struct {
+		name   string
+		patch  string
+		fields []string
+	}{
+		{"info field", `{"info": {"rating": 9}}`, nil},
+		{"remove info field", `{"info": {"rating": null}}`, nil},
+		{"deep change", `{"info": {"details": {"plot": "x"}}}`, []string{"info.details"}},
+		{"key attribute", `{"title": "Interstellar"}`, []string{"title"}},
+		{"invalid value", `{"info": {"rating": 11, "genres": ["Cooking"]}}`, []string{"info.genres[0]", "info.rating"}},
+		{"unknown key", `{"info": {"budget": 1}}`, []string{"info.budget"}},
+		{"info not an object", `{"info": "none"}`, []string{"info"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var doc map[string]any
+			if err := json.Unmarshal([]byte(tt.patch), &doc); err != nil {
+				t.Fatal(err)
+			}
+			if got := fields(t, validation.Patch(patch.FromMergePatch(doc), &model.MovieItem{})); !reflect.DeepEqual(got, tt.fields) {
+				t.Errorf("fields = %v, want %v", got, tt.fields)
+			}
+		})
+	}
+}
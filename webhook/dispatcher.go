@@ -0,0 +1,245 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	dynamodbClient "dytest/dynamodb"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	SignatureHeader = "X-Dytest-Signature"
+	TimestampHeader = "X-Dytest-Timestamp"
+	EventHeader     = "X-Dytest-Event"
+	DeliveryHeader  = "X-Dytest-Delivery"
+)
+
+type job struct {
+	event   string
+	tenant  string
+	payload []byte
+}
+
+// attemptLease is how long a delivery being tried is left alone by the sweeper.
+const attemptLease = time.Minute
+
+// Dispatcher sends events to the matching subscriptions in the background. Every delivery is
+// logged before it is first tried; a failed one keeps its next attempt time in the log, where a
+// sweeper picks it up again, so retries survive a restart. Retries back off exponentially and a
+// delivery is dead-lettered after MaxAttempts.
+type Dispatcher struct {
+	Store       *Store
+	HTTPClient  *http.Client
+	MaxAttempts int
+	Backoff     time.Duration
+	MaxBackoff  time.Duration
+	// SweepInterval is how often the log is checked for deliveries that are due again.
+	SweepInterval time.Duration
+
+	queue chan job
+	ctx   context.Context
+}
+
+func (d *Dispatcher) Start(ctx context.Context, workers int) {
+	d.ctx = ctx
+	d.queue = make(chan job, 1000)
+	for i := 0; i < workers; i++ {
+		go d.work()
+	}
+	go d.sweep()
+}
+
+// Dispatch queues an event without blocking the request that caused it.
+func (d *Dispatcher) Dispatch(ctx context.Context, event string, data any) {
+	payload, err := json.Marshal(map[string]any{"event": event, "data": data, "occurredAt": time.Now().UTC()})
+	if err != nil {
+		log.Printf("Failed to marshal webhook event %s: %v\n", event, err)
+		return
+	}
+
+	tenantID, _ := dynamodbClient.TenantFromContext(ctx)
+	select {
+	case d.queue <- job{event: event, tenant: tenantID, payload: payload}:
+	default:
+		log.Printf("Webhook queue is full, dropping %s event\n", event)
+	}
+}
+
+func (d *Dispatcher) work() {
+	for {
+		select {
+		case <-d.ctx.Done():
+			return
+		case j := <-d.queue:
+			ctx := d.ctx
+			if j.tenant != "" {
+				ctx = dynamodbClient.WithTenant(ctx, j.tenant)
+			}
+
+			subscriptions, err := d.Store.SubscriptionsFor(ctx, j.event)
+			if err != nil {
+				log.Printf("Failed to load webhook subscriptions: %v\n", err)
+				continue
+			}
+			for _, subscription := range subscriptions {
+				d.deliver(ctx, subscription, j)
+			}
+		}
+	}
+}
+
+func (d *Dispatcher) deliver(ctx context.Context, subscription Subscription, j job) {
+	now := time.Now().UTC()
+	delivery := Delivery{
+		ID:             uuid.NewString(),
+		SubscriptionID: subscription.ID,
+		Event:          j.event,
+		Payload:        string(j.payload),
+		Status:         StatusPending,
+		Tenant:         j.tenant,
+		CreatedAt:      now,
+		UpdatedAt:      now,
+		NextAttemptAt:  now.Add(attemptLease).UnixMilli(),
+	}
+	if err := d.Store.SaveDelivery(ctx, delivery); err != nil {
+		log.Printf("Failed to log webhook delivery %s: %v\n", delivery.ID, err)
+		return
+	}
+	d.attempt(ctx, subscription, delivery)
+}
+
+// attempt sends a delivery once and logs the outcome, with the next attempt time if it is retried.
+func (d *Dispatcher) attempt(ctx context.Context, subscription Subscription, delivery Delivery) {
+	delivery.Attempts++
+	delivery.LastStatusCode, delivery.LastError = d.send(ctx, subscription, delivery)
+	now := time.Now().UTC()
+	delivery.UpdatedAt = now
+
+	switch {
+	case delivery.LastError == "":
+		delivery.Status = StatusDelivered
+		delivery.NextAttemptAt = 0
+	case delivery.Attempts >= d.MaxAttempts:
+		delivery.Status = StatusDeadLettered
+		delivery.NextAttemptAt = 0
+		log.Printf("Dead-lettered webhook delivery %s to %s after %d attempts: %s\n", delivery.ID, subscription.URL, delivery.Attempts, delivery.LastError)
+	default:
+		delivery.NextAttemptAt = now.Add(d.backoff(delivery.Attempts)).UnixMilli()
+	}
+
+	if err := d.Store.SaveDelivery(ctx, delivery); err != nil {
+		log.Printf("Failed to log webhook delivery %s: %v\n", delivery.ID, err)
+	}
+}
+
+func (d *Dispatcher) backoff(attempts int) time.Duration {
+	delay := d.Backoff << (attempts - 1)
+	if delay <= 0 || delay > d.MaxBackoff {
+		return d.MaxBackoff
+	}
+	return delay
+}
+
+func (d *Dispatcher) sweep() {
+	interval := d.SweepInterval
+	if interval <= 0 {
+		interval = time.Second
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-d.ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		if err := d.retry(d.ctx); err != nil {
+			log.Printf("Failed to retry webhook deliveries: %v\n", err)
+		}
+	}
+}
+
+// retry tries every delivery that is due again once more.
+func (d *Dispatcher) retry(ctx context.Context) error {
+	deliveries, err := d.Store.DueDeliveries(ctx, time.Now())
+	if err != nil {
+		return err
+	}
+	for _, delivery := range deliveries {
+		err := d.Store.ClaimDelivery(ctx, delivery, time.Now().Add(attemptLease))
+		if errors.Is(err, dynamodbClient.ErrConditionFailed) {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+
+		tenantCtx := ctx
+		if delivery.Tenant != "" {
+			tenantCtx = dynamodbClient.WithTenant(ctx, delivery.Tenant)
+		}
+		subscription, err := d.Store.Subscription(tenantCtx, delivery.SubscriptionID)
+		if errors.Is(err, ErrSubscriptionNotFound) {
+			delivery.Status = StatusDeadLettered
+			delivery.LastError = "the webhook was deleted"
+			delivery.NextAttemptAt = 0
+			delivery.UpdatedAt = time.Now().UTC()
+			if err := d.Store.SaveDelivery(ctx, delivery); err != nil {
+				return err
+			}
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		d.attempt(tenantCtx, subscription, delivery)
+	}
+	return nil
+}
+
+func (d *Dispatcher) send(ctx context.Context, subscription Subscription, delivery Delivery) (int, string) {
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, subscription.URL, bytes.NewReader([]byte(delivery.Payload)))
+	if err != nil {
+		return 0, err.Error()
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(EventHeader, delivery.Event)
+	req.Header.Set(DeliveryHeader, delivery.ID)
+	req.Header.Set(TimestampHeader, timestamp)
+	req.Header.Set(SignatureHeader, "sha256="+Sign(subscription.Secret, timestamp, []byte(delivery.Payload)))
+
+	resp, err := d.HTTPClient.Do(req)
+	if err != nil {
+		return 0, err.Error()
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return resp.StatusCode, fmt.Sprintf("endpoint answered %s", resp.Status)
+	}
+	return resp.StatusCode, ""
+}
+
+// Sign returns the hex HMAC-SHA256 of "<timestamp>.<body>". Receivers recompute it with
+// their secret and compare it to the X-Dytest-Signature header.
+func Sign(secret, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp + "."))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,331 @@
+package webhook
+
+import (
+	"context"
+	dynamodbClient "dytest/dynamodb"
+	"errors"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+const (
+	EventMovieCreated = "movie.created"
+	EventMovieUpdated = "movie.updated"
+	EventMovieDeleted = "movie.deleted"
+
+	StatusPending      = "pending"
+	StatusDelivered    = "delivered"
+	StatusDeadLettered = "dead_lettered"
+
+	// DueIndex is a sparse index of the pending deliveries, by when they are due.
+	DueIndex = "due"
+)
+
+var (
+	Events = []string{EventMovieCreated, EventMovieUpdated, EventMovieDeleted}
+
+	ErrSubscriptionNotFound = errors.New("subscription not found")
+)
+
+type Subscription struct {
+	ID        string    `dynamodbav:"id" json:"id"`
+	URL       string    `dynamodbav:"url" json:"url"`
+	Events    []string  `dynamodbav:"events" json:"events"`
+	Secret    string    `dynamodbav:"secret" json:"secret,omitempty"`
+	Tenant    string    `dynamodbav:"tenant,omitempty" json:"-"`
+	CreatedAt time.Time `dynamodbav:"createdAt" json:"createdAt"`
+}
+
+func (s Subscription) wants(event string) bool {
+	for _, e := range s.Events {
+		if e == event {
+			return true
+		}
+	}
+	return false
+}
+
+// Delivery is the log entry of one event sent to one subscription.
+type Delivery struct {
+	ID             string    `dynamodbav:"id" json:"id"`
+	SubscriptionID string    `dynamodbav:"subscriptionId" json:"subscriptionId"`
+	Event          string    `dynamodbav:"event" json:"event"`
+	Payload        string    `dynamodbav:"payload" json:"payload"`
+	Status         string    `dynamodbav:"status" json:"status"`
+	Attempts       int       `dynamodbav:"attempts" json:"attempts"`
+	LastStatusCode int       `dynamodbav:"lastStatusCode,omitempty" json:"lastStatusCode,omitempty"`
+	LastError      string    `dynamodbav:"lastError,omitempty" json:"lastError,omitempty"`
+	Tenant         string    `dynamodbav:"tenant,omitempty" json:"-"`
+	CreatedAt      time.Time `dynamodbav:"createdAt" json:"createdAt"`
+	UpdatedAt      time.Time `dynamodbav:"updatedAt" json:"updatedAt"`
+	// Due and NextAttemptAt, when a pending delivery is tried again in Unix milliseconds, key
+	// DueIndex. SaveDelivery drops both once the delivery is no longer pending.
+	Due           string `dynamodbav:"due,omitempty" json:"-"`
+	NextAttemptAt int64  `dynamodbav:"nextAttemptAt,omitempty" json:"-"`
+}
+
+// Store keeps subscriptions and the delivery log. Both tables hold every tenant's entries,
+// and reads only return those of the tenant in ctx.
+type Store struct {
+	Client             dynamodbClient.DynamodbClient
+	SubscriptionsTable string
+	DeliveriesTable    string
+	// CacheTTL is how long SubscriptionsFor keeps a tenant's subscriptions to an event, so that
+	// every write does not scan the subscriptions. Changes made through this store drop the
+	// cache at once; those of other servers show after CacheTTL. Zero disables the cache.
+	CacheTTL time.Duration
+
+	mu    sync.Mutex
+	cache map[subscriptionsKey]cachedSubscriptions
+	// generation counts cache drops, so a scan that raced a change is not cached.
+	generation int
+}
+
+type subscriptionsKey struct {
+	tenant, event string
+}
+
+type cachedSubscriptions struct {
+	subscriptions []Subscription
+	expiresAt     time.Time
+}
+
+func (s *Store) EnsureTables(ctx context.Context) error {
+	if _, err := s.Client.DescribeTable(ctx, s.SubscriptionsTable); err != nil {
+		err := s.Client.CreateTable(ctx, s.SubscriptionsTable, &dynamodb.CreateTableInput{
+			AttributeDefinitions: []types.AttributeDefinition{
+				{AttributeName: aws.String("id"), AttributeType: types.ScalarAttributeTypeS},
+			},
+			KeySchema: []types.KeySchemaElement{
+				{AttributeName: aws.String("id"), KeyType: types.KeyTypeHash},
+			},
+			BillingMode: types.BillingModePayPerRequest,
+		})
+		if err != nil {
+			return err
+		}
+	}
+	if _, err := s.Client.DescribeTable(ctx, s.DeliveriesTable); err == nil {
+		return nil
+	}
+	return s.Client.CreateTable(ctx, s.DeliveriesTable, &dynamodb.CreateTableInput{
+		AttributeDefinitions: []types.AttributeDefinition{
+			{AttributeName: aws.String("id"), AttributeType: types.ScalarAttributeTypeS},
+			{AttributeName: aws.String("due"), AttributeType: types.ScalarAttributeTypeS},
+			{AttributeName: aws.String("nextAttemptAt"), AttributeType: types.ScalarAttributeTypeN},
+		},
+		KeySchema: []types.KeySchemaElement{
+			{AttributeName: aws.String("id"), KeyType: types.KeyTypeHash},
+		},
+		GlobalSecondaryIndexes: []types.GlobalSecondaryIndex{{
+			IndexName: aws.String(DueIndex),
+			KeySchema: []types.KeySchemaElement{
+				{AttributeName: aws.String("due"), KeyType: types.KeyTypeHash},
+				{AttributeName: aws.String("nextAttemptAt"), KeyType: types.KeyTypeRange},
+			},
+			Projection: &types.Projection{ProjectionType: types.ProjectionTypeAll},
+		}},
+		BillingMode: types.BillingModePayPerRequest,
+	})
+}
+
+func (s *Store) SaveSubscription(ctx context.Context, subscription Subscription) error {
+	defer s.dropCache()
+	return s.Client.TransactWriteItems(ctx, s.SubscriptionsTable, subscription)
+}
+
+func (s *Store) Subscriptions(ctx context.Context) ([]Subscription, error) {
+	return s.subscriptions(ctx, tenantFilter(ctx))
+}
+
+// SubscriptionsFor returns the subscriptions of the tenant in ctx that want event, from the
+// cache while it is fresh.
+func (s *Store) SubscriptionsFor(ctx context.Context, event string) ([]Subscription, error) {
+	tenantID, _ := dynamodbClient.TenantFromContext(ctx)
+	key := subscriptionsKey{tenant: tenantID, event: event}
+	s.mu.Lock()
+	cached, ok := s.cache[key]
+	generation := s.generation
+	s.mu.Unlock()
+	if ok && time.Now().Before(cached.expiresAt) {
+		return cached.subscriptions, nil
+	}
+
+	scan := tenantFilter(ctx)
+	scan.Filter += " AND contains(#events, :event)"
+	scan.Names["#events"] = "events"
+	scan.Values[":event"] = event
+	subscriptions, err := s.subscriptions(ctx, scan)
+	if err != nil || s.CacheTTL <= 0 {
+		return subscriptions, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.generation != generation {
+		return subscriptions, nil
+	}
+	if s.cache == nil {
+		s.cache = map[subscriptionsKey]cachedSubscriptions{}
+	}
+	s.cache[key] = cachedSubscriptions{subscriptions: subscriptions, expiresAt: time.Now().Add(s.CacheTTL)}
+	return subscriptions, nil
+}
+
+func (s *Store) dropCache() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.cache = nil
+	s.generation++
+}
+
+func (s *Store) subscriptions(ctx context.Context, scan dynamodbClient.ScanRequest) ([]Subscription, error) {
+	subscriptions, err := scanAll[Subscription](ctx, s.Client, s.SubscriptionsTable, scan)
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(subscriptions, func(i, j int) bool {
+		return subscriptions[i].CreatedAt.Before(subscriptions[j].CreatedAt)
+	})
+	return subscriptions, nil
+}
+
+// tenantFilter matches the entries of the tenant in ctx; entries without a tenant have no tenant attribute.
+func tenantFilter(ctx context.Context) dynamodbClient.ScanRequest {
+	scan := dynamodbClient.ScanRequest{
+		Filter: "attribute_not_exists(#tenant)",
+		Names:  map[string]string{"#tenant": "tenant"},
+		Values: map[string]any{},
+	}
+	if tenantID, ok := dynamodbClient.TenantFromContext(ctx); ok && tenantID != "" {
+		scan.Filter = "#tenant = :tenant"
+		scan.Values[":tenant"] = tenantID
+	}
+	return scan
+}
+
+// scanAll reads every page of a filtered scan.
+func scanAll[T any](ctx context.Context, client dynamodbClient.DynamodbClient, tableName string, scan dynamodbClient.ScanRequest) ([]T, error) {
+	items := []T{}
+	for {
+		var page []T
+		cursor, err := client.ScanPage(ctx, tableName, scan, &page)
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, page...)
+		if cursor == "" {
+			return items, nil
+		}
+		scan.Cursor = cursor
+	}
+}
+
+func (s *Store) Subscription(ctx context.Context, id string) (Subscription, error) {
+	tenantID, _ := dynamodbClient.TenantFromContext(ctx)
+
+	key, err := attributevalue.Marshal(id)
+	if err != nil {
+		return Subscription{}, err
+	}
+	var subscription Subscription
+	output, err := s.Client.TransactGetItem(ctx, s.SubscriptionsTable, map[string]types.AttributeValue{"id": key}, &subscription)
+	if err != nil {
+		return Subscription{}, err
+	}
+	if output.Responses[0].Item == nil || subscription.Tenant != tenantID {
+		return Subscription{}, ErrSubscriptionNotFound
+	}
+	return subscription, nil
+}
+
+func (s *Store) DeleteSubscription(ctx context.Context, id string) error {
+	if _, err := s.Subscription(ctx, id); err != nil {
+		return err
+	}
+	key, err := attributevalue.Marshal(id)
+	if err != nil {
+		return err
+	}
+	defer s.dropCache()
+	return s.Client.DeleteItem(ctx, s.SubscriptionsTable, map[string]types.AttributeValue{"id": key})
+}
+
+func (s *Store) SaveDelivery(ctx context.Context, delivery Delivery) error {
+	if delivery.Status == StatusPending {
+		delivery.Due = StatusPending
+	} else {
+		delivery.Due, delivery.NextAttemptAt = "", 0
+	}
+	return s.Client.TransactWriteItems(ctx, s.DeliveriesTable, delivery)
+}
+
+// Deliveries returns the delivery history of a subscription, newest first, optionally only with the given status.
+func (s *Store) Deliveries(ctx context.Context, subscriptionID, status string) ([]Delivery, error) {
+	if _, err := s.Subscription(ctx, subscriptionID); err != nil {
+		return nil, err
+	}
+
+	scan := dynamodbClient.ScanRequest{
+		Filter: "#subscriptionId = :subscriptionId",
+		Names:  map[string]string{"#subscriptionId": "subscriptionId"},
+		Values: map[string]any{":subscriptionId": subscriptionID},
+	}
+	if status != "" {
+		scan.Filter += " AND #status = :status"
+		scan.Names["#status"] = "status"
+		scan.Values[":status"] = status
+	}
+	deliveries, err := scanAll[Delivery](ctx, s.Client, s.DeliveriesTable, scan)
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(deliveries, func(i, j int) bool {
+		return deliveries[i].CreatedAt.After(deliveries[j].CreatedAt)
+	})
+	return deliveries, nil
+}
+
+// DueDeliveries queries DueIndex for the pending deliveries of every tenant whose next attempt
+// is due at now.
+func (s *Store) DueDeliveries(ctx context.Context, now time.Time) ([]Delivery, error) {
+	deliveries := []Delivery{}
+	query := dynamodbClient.QueryRequest{
+		IndexName:    DueIndex,
+		KeyCondition: "#due = :pending AND #nextAttemptAt <= :now",
+		Names:        map[string]string{"#due": "due", "#nextAttemptAt": "nextAttemptAt"},
+		Values:       map[string]any{":pending": StatusPending, ":now": now.UnixMilli()},
+	}
+	for {
+		var page []Delivery
+		cursor, err := s.Client.Query(ctx, s.DeliveriesTable, query, &page)
+		if err != nil {
+			return nil, err
+		}
+		deliveries = append(deliveries, page...)
+		if cursor == "" {
+			return deliveries, nil
+		}
+		query.Cursor = cursor
+	}
+}
+
+// ClaimDelivery moves a due delivery's next attempt to until, so that no other sweeper tries it
+// meanwhile. It fails with ErrConditionFailed when the delivery was claimed or tried since it was read.
+func (s *Store) ClaimDelivery(ctx context.Context, delivery Delivery, until time.Time) error {
+	key, err := attributevalue.Marshal(delivery.ID)
+	if err != nil {
+		return err
+	}
+	return s.Client.UpdateItem(ctx, s.DeliveriesTable, map[string]types.AttributeValue{"id": key},
+		"SET #nextAttemptAt = :until", map[string]any{":until": until.UnixMilli()},
+		dynamodbClient.Condition("#status = :pending AND #attempts = :attempts AND #nextAttemptAt = :nextAttemptAt",
+			map[string]string{"#status": "status", "#attempts": "attempts", "#nextAttemptAt": "nextAttemptAt"},
+			map[string]any{":pending": StatusPending, ":attempts": delivery.Attempts, ":nextAttemptAt": delivery.NextAttemptAt}))
+}